@@ -0,0 +1,100 @@
+package main
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// getEnv returns the value of an environment variable, or fallback if it is unset
+func getEnv(key, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok && value != "" {
+		return value
+	}
+
+	return fallback
+}
+
+// getEnvInt64 returns an environment variable parsed as an int64, or fallback if it is unset or invalid
+func getEnvInt64(key string, fallback int64) int64 {
+	value := getEnv(key, "")
+	if value == "" {
+		return fallback
+	}
+
+	parsed, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		log.Printf("Invalid value %q for %s, using default %d", value, key, fallback)
+		return fallback
+	}
+
+	return parsed
+}
+
+// getEnvInt returns an environment variable parsed as an int, or fallback if it is unset or invalid
+func getEnvInt(key string, fallback int) int {
+	value := getEnv(key, "")
+	if value == "" {
+		return fallback
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("Invalid value %q for %s, using default %d", value, key, fallback)
+		return fallback
+	}
+
+	return parsed
+}
+
+// getEnvBool returns an environment variable parsed as a bool, or fallback if it is unset or invalid
+func getEnvBool(key string, fallback bool) bool {
+	value := getEnv(key, "")
+	if value == "" {
+		return fallback
+	}
+
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Printf("Invalid value %q for %s, using default %t", value, key, fallback)
+		return fallback
+	}
+
+	return parsed
+}
+
+// getEnvDuration returns an environment variable parsed as a time.Duration (e.g. "30s"), or fallback if it is unset or invalid
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	value := getEnv(key, "")
+	if value == "" {
+		return fallback
+	}
+
+	parsed, err := time.ParseDuration(value)
+	if err != nil || parsed <= 0 {
+		log.Printf("Invalid value %q for %s, using default %s", value, key, fallback)
+		return fallback
+	}
+
+	return parsed
+}
+
+// getEnvList returns a comma-separated environment variable as a list of trimmed, non-empty values,
+// or fallback if it is unset
+func getEnvList(key string, fallback []string) []string {
+	value := getEnv(key, "")
+	if value == "" {
+		return fallback
+	}
+
+	var values []string
+	for _, part := range strings.Split(value, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			values = append(values, part)
+		}
+	}
+
+	return values
+}
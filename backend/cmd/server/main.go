@@ -3,10 +3,8 @@ package main
 
 import (
 	"log"
-	"net/http"
 	"time"
 
-	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 
 	"github.com/adzzfarr/gossip-with-go/backend/internal/api"
@@ -14,7 +12,18 @@ import (
 	"github.com/adzzfarr/gossip-with-go/backend/internal/service"
 )
 
+// Build information, set at build time with e.g.
+// go build -ldflags "-X main.version=1.2.0 -X main.commit=$(git rev-parse --short HEAD) -X main.buildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+var (
+	version   = "dev"
+	commit    = "unknown"
+	buildTime = "unknown"
+)
+
 func main() {
+	// Reported as uptime by the version endpoint
+	startTime := time.Now()
+
 	// Initialise database
 	dbPool, err := data.OpenDB()
 	if err != nil {
@@ -24,24 +33,125 @@ func main() {
 
 	log.Println("Database connection pool successfully created.")
 
+	// Read Replica (DATABASE_READ_URL, optional; listings and searches read from it when set)
+	readPool, err := data.OpenReadDB()
+	if err != nil {
+		log.Fatalf("Failed to initialize read replica connection: %v", err)
+	}
+	if readPool != nil {
+		defer readPool.Close()
+	}
+
 	// Initialise Layers
 	repo := data.NewRepository(dbPool)
+	repo.ReadDB = readPool
+
+	// Content Limits (maximum lengths in characters, shared by topics, posts and comments)
+	contentLimits := service.ContentLimits{
+		TitleLength:            getEnvInt("MAX_TITLE_LENGTH", service.DefaultContentLimits.TitleLength),
+		TopicDescriptionLength: getEnvInt("MAX_TOPIC_DESCRIPTION_LENGTH", service.DefaultContentLimits.TopicDescriptionLength),
+		PostContentLength:      getEnvInt("MAX_POST_CONTENT_LENGTH", service.DefaultContentLimits.PostContentLength),
+		CommentLength:          getEnvInt("MAX_COMMENT_LENGTH", service.DefaultContentLimits.CommentLength),
+	}
+	configHandler := api.NewConfigHandler(contentLimits)
 
 	// Topics
 	topicService := service.NewTopicService(repo)
+	topicService.Limits = contentLimits
+	topicService.TrendingWindow = getEnvDuration("TRENDING_WINDOW", service.DefaultTrendingWindow) // Activity counted towards trending topics
+
+	// Topics List Total (TOPICS_COUNT_ESTIMATE_THRESHOLD, default 100000 rows; larger tables get an estimated total, 0 = always exact)
+	topicService.CountEstimateThreshold = getEnvInt("TOPICS_COUNT_ESTIMATE_THRESHOLD", service.DefaultCountEstimateThreshold)
+
+	// Daily Topic Limit per User (MAX_TOPICS_PER_DAY, default 10 in any 24 hours; 0 = unlimited)
+	topicService.MaxTopicsPerDay = getEnvInt("MAX_TOPICS_PER_DAY", service.DefaultMaxTopicsPerDay)
+
+	// Topics List Cache (TOPICS_CACHE_ENABLED, default true; TOPICS_CACHE_TTL, default 30s)
+	// Writes invalidate only this instance's cache, so with several instances lists may lag by up to the TTL
+	if getEnvBool("TOPICS_CACHE_ENABLED", true) {
+		topicService.Cache = service.NewTopicsCache(
+			getEnvDuration("TOPICS_CACHE_TTL", service.DefaultTopicsCacheTTL),
+			service.DefaultTopicsCacheEntries,
+		)
+	}
 	topicHandler := api.NewTopicHandler(topicService)
 
 	// Users
-	userService := service.NewUserService(repo)
+	passwordPolicy := service.PasswordPolicy{
+		MinLength:     getEnvInt("PASSWORD_MIN_LENGTH", service.DefaultPasswordPolicy.MinLength),
+		RequireLower:  getEnvBool("PASSWORD_REQUIRE_LOWER", service.DefaultPasswordPolicy.RequireLower),
+		RequireUpper:  getEnvBool("PASSWORD_REQUIRE_UPPER", service.DefaultPasswordPolicy.RequireUpper),
+		RequireDigit:  getEnvBool("PASSWORD_REQUIRE_DIGIT", service.DefaultPasswordPolicy.RequireDigit),
+		RequireSymbol: getEnvBool("PASSWORD_REQUIRE_SYMBOL", service.DefaultPasswordPolicy.RequireSymbol),
+	}
+	userService := service.NewUserService(repo, passwordPolicy, service.DefaultPasswordBlocklist())
+	userService.ResetTokenTTL = getEnvDuration("PASSWORD_RESET_TTL", service.DefaultResetTokenTTL)
+
+	// Outgoing Email (EMAIL_SENDER: "smtp" uses SMTP_HOST, SMTP_PORT, SMTP_USERNAME, SMTP_PASSWORD and EMAIL_FROM;
+	// "log" prints emails, for development only; unset disables email, so password resets cannot be requested)
+	switch getEnv("EMAIL_SENDER", "") {
+	case "smtp":
+		userService.Email = service.NewSMTPEmailSender(
+			getEnv("SMTP_HOST", "localhost"),
+			getEnvInt("SMTP_PORT", 587),
+			getEnv("SMTP_USERNAME", ""),
+			getEnv("SMTP_PASSWORD", ""),
+			getEnv("EMAIL_FROM", "no-reply@localhost"),
+		)
+	case "log":
+		userService.Email = service.LogEmailSender{}
+	case "":
+		// Email disabled
+	default:
+		log.Fatalf("Invalid EMAIL_SENDER: %q", getEnv("EMAIL_SENDER", ""))
+	}
+
+	// Deleted Accounts, Revoked Sessions and Bans (AUTH_USER_CHECK: "cached", the default, trusts users confirmed to
+	// exist, and their ban status, for AUTH_USER_CHECK_TTL, default 30s; "always" queries on every protected request;
+	// "off" accepts tokens of deleted users until they expire, and refuses DELETE /me/sessions with 501 as revoked
+	// tokens would still be accepted; bans are still checked on every protected request unless "cached")
+	checkUserExists := true
+	switch getEnv("AUTH_USER_CHECK", "cached") {
+	case "cached":
+		userService.ExistsCache = service.NewUserExistsCache(getEnvDuration("AUTH_USER_CHECK_TTL", service.DefaultUserExistsCacheTTL))
+	case "always":
+		// No cache
+	case "off":
+		checkUserExists = false
+	default:
+		log.Fatalf("Invalid AUTH_USER_CHECK: %q", getEnv("AUTH_USER_CHECK", ""))
+	}
 	userHandler := api.NewUserHandler(userService)
+	userHandler.RegistrationClosed = !getEnvBool("REGISTRATION_OPEN", true) // Close signups, e.g. during invite-only phases
+	userHandler.SessionsUnchecked = !checkUserExists
+
+	// Idempotency Keys
+	idempotencyService := service.NewIdempotencyService(repo)
+
+	// Webhooks (delivered in the background, so a slow or failing endpoint never blocks post creation)
+	webhookDispatcher := service.NewWebhookDispatcher(
+		service.DefaultWebhookQueueSize,
+		service.DefaultWebhookWorkers,
+		service.NewWebhookHTTPClient(service.DefaultWebhookTimeout),
+	)
+	defer webhookDispatcher.Close()
+
+	webhookService := service.NewWebhookService(repo, webhookDispatcher)
+	webhookHandler := api.NewWebhookHandler(webhookService)
 
 	// Posts
 	postService := service.NewPostService(repo)
-	postHandler := api.NewPostHandler(postService)
+	postService.Webhooks = webhookService
+	postService.Limits = contentLimits
+	postService.MaxPostsPerTopic = getEnvInt("MAX_POSTS_PER_TOPIC", 0) // 0 = unlimited
+	postHandler := api.NewPostHandler(postService, idempotencyService)
 
 	// Comments
 	commentService := service.NewCommentService(repo)
-	commentHandler := api.NewCommentHandler(commentService)
+	commentService.Broker = service.NewCommentBroker()                        // Live comment streams (single instance only)
+	commentService.MaxCommentsPerPost = getEnvInt("MAX_COMMENTS_PER_POST", 0) // 0 = unlimited
+	commentService.Limits = contentLimits
+	commentHandler := api.NewCommentHandler(commentService, idempotencyService)
 
 	// Votes
 	voteService := service.NewVoteService(repo)
@@ -50,61 +160,138 @@ func main() {
 	// JWT (Replace "secret-key" with a secure key from env variables in production)
 	jwtService := service.NewJWTService("secret-key", 24*time.Hour) // 24 hours expiry
 
-	// Login
+	// Login (a username is locked out for LOGIN_LOCKOUT_DURATION after MAX_FAILED_LOGINS consecutive failures from one client)
 	loginService := service.NewLoginService(repo)
+	loginService.Lockout = service.NewLoginLockout(
+		getEnvInt("MAX_FAILED_LOGINS", service.DefaultMaxFailedLogins),
+		getEnvDuration("LOGIN_LOCKOUT_DURATION", service.DefaultLoginLockoutDuration),
+	)
 	loginHandler := api.NewLoginHandler(loginService, jwtService)
 
+	// Admin
+	adminService := service.NewAdminService(repo)
+	adminService.UserCache = userService.ExistsCache // Ban status is cached with the token version (nil when not "cached")
+	adminHandler := api.NewAdminHandler(adminService)
+
 	// Initialise Gin router
 	router := gin.Default()
 
-	// CORS Middleware
-	router.Use(cors.New(cors.Config{
-		AllowOrigins:     []string{"http://localhost:5173"},
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
-		AllowCredentials: true,
-	}))
-
-	// Health Check Endpoint
-	router.GET("/health", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{"status": "UP"})
+	// Trusted Proxies (TRUSTED_PROXIES, comma-separated IPs or CIDRs; default none)
+	// ctx.ClientIP() only honours X-Forwarded-For (rightmost untrusted hop) and X-Real-IP on requests whose
+	// direct peer is a trusted proxy; trusting none means it is always the peer's address, which clients cannot spoof
+	if err := router.SetTrustedProxies(getEnvList("TRUSTED_PROXIES", nil)); err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
+
+	// JSON 404 for unknown paths, and 405 (with an Allow header) for unsupported methods on known ones
+	api.ConfigureFallbackHandlers(router)
+
+	// CORS Middleware (CORS_ALLOWED_ORIGINS, comma-separated; CORS_ALLOW_CREDENTIALS; CORS_MAX_AGE, default 12h)
+	corsMiddleware, err := api.CORSMiddleware(api.CORSOptions{
+		AllowOrigins:     getEnvList("CORS_ALLOWED_ORIGINS", []string{"http://localhost:5173"}),
+		AllowCredentials: getEnvBool("CORS_ALLOW_CREDENTIALS", true),
+		MaxAge:           getEnvDuration("CORS_MAX_AGE", api.DefaultCORSMaxAge),
 	})
+	if err != nil {
+		log.Fatalf("Invalid CORS configuration: %v", err)
+	}
+	router.Use(corsMiddleware)
+
+	// Response Compression (gzip/deflate for bodies of 1KB or more)
+	router.Use(api.CompressionMiddleware(api.DefaultCompressionMinBytes, "/metrics"))
+
+	// Health Check Endpoint (pings the database; HEALTH_CHECK_INTERVAL, default 2s, reuses a successful ping)
+	healthChecker := api.NewHealthChecker(dbPool.Ping, getEnvDuration("HEALTH_CHECK_INTERVAL", api.DefaultHealthCheckInterval))
+	router.GET("/health", api.HealthHandler(healthChecker))
+
+	// OpenAPI Document
+	router.GET("/openapi.json", api.GetOpenAPISpec)
 
 	// Register API Routes
-	v1 := router.Group("/api/v1")
+	v1 := router.Group(api.APIBasePath)
+
+	// Request Timeout (REQUEST_TIMEOUT, default 30s; comment streams, topic streams and CSV exports are long-lived, so have none)
+	requestTimeout := getEnvDuration("REQUEST_TIMEOUT", api.DefaultRequestTimeout)
+	v1.Use(api.TimeoutMiddleware(requestTimeout, api.LongLivedRoutes...))
+
+	// Request Body Content Type (non-JSON bodies get 415; STRICT_CONTENT_TYPE also rejects bodies without a Content-Type)
+	v1.Use(api.JSONContentTypeMiddleware(getEnvBool("STRICT_CONTENT_TYPE", false)))
+
+	// Request Body Size Limit (MAX_BODY_BYTES, default 64KB; bulk post imports are sized for their maximum batch instead)
+	maxBodyBytes := getEnvInt64("MAX_BODY_BYTES", api.DefaultMaxBodyBytes)
+	v1.Use(api.BodySizeLimitMiddleware(maxBodyBytes, api.APIBasePath+"/admin/posts/bulk"))
 	{
 		// Public Routes (No Auth Required)
 		v1.POST("/users", userHandler.RegisterUser)
 		v1.POST("/login", loginHandler.LoginUser)
+		v1.GET("/auth/password-policy", userHandler.GetPasswordPolicy)
+		v1.POST("/auth/forgot-password", userHandler.ForgotPassword)
+		v1.POST("/auth/reset-password", userHandler.ResetPassword)
+		v1.GET("/config/limits", configHandler.GetContentLimits)
+		v1.GET("/version", api.VersionHandler(api.BuildInfo{Version: version, Commit: commit, BuildTime: buildTime}, startTime))
+
+		// Public Read Routes (Auth Optional, so logged-in users see their own vote state)
+		// Tokens of deleted users or revoked sessions browse anonymously, unless AUTH_USER_CHECK is off
+		public := v1.Group("")
+		optionalAuthUsers := userService
+		if !checkUserExists {
+			optionalAuthUsers = nil
+		}
+		public.Use(api.OptionalAuthMiddleware(jwtService, optionalAuthUsers))
+		{
+			public.GET("/topics", topicHandler.GetAllTopics)
+			public.GET("/topics/:topicID", topicHandler.GetTopicByID)
+			public.GET("/topics/trending", topicHandler.GetTrendingTopics)
+			public.GET("/topics/slug/:slug", topicHandler.GetTopicBySlug)
+			public.GET("/topics/:topicID/full", topicHandler.GetTopicWithPosts)
+
+			public.GET("/topics/:topicID/posts", postHandler.GetPostsByTopicID)
+			public.GET("/posts/:postID", postHandler.GetPostByID)
+			public.GET("/posts/votes", voteHandler.GetVoteCountsForPosts)
 
-		v1.GET("/topics", topicHandler.GetAllTopics)
-		v1.GET("/topics/:topicID", topicHandler.GetTopicByID)
+			public.GET("/posts/:postID/comments", commentHandler.GetCommentsByPostID)
 
-		v1.GET("/topics/:topicID/posts", postHandler.GetPostsByTopicID)
-		v1.GET("/posts/:postID", postHandler.GetPostByID)
+			public.GET("/posts/:postID/comments/count", commentHandler.CountComments)
+			public.GET("/posts/:postID/comments/stream", commentHandler.StreamComments)
+			public.GET("/comments/:commentID", commentHandler.GetCommentByID)
 
-		v1.GET("/posts/:postID/comments", commentHandler.GetCommentsByPostID)
+			public.GET("/users/active", userHandler.GetActiveUsers)
+			public.GET("/users/usernames", userHandler.GetUsernamesByIDs)
+			public.GET("/users/username/:username", userHandler.GetUserByUsername)
+		}
 
 		// Protected Routes (Auth Required)
 		protected := v1.Group("")
 		protected.Use(api.AuthMiddleware(jwtService))
+		if checkUserExists {
+			protected.Use(api.UserExistsMiddleware(userService))
+		}
+		protected.Use(api.BanMiddleware(adminService))
 		{
 			// Topics
 			protected.POST("/topics", topicHandler.CreateTopic)
 			protected.PUT("/topics/:topicID", topicHandler.UpdateTopic)
+			protected.PATCH("/topics/:topicID", topicHandler.PatchTopic)
+			protected.PATCH("/topics/:topicID/description", topicHandler.UpdateTopicDescription)
 			protected.DELETE("/topics/:topicID", topicHandler.DeleteTopic)
 
 			// Posts
 			protected.POST("/topics/:topicID/posts", postHandler.CreatePost)
 			protected.PUT("/posts/:postID", postHandler.UpdatePost)
+			protected.PATCH("/posts/:postID", postHandler.PatchPost)
 			protected.DELETE("/posts/:postID", postHandler.DeletePost)
+			protected.POST("/posts/:postID/lock", postHandler.TogglePostLock)
 
 			// Comments
 			protected.POST("/posts/:postID/comments", commentHandler.CreateComment)
+			protected.POST("/topics/:topicID/posts/:postID/comments", commentHandler.CreateTopicComment)
 			protected.PUT("/comments/:commentID", commentHandler.UpdateComment)
 			protected.DELETE("/comments/:commentID", commentHandler.DeleteComment)
 
+			// Webhooks
+			protected.POST("/topics/:topicID/webhooks", webhookHandler.CreateWebhook)
+			protected.DELETE("/webhooks/:webhookID", webhookHandler.DeleteWebhook)
+
 			// Votes
 			protected.POST("/posts/:postID/vote", voteHandler.VoteOnPost)
 			protected.DELETE("/posts/:postID/vote", voteHandler.RemoveVoteFromPost)
@@ -112,15 +299,40 @@ func main() {
 			protected.DELETE("/comments/:commentID/vote", voteHandler.RemoveVoteFromComment)
 
 			// User Profiles
+			protected.GET("/me", userHandler.GetCurrentUser)
+			protected.PATCH("/me/username", userHandler.ChangeUsername)
+			protected.DELETE("/me/sessions", userHandler.RevokeSessions)
 			protected.GET("/users/:id", userHandler.GetUserByID)
 			protected.GET("/users/:id/posts", userHandler.GetUserPosts)
 			protected.GET("/users/:id/comments", userHandler.GetUserComments)
+			protected.GET("/users/:id/karma", userHandler.GetUserKarma)
+
+			// Admin Routes (Admin Role Required)
+			admin := protected.Group("/admin")
+			admin.Use(api.AdminMiddleware(adminService))
+			{
+				admin.GET("/audit", adminHandler.GetAuditLog)
+				admin.GET("/users", adminHandler.SearchUsers)
+				admin.POST("/users/:userID/ban", adminHandler.BanUser)
+				admin.POST("/users/:userID/unban", adminHandler.UnbanUser)
+				admin.POST("/comments/delete", adminHandler.DeleteComments)
+				admin.POST("/votes/recount", adminHandler.RecountVotes)
+				admin.POST("/posts/bulk", api.BodySizeLimitMiddleware(api.BulkPostsMaxBodyBytes(contentLimits)), postHandler.CreatePostsBatch)
+			}
+
+			// Pinning and Exports (Admin Role Required)
+			protected.POST("/topics/:topicID/pin", api.AdminMiddleware(adminService), topicHandler.PinTopic)
+			protected.DELETE("/topics/:topicID/pin", api.AdminMiddleware(adminService), topicHandler.UnpinTopic)
+			protected.POST("/posts/:postID/pin", api.AdminMiddleware(adminService), postHandler.PinPost)
+			protected.DELETE("/posts/:postID/pin", api.AdminMiddleware(adminService), postHandler.UnpinPost)
+			protected.GET("/topics/:topicID/posts.csv", api.AdminMiddleware(adminService), postHandler.ExportTopicPostsCSV)
 		}
 	}
 
-	// Run Server
-	log.Println("Starting server on :8080...")
-	if err := router.Run(":8080"); err != nil {
+	// Run Server (LISTEN_ADDR, default :8080)
+	listenAddr := getEnv("LISTEN_ADDR", ":8080")
+	log.Printf("Starting server on %s...", listenAddr)
+	if err := router.Run(listenAddr); err != nil {
 		log.Fatalf("Server failed to start: %v", err)
 	}
 }
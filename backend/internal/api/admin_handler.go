@@ -0,0 +1,319 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/adzzfarr/gossip-with-go/backend/internal/data"
+	"github.com/adzzfarr/gossip-with-go/backend/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// AdminHandler holds instance of AdminService to perform business logic
+type AdminHandler struct {
+	AdminService *service.AdminService
+}
+
+// NewAdminHandler creates a new instance of AdminHandler
+func NewAdminHandler(adminService *service.AdminService) *AdminHandler {
+	return &AdminHandler{AdminService: adminService}
+}
+
+// GetAuditLog handles GET requests for recent audit log entries (admin only)
+// Supports optional 'limit' (default 20, max 100) and 'offset' (default 0) query parameters
+func (handler *AdminHandler) GetAuditLog(ctx *gin.Context) {
+	// Parse pagination query parameters
+	limit, offset, err := parsePagination(ctx, MaxAuditLogPageLimit)
+	if err != nil {
+		ctx.JSON(
+			http.StatusBadRequest,
+			gin.H{"error": err.Error()})
+		return
+	}
+
+	// Call service layer
+	entries, err := handler.AdminService.GetAuditLog(limit, offset)
+
+	if err != nil {
+		// Send ISE status to client
+		ctx.JSON(
+			http.StatusInternalServerError,
+			gin.H{"error": "Failed to fetch audit log"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, entries)
+}
+
+// SearchUsers handles GET requests to list users, optionally filtered by username prefix (admin only)
+// Supports optional 'limit' (default 20, max 25) and 'offset' (default 0) query parameters
+func (handler *AdminHandler) SearchUsers(ctx *gin.Context) {
+	// Parse pagination query parameters
+	limit, offset, err := parsePagination(ctx, MaxUserSearchPageLimit)
+	if err != nil {
+		ctx.JSON(
+			http.StatusBadRequest,
+			gin.H{"error": err.Error()})
+		return
+	}
+
+	// Call service layer
+	users, err := handler.AdminService.SearchUsers(ctx.Query("q"), limit, offset)
+
+	if err != nil {
+		// Check for validation errors (Bad Request 400)
+		if errors.Is(err, service.ErrValidation) {
+			ctx.JSON(
+				http.StatusBadRequest,
+				gin.H{"error": err.Error()},
+			)
+			return
+		}
+
+		// Otherwise, send ISE status to client
+		ctx.JSON(
+			http.StatusInternalServerError,
+			gin.H{"error": "Failed to search users"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, users)
+}
+
+// BanUserRequest defines optional JSON input for banning a user
+type BanUserRequest struct {
+	Duration string `json:"duration,omitempty"` // e.g. "72h"; omit for a permanent ban
+}
+
+// BanUserResponse reports when a ban ends
+type BanUserResponse struct {
+	UserID      int     `json:"userID"`
+	BannedUntil *string `json:"bannedUntil"` // Nil for a permanent ban
+}
+
+// BanUser handles POST requests to suspend a user, optionally for a limited time (admin only)
+// Banned users are refused on every authenticated route (see BanMiddleware); their content is kept
+func (handler *AdminHandler) BanUser(ctx *gin.Context) {
+	// Get authenticated admin's ID from context (set by AuthMiddleware)
+	adminID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(
+			http.StatusUnauthorized,
+			gin.H{"error": "Unauthorized"},
+		)
+		return
+	}
+
+	// Get userID from URL parameter
+	userID, err := strconv.Atoi(ctx.Param("userID"))
+	if err != nil {
+		ctx.JSON(
+			http.StatusBadRequest,
+			gin.H{"error": "Invalid user ID"},
+		)
+		return
+	}
+
+	// Parse optional request body JSON into BanUserRequest struct
+	var req BanUserRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		ctx.JSON(
+			http.StatusBadRequest,
+			gin.H{"error": "Invalid input format"},
+		)
+		return
+	}
+
+	var duration time.Duration
+	if req.Duration != "" {
+		duration, err = time.ParseDuration(req.Duration)
+		if err != nil || duration <= 0 {
+			ctx.JSON(
+				http.StatusBadRequest,
+				gin.H{"error": "Invalid duration"},
+			)
+			return
+		}
+	}
+
+	// Call service layer
+	until, err := handler.AdminService.BanUser(userID, adminID.(int), duration)
+	if err != nil {
+		errMsg := err.Error()
+
+		// Check for not found errors (Not Found 404)
+		if strings.Contains(errMsg, "not found") {
+			ctx.JSON(
+				http.StatusNotFound,
+				gin.H{"error": "User not found"},
+			)
+			return
+		}
+
+		// Check for validation errors (Bad Request 400)
+		if errors.Is(err, service.ErrValidation) || strings.Contains(errMsg, "invalid user ID") {
+			ctx.JSON(
+				http.StatusBadRequest,
+				gin.H{"error": errMsg},
+			)
+			return
+		}
+
+		// Otherwise, send ISE status to client
+		ctx.JSON(
+			http.StatusInternalServerError,
+			gin.H{"error": "Failed to ban user"},
+		)
+		return
+	}
+
+	resp := BanUserResponse{UserID: userID}
+	if until != nil {
+		formatted := until.UTC().Format(data.TimestampFormat)
+		resp.BannedUntil = &formatted
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// UnbanUser handles POST requests to lift a user's suspension (admin only)
+func (handler *AdminHandler) UnbanUser(ctx *gin.Context) {
+	// Get authenticated admin's ID from context (set by AuthMiddleware)
+	adminID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(
+			http.StatusUnauthorized,
+			gin.H{"error": "Unauthorized"},
+		)
+		return
+	}
+
+	// Get userID from URL parameter
+	userID, err := strconv.Atoi(ctx.Param("userID"))
+	if err != nil {
+		ctx.JSON(
+			http.StatusBadRequest,
+			gin.H{"error": "Invalid user ID"},
+		)
+		return
+	}
+
+	// Call service layer
+	if err := handler.AdminService.UnbanUser(userID, adminID.(int)); err != nil {
+		errMsg := err.Error()
+
+		// Check for not found errors (Not Found 404)
+		if strings.Contains(errMsg, "not found") {
+			ctx.JSON(
+				http.StatusNotFound,
+				gin.H{"error": "User not found"},
+			)
+			return
+		}
+
+		// Check for validation errors (Bad Request 400)
+		if strings.Contains(errMsg, "invalid user ID") {
+			ctx.JSON(
+				http.StatusBadRequest,
+				gin.H{"error": errMsg},
+			)
+			return
+		}
+
+		// Otherwise, send ISE status to client
+		ctx.JSON(
+			http.StatusInternalServerError,
+			gin.H{"error": "Failed to unban user"},
+		)
+		return
+	}
+
+	// Return No Content status on success
+	ctx.Status(http.StatusNoContent)
+}
+
+// DeleteCommentsRequest defines expected JSON input for deleting several comments at once
+type DeleteCommentsRequest struct {
+	CommentIDs []int `json:"commentIDs" binding:"required"`
+}
+
+// DeleteCommentsResponse reports how many comments a bulk deletion removed
+type DeleteCommentsResponse struct {
+	Deleted int `json:"deleted"`
+}
+
+// DeleteComments handles POST requests to delete several comments in one transaction (admin only)
+// At most service.MaxBulkDeleteComments IDs are accepted; IDs of missing comments are ignored
+func (handler *AdminHandler) DeleteComments(ctx *gin.Context) {
+	// Get authenticated admin's ID from context (set by AuthMiddleware)
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(
+			http.StatusUnauthorized,
+			gin.H{"error": "Unauthorized"},
+		)
+		return
+	}
+
+	// Parse request body JSON into DeleteCommentsRequest struct
+	var req DeleteCommentsRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(
+			http.StatusBadRequest,
+			gin.H{"error": "Invalid input format or missing fields"},
+		)
+		return
+	}
+
+	// Call service layer
+	deleted, err := handler.AdminService.DeleteComments(req.CommentIDs, userID.(int))
+	if err != nil {
+		// Check for validation errors (Bad Request 400)
+		if errors.Is(err, service.ErrValidation) {
+			ctx.JSON(
+				http.StatusBadRequest,
+				gin.H{"error": err.Error()},
+			)
+			return
+		}
+
+		// Otherwise, send ISE status to client
+		ctx.JSON(
+			http.StatusInternalServerError,
+			gin.H{"error": "Failed to delete comments"},
+		)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, DeleteCommentsResponse{Deleted: deleted})
+}
+
+// RecountVotes handles POST requests to recompute stored vote counts from the votes table (admin only)
+// Responds with the posts and comments whose counts were wrong, along with their old and corrected values
+func (handler *AdminHandler) RecountVotes(ctx *gin.Context) {
+	// Get authenticated admin's ID from context (set by AuthMiddleware)
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(
+			http.StatusUnauthorized,
+			gin.H{"error": "Unauthorized"},
+		)
+		return
+	}
+
+	// Call service layer
+	recount, err := handler.AdminService.RecountVotes(userID.(int))
+	if err != nil {
+		ctx.JSON(
+			http.StatusInternalServerError,
+			gin.H{"error": "Failed to recount votes"},
+		)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, recount)
+}
@@ -0,0 +1,51 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/adzzfarr/gossip-with-go/backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminMiddleware restricts routes to users with the admin role
+// Must run after AuthMiddleware, which sets the userID in context
+func AdminMiddleware(adminService *service.AdminService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		// Get authenticated user's ID from context (set by AuthMiddleware)
+		userID, exists := ctx.Get("userID")
+
+		if !exists {
+			ctx.JSON(
+				http.StatusUnauthorized,
+				gin.H{"error": "Unauthorized"},
+			)
+			ctx.Abort()
+			return
+		}
+
+		// Role is checked against the database, so revoking admin takes effect immediately
+		isAdmin, err := adminService.IsAdmin(userID.(int))
+
+		if err != nil {
+			ctx.JSON(
+				http.StatusInternalServerError,
+				gin.H{"error": "Failed to verify admin role"},
+			)
+			ctx.Abort()
+			return
+		}
+
+		if !isAdmin {
+			ctx.JSON(
+				http.StatusForbidden,
+				gin.H{"error": "Admin access required"},
+			)
+			ctx.Abort()
+			return
+		}
+
+		// Proceed to next handler
+		ctx.Next()
+	}
+}
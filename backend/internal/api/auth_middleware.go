@@ -1,26 +1,66 @@
 package api
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 
 	"github.com/adzzfarr/gossip-with-go/backend/internal/service"
 
 	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
 )
 
+// Codes returned with AuthMiddleware's 401 responses, so clients can tell a missing login from an expired one
+const (
+	AuthCodeMissing   = "AUTH_MISSING"   // No Authorization header: log in
+	AuthCodeMalformed = "AUTH_MALFORMED" // Authorization header is not "Bearer <token>"
+	AuthCodeExpired   = "AUTH_EXPIRED"   // Token was valid but has expired: log in again
+	AuthCodeInvalid   = "AUTH_INVALID"   // Token failed validation for any other reason (bad signature, garbled, account deleted, ...)
+	AuthCodeRevoked   = "AUTH_REVOKED"   // Token was issued before the user revoked their sessions (see UserExistsMiddleware)
+)
+
+// bearerRealm is the realm named in WWW-Authenticate challenges
+const bearerRealm = "api"
+
+// bearerErrors maps AuthCode values to the RFC 6750 error sent in the WWW-Authenticate challenge
+// Requests without credentials get a challenge without an error, as the RFC asks
+var bearerErrors = map[string]string{
+	AuthCodeMalformed: "invalid_request",
+	AuthCodeExpired:   "invalid_token",
+	AuthCodeInvalid:   "invalid_token",
+	AuthCodeRevoked:   "invalid_token",
+}
+
+// setBearerChallenge sets the WWW-Authenticate header a 401 must carry, with bearerError if not empty
+func setBearerChallenge(ctx *gin.Context, bearerError string) {
+	challenge := fmt.Sprintf("Bearer realm=%q", bearerRealm)
+	if bearerError != "" {
+		challenge += fmt.Sprintf(", error=%q", bearerError)
+	}
+
+	ctx.Header("WWW-Authenticate", challenge)
+}
+
+// abortUnauthorized ends the request with 401, an error message and one of the AuthCode values
+func abortUnauthorized(ctx *gin.Context, code, message string) {
+	setBearerChallenge(ctx, bearerErrors[code])
+	ctx.JSON(
+		http.StatusUnauthorized,
+		gin.H{"error": message, "code": code},
+	)
+	ctx.Abort()
+}
+
 // AuthMiddleware validates JWT tokens of incoming requests
+// Rejected requests get 401 with a 'code' saying why (see AuthCodeMissing and friends)
 func AuthMiddleware(jwtService *service.JWTService) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		// Get token from Authorization header
 		authHeader := ctx.GetHeader("Authorization")
 
 		if authHeader == "" {
-			ctx.JSON(
-				http.StatusUnauthorized,
-				gin.H{"error": "Authorization header required"},
-			)
-			ctx.Abort()
+			abortUnauthorized(ctx, AuthCodeMissing, "Authorization header required")
 			return
 		}
 
@@ -29,11 +69,7 @@ func AuthMiddleware(jwtService *service.JWTService) gin.HandlerFunc {
 		_, err := fmt.Sscanf(authHeader, "Bearer %s", &tokenString)
 
 		if err != nil || tokenString == "" {
-			ctx.JSON(
-				http.StatusUnauthorized,
-				gin.H{"error": "Invalid Authorization header format"},
-			)
-			ctx.Abort()
+			abortUnauthorized(ctx, AuthCodeMalformed, "Invalid Authorization header format")
 			return
 		}
 
@@ -41,19 +77,64 @@ func AuthMiddleware(jwtService *service.JWTService) gin.HandlerFunc {
 		claims, err := jwtService.ValidateToken(tokenString)
 
 		if err != nil {
-			ctx.JSON(
-				http.StatusUnauthorized,
-				gin.H{"error": "Invalid or expired token"},
-			)
-			ctx.Abort()
+			// Expired tokens are told apart, so clients know logging in again will help
+			if errors.Is(err, jwt.ErrTokenExpired) {
+				abortUnauthorized(ctx, AuthCodeExpired, "Token has expired")
+				return
+			}
+
+			abortUnauthorized(ctx, AuthCodeInvalid, "Invalid token")
 			return
 		}
 
 		// Store claims in context for other handlers
 		ctx.Set("userID", claims.UserID)
 		ctx.Set("username", claims.Username)
+		ctx.Set("tokenVersion", claims.TokenVersion)
+
+		// Proceed to next handler
+		ctx.Next()
+	}
+}
+
+// OptionalAuthMiddleware sets the user in context when a valid JWT token is present
+// Unlike AuthMiddleware, it never aborts: requests with a missing or invalid token proceed anonymously
+// If userService is set, tokens of deleted users or revoked sessions are also treated as anonymous (nil skips the check)
+func OptionalAuthMiddleware(jwtService *service.JWTService, userService *service.UserService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		// Get token from Authorization header
+		authHeader := ctx.GetHeader("Authorization")
+
+		if authHeader != "" {
+			// Expected format: "Bearer <token>"
+			var tokenString string
+			_, err := fmt.Sscanf(authHeader, "Bearer %s", &tokenString)
+
+			if err == nil && tokenString != "" {
+				// Only store claims of valid, unrevoked tokens
+				if claims, err := jwtService.ValidateToken(tokenString); err == nil && tokenStillValid(userService, claims) {
+					ctx.Set("userID", claims.UserID)
+					ctx.Set("username", claims.Username)
+				}
+			}
+		}
 
 		// Proceed to next handler
 		ctx.Next()
 	}
 }
+
+// tokenStillValid reports whether the token's user still exists and has not revoked it since it was issued
+// Lookup failures count as invalid, so OptionalAuthMiddleware falls back to anonymous access
+func tokenStillValid(userService *service.UserService, claims *service.JWTClaims) bool {
+	if userService == nil {
+		return true
+	}
+
+	tokenVersion, err := userService.CurrentTokenVersion(claims.UserID)
+	if err != nil {
+		return false
+	}
+
+	return claims.TokenVersion >= tokenVersion
+}
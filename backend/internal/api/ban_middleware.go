@@ -0,0 +1,51 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/adzzfarr/gossip-with-go/backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BanMiddleware rejects requests from suspended users with 403
+// Must run after AuthMiddleware, which sets the userID in context
+func BanMiddleware(adminService *service.AdminService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		// Get authenticated user's ID from context (set by AuthMiddleware)
+		userID, exists := ctx.Get("userID")
+
+		if !exists {
+			ctx.JSON(
+				http.StatusUnauthorized,
+				gin.H{"error": "Unauthorized"},
+			)
+			ctx.Abort()
+			return
+		}
+
+		// Bans are checked against the database (or the user cache, cleared on ban and unban), so they take effect on tokens that were already issued
+		isBanned, err := adminService.IsBanned(userID.(int))
+
+		if err != nil {
+			ctx.JSON(
+				http.StatusInternalServerError,
+				gin.H{"error": "Failed to verify account status"},
+			)
+			ctx.Abort()
+			return
+		}
+
+		if isBanned {
+			ctx.JSON(
+				http.StatusForbidden,
+				gin.H{"error": "account suspended"},
+			)
+			ctx.Abort()
+			return
+		}
+
+		// Proceed to next handler
+		ctx.Next()
+	}
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/adzzfarr/gossip-with-go/backend/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultMaxBodyBytes is the default request body size limit (64KB)
+const DefaultMaxBodyBytes int64 = 64 << 10
+
+// bulkPostOverheadBytes bounds the JSON of a bulk-imported post besides its title and content (keys, IDs, punctuation)
+const bulkPostOverheadBytes = 256
+
+// maxJSONBytesPerChar bounds the encoded size of one character in a JSON string (a \uXXXX escape)
+const maxJSONBytesPerChar = 6
+
+// BulkPostsMaxBodyBytes is the body size limit of a bulk post import: service.MaxBulkCreatePosts posts,
+// each with a title and content at the maximum lengths in limits
+func BulkPostsMaxBodyBytes(limits service.ContentLimits) int64 {
+	perPost := int64(limits.TitleLength+limits.PostContentLength)*maxJSONBytesPerChar + bulkPostOverheadBytes
+	return int64(service.MaxBulkCreatePosts) * perPost
+}
+
+// BodySizeLimitMiddleware rejects request bodies larger than maxBytes with 413 Request Entity Too Large
+// Routes in excludedRoutes (full route patterns) are not limited here; they should apply their own, larger, limit
+func BodySizeLimitMiddleware(maxBytes int64, excludedRoutes ...string) gin.HandlerFunc {
+	excluded := make(map[string]bool, len(excludedRoutes))
+	for _, route := range excludedRoutes {
+		excluded[route] = true
+	}
+
+	return func(ctx *gin.Context) {
+		if ctx.Request.Body == nil || ctx.Request.Body == http.NoBody || excluded[ctx.FullPath()] {
+			ctx.Next()
+			return
+		}
+
+		// Reject early if the declared length is already too large
+		if ctx.Request.ContentLength > maxBytes {
+			ctx.JSON(
+				http.StatusRequestEntityTooLarge,
+				gin.H{"error": "Request body too large"},
+			)
+			ctx.Abort()
+			return
+		}
+
+		// Read at most maxBytes so that oversized (e.g. chunked) bodies never reach the handlers
+		ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxBytes)
+		body, err := io.ReadAll(ctx.Request.Body)
+
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				ctx.JSON(
+					http.StatusRequestEntityTooLarge,
+					gin.H{"error": "Request body too large"},
+				)
+				ctx.Abort()
+				return
+			}
+
+			ctx.JSON(
+				http.StatusBadRequest,
+				gin.H{"error": "Failed to read request body"},
+			)
+			ctx.Abort()
+			return
+		}
+
+		// Replace consumed body so handlers can bind it as usual
+		ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		// Proceed to next handler
+		ctx.Next()
+	}
+}
@@ -1,10 +1,17 @@
 package api
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/adzzfarr/gossip-with-go/backend/internal/data"
 	"github.com/adzzfarr/gossip-with-go/backend/internal/service"
 
 	"github.com/gin-gonic/gin"
@@ -12,15 +19,20 @@ import (
 
 // CommentHandler handles HTTP requests related to comments
 type CommentHandler struct {
-	CommentService *service.CommentService
+	CommentService     *service.CommentService
+	IdempotencyService *service.IdempotencyService
 }
 
 // NewCommentHandler creates a new instance of CommentHandler
-func NewCommentHandler(commentService *service.CommentService) *CommentHandler {
-	return &CommentHandler{CommentService: commentService}
+func NewCommentHandler(commentService *service.CommentService, idempotencyService *service.IdempotencyService) *CommentHandler {
+	return &CommentHandler{
+		CommentService:     commentService,
+		IdempotencyService: idempotencyService,
+	}
 }
 
 // GetCommentsByPostID handles GET requests for comments on a specific post
+// Supports optional 'sort' query parameter: 'new', 'old' (default) or 'top'
 func (handler *CommentHandler) GetCommentsByPostID(ctx *gin.Context) {
 	// Get postID from URL parameter
 	postIDStr := ctx.Param("postID")
@@ -40,27 +52,253 @@ func (handler *CommentHandler) GetCommentsByPostID(ctx *gin.Context) {
 		userID = &uidInt
 	}
 
+	// Threads read top-down, so default to oldest first
+	sort := ctx.DefaultQuery("sort", data.CommentSortOld)
+
+	// A 'cursor' parameter (empty for the first page) switches to keyset pagination, which only walks oldest first
+	cursor, paginated := ctx.GetQuery("cursor")
+	if paginated {
+		if sort != data.CommentSortOld {
+			ctx.JSON(
+				http.StatusBadRequest,
+				gin.H{"error": "cursor pagination only supports sort=old"},
+			)
+			return
+		}
+
+		limit, err := parseLimit(ctx, MaxCommentsPageLimit)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		page, err := handler.CommentService.GetCommentsByPostIDPaginated(postID, userID, cursor, limit)
+		if err != nil {
+			handler.respondCommentsError(ctx, err)
+			return
+		}
+
+		respondNegotiated(ctx, http.StatusOK, page)
+		return
+	}
+
 	// Call service layer
-	comments, err := handler.CommentService.GetCommentsByPostID(postID, userID)
+	comments, err := handler.CommentService.GetCommentsByPostID(postID, userID, sort)
+
+	if err != nil {
+		handler.respondCommentsError(ctx, err)
+		return
+	}
+
+	// Gin serializes 'comments' slice into JSON (or XML if requested)
+	respondNegotiatedList(ctx, http.StatusOK, "comments", comments)
+}
+
+// respondCommentsError maps an error from listing a post's comments to a response
+func (handler *CommentHandler) respondCommentsError(ctx *gin.Context, err error) {
+	// Check for not found errors (Not Found 404)
+	if errors.Is(err, data.ErrPostNotFound) {
+		ctx.JSON(
+			http.StatusNotFound,
+			gin.H{"error": "Post not found"},
+		)
+		return
+	}
+
+	// Check for validation errors (Bad Request 400)
+	if strings.Contains(err.Error(), "invalid post ID") ||
+		errors.Is(err, service.ErrValidation) {
+		ctx.JSON(
+			http.StatusBadRequest,
+			gin.H{"error": err.Error()},
+		)
+		return
+	}
+
+	// Otherwise, send ISE status to client
+	ctx.JSON(
+		http.StatusInternalServerError,
+		gin.H{"error": "Failed to fetch comments for the post"})
+}
+
+// CountComments handles GET requests for the number of comments on a post
+func (handler *CommentHandler) CountComments(ctx *gin.Context) {
+	// Get postID from URL parameter
+	postID, err := strconv.Atoi(ctx.Param("postID"))
+	if err != nil {
+		ctx.JSON(
+			http.StatusBadRequest,
+			gin.H{"error": "Invalid post ID"},
+		)
+		return
+	}
+
+	// Call service layer
+	count, err := handler.CommentService.CountCommentsByPostID(postID)
+	if err != nil {
+		// Check for not found errors (Not Found 404)
+		if errors.Is(err, data.ErrPostNotFound) {
+			ctx.JSON(
+				http.StatusNotFound,
+				gin.H{"error": "Post not found"},
+			)
+			return
+		}
+
+		// Check for validation errors (Bad Request 400)
+		if strings.Contains(err.Error(), "invalid post ID") {
+			ctx.JSON(
+				http.StatusBadRequest,
+				gin.H{"error": err.Error()},
+			)
+			return
+		}
+
+		// Otherwise, send ISE status to client
+		ctx.JSON(
+			http.StatusInternalServerError,
+			gin.H{"error": "Failed to count comments for the post"},
+		)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"count": count})
+}
+
+// GetCommentByID handles GET requests for a specific comment by its ID
+func (handler *CommentHandler) GetCommentByID(ctx *gin.Context) {
+	// Get commentID from URL parameter
+	commentID, err := strconv.Atoi(ctx.Param("commentID"))
+	if err != nil {
+		ctx.JSON(
+			http.StatusBadRequest,
+			gin.H{"error": "Invalid comment ID"},
+		)
+		return
+	}
+
+	var userID *int
+	if uid, ok := ctx.Get("userID"); ok {
+		uidInt := uid.(int)
+		userID = &uidInt
+	}
+
+	comment, err := handler.CommentService.GetCommentByID(commentID, userID)
+	if err != nil {
+		// Check for not found errors (Not Found 404)
+		if strings.Contains(err.Error(), "not found") {
+			ctx.JSON(
+				http.StatusNotFound,
+				gin.H{"error": "Comment not found"},
+			)
+			return
+		}
+
+		// Otherwise, send ISE status to client
+		ctx.JSON(
+			http.StatusInternalServerError,
+			gin.H{"error": "Failed to fetch comment"},
+		)
+		return
+	}
+
+	// Gin serializes 'comment' object into JSON (or XML if requested)
+	respondNegotiated(ctx, http.StatusOK, comment)
+}
+
+// CommentStreamKeepAlive is how often an idle comment stream sends a keep-alive comment,
+// so proxies do not close the connection
+const CommentStreamKeepAlive = 15 * time.Second
+
+// StreamComments handles GET requests for a server-sent event stream of new comments on a post
+// Each comment created after the client connects is sent as a "comment" event with the comment as JSON data
+func (handler *CommentHandler) StreamComments(ctx *gin.Context) {
+	// Get postID from URL parameter
+	postID, err := strconv.Atoi(ctx.Param("postID"))
+	if err != nil {
+		ctx.JSON(
+			http.StatusBadRequest,
+			gin.H{"error": "Invalid post ID"},
+		)
+		return
+	}
 
+	// Call service layer to subscribe to the post's comments
+	comments, unsubscribe, err := handler.CommentService.SubscribeToPost(postID)
 	if err != nil {
-		// Send ISE status to client
+		// Check for not found errors (Not Found 404)
+		if errors.Is(err, data.ErrPostNotFound) {
+			ctx.JSON(
+				http.StatusNotFound,
+				gin.H{"error": "Post not found"},
+			)
+			return
+		}
+
+		// Check for validation errors (Bad Request 400)
+		if strings.Contains(err.Error(), "invalid post ID") {
+			ctx.JSON(
+				http.StatusBadRequest,
+				gin.H{"error": err.Error()},
+			)
+			return
+		}
+
+		// Otherwise, send ISE status to client
 		ctx.JSON(
 			http.StatusInternalServerError,
-			gin.H{"error": "Failed to fetch comments for the post"})
+			gin.H{"error": "Failed to stream comments"},
+		)
 		return
 	}
+	defer unsubscribe()
+
+	// Send headers straight away so the client knows the subscription is live
+	ctx.Header("Content-Type", "text/event-stream")
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+	ctx.Header("X-Accel-Buffering", "no") // Stop reverse proxies buffering the stream
+	ctx.Status(http.StatusOK)
+	ctx.Writer.Flush()
+
+	keepAlive := time.NewTicker(CommentStreamKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		// Client disconnected (or the request was otherwise cancelled)
+		case <-ctx.Request.Context().Done():
+			return
 
-	// Gin serializes 'comments' slice into JSON
-	ctx.JSON(http.StatusOK, comments)
+		case comment := <-comments:
+			payload, err := json.Marshal(comment)
+			if err != nil {
+				log.Printf("Failed to encode comment %d for stream: %v", comment.CommentID, err)
+				continue
+			}
+
+			if _, err := fmt.Fprintf(ctx.Writer, "id: %d\nevent: comment\ndata: %s\n\n", comment.CommentID, payload); err != nil {
+				return
+			}
+			ctx.Writer.Flush()
+
+		case <-keepAlive.C:
+			if _, err := io.WriteString(ctx.Writer, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			ctx.Writer.Flush()
+		}
+	}
 }
 
 // CreateCommentRequest defines expected JSON input for new comments
 type CreateCommentRequest struct {
-	Content string `json:"content" binding:"required"`
+	Content         string `json:"content" binding:"required"`
+	QuotedCommentID *int   `json:"quotedCommentID"` // Optional; must be a comment on the same post
 }
 
 // CreateComment handles POST requests for creating new comments
+// Supports an optional Idempotency-Key header so that retried requests do not create duplicates
 func (handler *CommentHandler) CreateComment(ctx *gin.Context) {
 	// Get authenticated user's ID from context (set by AuthMiddleware)
 	userID, exists := ctx.Get("userID")
@@ -94,17 +332,61 @@ func (handler *CommentHandler) CreateComment(ctx *gin.Context) {
 		return
 	}
 
+	// Return the original comment if this request has already been processed
+	fingerprint := gin.H{"postID": postID, "content": req.Content}
+	if req.QuotedCommentID != nil {
+		fingerprint["quotedCommentID"] = *req.QuotedCommentID
+	}
+
+	idempotent, ok := claimIdempotencyKey(
+		ctx,
+		handler.IdempotencyService,
+		userID.(int),
+		"comment",
+		fingerprint,
+	)
+	if !ok {
+		return
+	}
+
+	if idempotent.ResourceID != 0 {
+		uid := userID.(int)
+		comment, err := handler.CommentService.GetCommentByID(idempotent.ResourceID, &uid)
+		if err != nil {
+			// The original comment has since been deleted (Gone 410)
+			if strings.Contains(err.Error(), "not found") {
+				ctx.JSON(
+					http.StatusGone,
+					gin.H{"error": "The comment created with this idempotency key has been deleted"},
+				)
+				return
+			}
+
+			ctx.JSON(
+				http.StatusInternalServerError,
+				gin.H{"error": "Failed to fetch original comment"},
+			)
+			return
+		}
+
+		ctx.Header("Location", commentLocation(comment.CommentID))
+		ctx.JSON(http.StatusCreated, comment)
+		return
+	}
+
 	// Call service layer to create comment
 	comment, err := handler.CommentService.CreateComment(
 		postID,
 		req.Content,
 		userID.(int),
+		req.QuotedCommentID,
 	)
 
 	if err != nil {
+		releaseIdempotencyKey(handler.IdempotencyService, userID.(int), idempotent)
+
 		// Check for validation errors (Bad Request 400)
-		if err.Error() == "content cannot be empty" ||
-			err.Error() == "content exceeds maximum length of 2000 characters" {
+		if errors.Is(err, service.ErrValidation) {
 			ctx.JSON(
 				http.StatusBadRequest,
 				gin.H{"error": err.Error()},
@@ -112,6 +394,42 @@ func (handler *CommentHandler) CreateComment(ctx *gin.Context) {
 			return
 		}
 
+		// Check for missing posts (Not Found 404)
+		if errors.Is(err, data.ErrPostNotFound) {
+			ctx.JSON(
+				http.StatusNotFound,
+				gin.H{"error": "Post not found"},
+			)
+			return
+		}
+
+		// Check for locked posts (Forbidden 403)
+		if errors.Is(err, service.ErrPostLocked) {
+			ctx.JSON(
+				http.StatusForbidden,
+				gin.H{"error": err.Error()},
+			)
+			return
+		}
+
+		// Check for full posts (Conflict 409)
+		if errors.Is(err, service.ErrLimitReached) {
+			ctx.JSON(
+				http.StatusConflict,
+				gin.H{"error": err.Error()},
+			)
+			return
+		}
+
+		// Check for repeated comments (Conflict 409)
+		if errors.Is(err, service.ErrDuplicateComment) {
+			ctx.JSON(
+				http.StatusConflict,
+				gin.H{"error": "Duplicate comment"},
+			)
+			return
+		}
+
 		// Otherwise, send ISE status to client
 		ctx.JSON(
 			http.StatusInternalServerError,
@@ -120,10 +438,65 @@ func (handler *CommentHandler) CreateComment(ctx *gin.Context) {
 		return
 	}
 
+	completeIdempotencyKey(handler.IdempotencyService, userID.(int), "comment", idempotent, comment.CommentID)
+
 	// Return created comment
+	ctx.Header("Location", commentLocation(comment.CommentID))
 	ctx.JSON(http.StatusCreated, comment)
 }
 
+// CreateTopicComment handles POST requests for creating comments via a topic-scoped URL
+// Verifies that the post belongs to the topic before delegating to CreateComment
+func (handler *CommentHandler) CreateTopicComment(ctx *gin.Context) {
+	// Get topicID and postID from URL parameters
+	topicID, err := strconv.Atoi(ctx.Param("topicID"))
+	if err != nil {
+		ctx.JSON(
+			http.StatusBadRequest,
+			gin.H{"error": "Invalid topic ID"})
+		return
+	}
+
+	postID, err := strconv.Atoi(ctx.Param("postID"))
+	if err != nil {
+		ctx.JSON(
+			http.StatusBadRequest,
+			gin.H{"error": "Invalid post ID"})
+		return
+	}
+
+	// Call service layer to verify the post is under the topic
+	belongs, err := handler.CommentService.PostBelongsToTopic(postID, topicID)
+	if err != nil {
+		// Check for validation errors (Bad Request 400)
+		if strings.Contains(err.Error(), "invalid post ID") ||
+			strings.Contains(err.Error(), "invalid topic ID") {
+			ctx.JSON(
+				http.StatusBadRequest,
+				gin.H{"error": err.Error()},
+			)
+			return
+		}
+
+		// Otherwise, send ISE status to client
+		ctx.JSON(
+			http.StatusInternalServerError,
+			gin.H{"error": "Failed to create comment"},
+		)
+		return
+	}
+
+	if !belongs {
+		ctx.JSON(
+			http.StatusNotFound,
+			gin.H{"error": "Post not found in topic"},
+		)
+		return
+	}
+
+	handler.CreateComment(ctx)
+}
+
 // UpdateCommentRequest defines expected JSON input for updating comments
 type UpdateCommentRequest struct {
 	Content string `json:"content" binding:"required"`
@@ -187,8 +560,7 @@ func (handler *CommentHandler) UpdateComment(ctx *gin.Context) {
 		}
 
 		// Check for validation errors (Bad Request 400)
-		if strings.Contains(errMsg, "cannot be empty") ||
-			strings.Contains(errMsg, "exceeds maximum length") {
+		if errors.Is(err, service.ErrValidation) {
 			ctx.JSON(
 				http.StatusBadRequest,
 				gin.H{"error": errMsg},
@@ -209,6 +581,7 @@ func (handler *CommentHandler) UpdateComment(ctx *gin.Context) {
 }
 
 // DeleteComment handles DELETE requests for deleting comments
+// Comments quoted by replies are soft-deleted (content shown as "[deleted]"); 204 is returned either way
 func (handler *CommentHandler) DeleteComment(ctx *gin.Context) {
 	// Get authenticated user's ID from context (set by AuthMiddleware)
 	userID, exists := ctx.Get("userID")
@@ -0,0 +1,219 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultCompressionMinBytes is the smallest response body that is compressed (1KB)
+// Smaller bodies gain little and cost CPU on both ends
+const DefaultCompressionMinBytes = 1 << 10
+
+// incompressibleContentTypes are content type prefixes that are already compressed
+var incompressibleContentTypes = []string{
+	"image/",
+	"video/",
+	"audio/",
+	"application/gzip",
+	"application/zip",
+	"application/x-gzip",
+	"application/zstd",
+	"text/event-stream", // Streamed, so must not be buffered
+}
+
+// CompressionMiddleware gzip- or deflate-encodes response bodies of at least minBytes
+// when the client accepts it (gzip preferred). Requests to excludedPaths are never compressed
+func CompressionMiddleware(minBytes int, excludedPaths ...string) gin.HandlerFunc {
+	excluded := make(map[string]bool, len(excludedPaths))
+	for _, path := range excludedPaths {
+		excluded[path] = true
+	}
+
+	return func(ctx *gin.Context) {
+		if excluded[ctx.Request.URL.Path] || ctx.Request.Method == http.MethodHead {
+			ctx.Next()
+			return
+		}
+
+		// Caches must key on Accept-Encoding, whether or not this response ends up compressed
+		ctx.Writer.Header().Add("Vary", "Accept-Encoding")
+
+		encoding := negotiateEncoding(ctx.GetHeader("Accept-Encoding"))
+		if encoding == "" {
+			ctx.Next()
+			return
+		}
+
+		writer := &compressWriter{
+			ResponseWriter: ctx.Writer,
+			encoding:       encoding,
+			minBytes:       minBytes,
+		}
+		ctx.Writer = writer
+
+		// Proceed to next handler, then flush whatever is still buffered
+		ctx.Next()
+		writer.finish()
+	}
+}
+
+// negotiateEncoding picks the response encoding from an Accept-Encoding header
+// Returns "" if neither gzip nor deflate is acceptable
+func negotiateEncoding(acceptEncoding string) string {
+	accepted := map[string]bool{}
+
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+
+		// A zero quality value explicitly refuses the coding
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if value, err := strconv.ParseFloat(q, 64); err == nil && value == 0 {
+				continue
+			}
+		}
+
+		accepted[coding] = true
+	}
+
+	switch {
+	case accepted["gzip"] || accepted["*"]:
+		return "gzip"
+	case accepted["deflate"]:
+		return "deflate"
+	default:
+		return ""
+	}
+}
+
+// compressWriter buffers the start of a response until it knows whether to compress it
+// Bodies shorter than minBytes, already-encoded bodies, and incompressible content types are written as-is
+type compressWriter struct {
+	gin.ResponseWriter
+	encoding   string
+	minBytes   int
+	buf        bytes.Buffer
+	compressor io.WriteCloser // Set once the response is being compressed
+	decided    bool           // Whether the compress/passthrough decision has been made
+}
+
+func (w *compressWriter) Write(data []byte) (int, error) {
+	if w.decided {
+		if w.compressor != nil {
+			return w.compressor.Write(data)
+		}
+		return w.ResponseWriter.Write(data)
+	}
+
+	w.buf.Write(data)
+	if w.buf.Len() >= w.minBytes {
+		if err := w.decide(true); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(data), nil
+}
+
+func (w *compressWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Written reports whether any part of the body has been written, including buffered bytes
+func (w *compressWriter) Written() bool {
+	return w.buf.Len() > 0 || w.ResponseWriter.Written()
+}
+
+// Flush sends buffered bytes to the client
+// Responses flushed before reaching minBytes are streamed uncompressed
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		w.decide(false)
+	}
+
+	if flusher, ok := w.compressor.(interface{ Flush() error }); ok {
+		flusher.Flush()
+	}
+
+	w.ResponseWriter.Flush()
+}
+
+// Hijack disables compression, since the connection is no longer an HTTP response
+func (w *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.decided = true
+	return w.ResponseWriter.Hijack()
+}
+
+// decide commits to compressing (if allowed and compress is true) or passing the body through,
+// then writes out the buffered bytes
+func (w *compressWriter) decide(compress bool) error {
+	w.decided = true
+
+	header := w.ResponseWriter.Header()
+	if compress && w.isCompressible(header) {
+		header.Set("Content-Encoding", w.encoding)
+		header.Del("Content-Length") // Length of the original body no longer applies
+
+		if w.encoding == "gzip" {
+			w.compressor = gzip.NewWriter(w.ResponseWriter)
+		} else {
+			compressor, err := flate.NewWriter(w.ResponseWriter, flate.DefaultCompression)
+			if err != nil {
+				return err
+			}
+			w.compressor = compressor
+		}
+	}
+
+	buffered := w.buf.Bytes()
+	w.buf = bytes.Buffer{}
+
+	if len(buffered) == 0 {
+		return nil
+	}
+
+	var err error
+	if w.compressor != nil {
+		_, err = w.compressor.Write(buffered)
+	} else {
+		_, err = w.ResponseWriter.Write(buffered)
+	}
+
+	return err
+}
+
+// isCompressible reports whether the response may be compressed based on its headers
+func (w *compressWriter) isCompressible(header http.Header) bool {
+	if header.Get("Content-Encoding") != "" {
+		return false
+	}
+
+	contentType := strings.ToLower(header.Get("Content-Type"))
+	for _, prefix := range incompressibleContentTypes {
+		if strings.HasPrefix(contentType, prefix) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// finish writes out any still-buffered (short) body and closes the compressor
+func (w *compressWriter) finish() {
+	if !w.decided {
+		w.decide(false)
+	}
+
+	if w.compressor != nil {
+		w.compressor.Close()
+	}
+}
@@ -0,0 +1,31 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// checkNotModified sets the Last-Modified header and honors If-Modified-Since at second granularity
+// Returns true (having written 304 Not Modified) if the client's copy is still current
+// Callers pass the time of the resource's last visible change (e.g. Post.ModifiedAt, which includes votes)
+func checkNotModified(ctx *gin.Context, lastModified time.Time) bool {
+	// HTTP dates have no sub-second part
+	lastModified = lastModified.UTC().Truncate(time.Second)
+	ctx.Header("Last-Modified", lastModified.Format(http.TimeFormat))
+
+	ifModifiedSince := ctx.GetHeader("If-Modified-Since")
+	if ifModifiedSince == "" {
+		return false
+	}
+
+	// Unparseable dates are ignored, as RFC 9110 requires
+	since, err := http.ParseTime(ifModifiedSince)
+	if err != nil || lastModified.After(since) {
+		return false
+	}
+
+	ctx.Status(http.StatusNotModified)
+	return true
+}
@@ -0,0 +1,23 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/adzzfarr/gossip-with-go/backend/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// ConfigHandler exposes server settings that clients need to mirror, such as content limits
+type ConfigHandler struct {
+	Limits service.ContentLimits
+}
+
+// NewConfigHandler creates a new instance of ConfigHandler
+func NewConfigHandler(limits service.ContentLimits) *ConfigHandler {
+	return &ConfigHandler{Limits: limits}
+}
+
+// GetContentLimits handles GET requests for the maximum lengths of titles, descriptions, posts and comments
+func (handler *ConfigHandler) GetContentLimits(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, handler.Limits)
+}
@@ -0,0 +1,57 @@
+package api
+
+import (
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// JSONContentTypeMiddleware rejects POST, PUT and PATCH bodies that are not JSON with 415 Unsupported Media Type
+// Bodies without a Content-Type are still bound as JSON unless strict is set, as older clients omit it
+func JSONContentTypeMiddleware(strict bool) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		switch ctx.Request.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch:
+		default:
+			ctx.Next()
+			return
+		}
+
+		// Requests without a body (e.g. pinning) have nothing to bind
+		if ctx.Request.Body == nil || ctx.Request.Body == http.NoBody || ctx.Request.ContentLength == 0 {
+			ctx.Next()
+			return
+		}
+
+		contentType := ctx.GetHeader("Content-Type")
+
+		if contentType == "" && !strict {
+			ctx.Next()
+			return
+		}
+
+		if !isJSONMediaType(contentType) {
+			ctx.JSON(
+				http.StatusUnsupportedMediaType,
+				gin.H{"error": "Content-Type must be application/json"},
+			)
+			ctx.Abort()
+			return
+		}
+
+		// Proceed to next handler
+		ctx.Next()
+	}
+}
+
+// isJSONMediaType reports whether contentType is application/json or a +json type, ignoring parameters such as charset
+func isJSONMediaType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	return mediaType == "application/json" || (strings.HasPrefix(mediaType, "application/") && strings.HasSuffix(mediaType, "+json"))
+}
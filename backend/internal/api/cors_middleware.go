@@ -0,0 +1,55 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultCORSMaxAge is how long browsers may cache a preflight response by default
+const DefaultCORSMaxAge = 12 * time.Hour
+
+// ErrCORSWildcardWithCredentials is returned when credentialed CORS is configured with a wildcard origin
+var ErrCORSWildcardWithCredentials = errors.New("CORS credentials cannot be allowed for wildcard origins")
+
+// CORSOptions configures CORSMiddleware
+type CORSOptions struct {
+	AllowOrigins     []string      // Exact origins, e.g. "http://localhost:5173"; "*" allows any (without credentials)
+	AllowCredentials bool          // Sends Access-Control-Allow-Credentials: true, for cookie-based sessions
+	MaxAge           time.Duration // Sent as Access-Control-Max-Age (whole seconds); 0 omits it
+}
+
+// CORSMiddleware returns the cross-origin middleware for opts
+// Credentialed requests must only ever be allowed for explicit origins, so a wildcard origin combined with
+// AllowCredentials is rejected here rather than at request time
+func CORSMiddleware(opts CORSOptions) (gin.HandlerFunc, error) {
+	if len(opts.AllowOrigins) == 0 {
+		return nil, fmt.Errorf("CORS requires at least one allowed origin")
+	}
+
+	if opts.MaxAge < 0 {
+		return nil, fmt.Errorf("invalid CORS max age: %s", opts.MaxAge)
+	}
+
+	for _, origin := range opts.AllowOrigins {
+		if opts.AllowCredentials && strings.Contains(origin, "*") {
+			return nil, fmt.Errorf("%w: %q", ErrCORSWildcardWithCredentials, origin)
+		}
+	}
+
+	return cors.New(cors.Config{
+		AllowOrigins: opts.AllowOrigins,
+		AllowMethods: []string{
+			http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodOptions,
+		},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", IdempotencyKeyHeader},
+		ExposeHeaders:    []string{"Content-Length", "Location"},
+		AllowCredentials: opts.AllowCredentials,
+		MaxAge:           opts.MaxAge,
+	}), nil
+}
@@ -0,0 +1,32 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ConfigureFallbackHandlers makes the router answer unknown paths with a JSON 404,
+// and known paths requested with an unsupported method with a JSON 405
+func ConfigureFallbackHandlers(router *gin.Engine) {
+	router.HandleMethodNotAllowed = true
+	router.NoRoute(NoRoute)
+	router.NoMethod(NoMethod)
+}
+
+// NoRoute handles requests for paths that match no route (Not Found 404)
+func NoRoute(ctx *gin.Context) {
+	ctx.JSON(
+		http.StatusNotFound,
+		gin.H{"error": "not found"},
+	)
+}
+
+// NoMethod handles requests whose path exists but not for the request method (Method Not Allowed 405)
+// Gin sets the Allow header, listing the path's methods, before calling it
+func NoMethod(ctx *gin.Context) {
+	ctx.JSON(
+		http.StatusMethodNotAllowed,
+		gin.H{"error": "method not allowed"},
+	)
+}
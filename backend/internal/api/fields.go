@@ -0,0 +1,85 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/adzzfarr/gossip-with-go/backend/internal/data"
+	"github.com/gin-gonic/gin"
+)
+
+// parseFields reads the comma-separated 'fields' query parameter (e.g. ?fields=topicID,title)
+// Returns nil if no fields were requested, meaning items are returned in full
+func parseFields(ctx *gin.Context) map[string]bool {
+	fieldsStr := ctx.Query("fields")
+	if strings.TrimSpace(fieldsStr) == "" {
+		return nil
+	}
+
+	fields := make(map[string]bool)
+	for _, field := range strings.Split(fieldsStr, ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			fields[field] = true
+		}
+	}
+
+	return fields
+}
+
+// projectFields reduces each item to the requested JSON keys, always keeping primaryKey
+// Unknown field names are ignored
+func projectFields[T any](items []T, fields map[string]bool, primaryKey string) ([]map[string]any, error) {
+	projected := make([]map[string]any, 0, len(items))
+
+	for _, item := range items {
+		// Go through the item's JSON form, so keys match what clients otherwise receive
+		encoded, err := json.Marshal(item)
+		if err != nil {
+			return nil, err
+		}
+
+		var full map[string]any
+		if err := json.Unmarshal(encoded, &full); err != nil {
+			return nil, err
+		}
+
+		kept := make(map[string]any)
+		for key, value := range full {
+			if key == primaryKey || fields[key] {
+				kept[key] = value
+			}
+		}
+
+		projected = append(projected, kept)
+	}
+
+	return projected, nil
+}
+
+// respondPage writes a page of items, projected to the fields in the 'fields' query parameter if present
+// Projection applies to JSON only; XML responses always contain full items
+func respondPage[T any](ctx *gin.Context, status int, page *data.PagedResponse[T], primaryKey string) {
+	fields := parseFields(ctx)
+	if fields == nil || wantsXML(ctx) {
+		respondNegotiated(ctx, status, page)
+		return
+	}
+
+	items, err := projectFields(page.Items, fields, primaryKey)
+	if err != nil {
+		ctx.JSON(
+			http.StatusInternalServerError,
+			gin.H{"error": "Failed to encode response"})
+		return
+	}
+
+	ctx.JSON(status, data.PagedResponse[map[string]any]{
+		Items:          items,
+		Total:          page.Total,
+		TotalEstimated: page.TotalEstimated,
+		Limit:          page.Limit,
+		HasMore:        page.HasMore,
+		NextCursor:     page.NextCursor,
+	})
+}
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Health check defaults
+const (
+	DefaultHealthCheckInterval = 2 * time.Second // How long a successful ping is reused
+	healthPingTimeout          = 2 * time.Second
+)
+
+// HealthChecker pings the database for the health endpoint, reusing a successful result for Interval
+// so frequent polling does not each issue a query; failures are not reused, so an outage shows on the next check
+type HealthChecker struct {
+	Ping     func(context.Context) error
+	Interval time.Duration
+	now      func() time.Time // Replaced in tests
+
+	mu        sync.Mutex // Held during a ping, so concurrent checks wait for it rather than pinging again
+	lastCheck time.Time
+	healthy   bool
+}
+
+// NewHealthChecker creates a HealthChecker calling ping (e.g. a pool's Ping method)
+func NewHealthChecker(ping func(context.Context) error, interval time.Duration) *HealthChecker {
+	return &HealthChecker{
+		Ping:     ping,
+		Interval: interval,
+		now:      time.Now,
+	}
+}
+
+// Check returns nil if the database answered a ping within the last Interval, otherwise pings it again
+func (checker *HealthChecker) Check(ctx context.Context) error {
+	checker.mu.Lock()
+	defer checker.mu.Unlock()
+
+	if checker.healthy && checker.now().Sub(checker.lastCheck) < checker.Interval {
+		return nil
+	}
+
+	pingCtx, cancel := context.WithTimeout(ctx, healthPingTimeout)
+	defer cancel()
+
+	err := checker.Ping(pingCtx)
+	checker.lastCheck = checker.now()
+	checker.healthy = err == nil
+
+	return err
+}
+
+// HealthHandler returns a handler reporting UP if the database is reachable (200), or DOWN if not (503)
+func HealthHandler(checker *HealthChecker) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if err := checker.Check(ctx.Request.Context()); err != nil {
+			log.Printf("Health check failed: %v", err)
+			ctx.JSON(
+				http.StatusServiceUnavailable,
+				gin.H{"status": "DOWN", "error": "Database unavailable"})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"status": "UP"})
+	}
+}
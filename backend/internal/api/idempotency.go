@@ -0,0 +1,109 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/adzzfarr/gossip-with-go/backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// IdempotencyKeyHeader is the request header clients use to make creation requests retry-safe
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// idempotentRequest holds the Idempotency-Key state of a single creation request
+type idempotentRequest struct {
+	Key        string // Empty if the client did not send the header
+	ResourceID int    // ID of the previously created resource (0 if the key was claimed for this request)
+}
+
+// claimIdempotencyKey claims the request's Idempotency-Key header (if any) before the resource is created,
+// so concurrent retries cannot both create it; the claim must be followed by completeIdempotencyKey or releaseIdempotencyKey
+// Writes an error response and returns ok=false if the request must not proceed
+func claimIdempotencyKey(
+	ctx *gin.Context,
+	idempotencyService *service.IdempotencyService,
+	userID int,
+	resourceType string,
+	payload any,
+) (idempotentRequest, bool) {
+	request := idempotentRequest{Key: ctx.GetHeader(IdempotencyKeyHeader)}
+	if request.Key == "" {
+		return request, true
+	}
+
+	requestHash, err := idempotencyService.HashRequest(payload)
+	if err != nil {
+		ctx.JSON(
+			http.StatusInternalServerError,
+			gin.H{"error": "Failed to process idempotency key"},
+		)
+		return request, false
+	}
+
+	resourceID, claimed, err := idempotencyService.Claim(userID, request.Key, resourceType, requestHash)
+	if err != nil {
+		// Same key used with a different request body, or its first request is still running (Conflict 409)
+		if errors.Is(err, service.ErrIdempotencyKeyReused) || errors.Is(err, service.ErrIdempotencyKeyInProgress) {
+			ctx.JSON(
+				http.StatusConflict,
+				gin.H{"error": err.Error()},
+			)
+			return request, false
+		}
+
+		// Check for validation errors (Bad Request 400)
+		if err.Error() == "invalid idempotency key" {
+			ctx.JSON(
+				http.StatusBadRequest,
+				gin.H{"error": "Invalid Idempotency-Key header"},
+			)
+			return request, false
+		}
+
+		// Otherwise, send ISE status to client
+		ctx.JSON(
+			http.StatusInternalServerError,
+			gin.H{"error": "Failed to process idempotency key"},
+		)
+		return request, false
+	}
+
+	if !claimed {
+		request.ResourceID = resourceID
+	}
+
+	return request, true
+}
+
+// completeIdempotencyKey records the created resource against the request's claimed Idempotency-Key
+// Failures are logged rather than surfaced, since the resource has already been created
+func completeIdempotencyKey(
+	idempotencyService *service.IdempotencyService,
+	userID int,
+	resourceType string,
+	request idempotentRequest,
+	resourceID int,
+) {
+	if request.Key == "" {
+		return
+	}
+
+	if err := idempotencyService.Complete(userID, request.Key, resourceID); err != nil {
+		log.Printf("Failed to save idempotency key for %s %d: %v", resourceType, resourceID, err)
+	}
+}
+
+// releaseIdempotencyKey frees the request's claimed Idempotency-Key after its resource failed to be created
+// Failures are logged; an unreleased claim is taken over once service.IdempotencyPendingTimeout passes
+func releaseIdempotencyKey(idempotencyService *service.IdempotencyService, userID int, request idempotentRequest) {
+	if request.Key == "" {
+		return
+	}
+
+	if err := idempotencyService.Release(userID, request.Key); err != nil {
+		log.Printf("Failed to release idempotency key: %v", err)
+	}
+}
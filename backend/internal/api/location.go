@@ -0,0 +1,21 @@
+package api
+
+import "fmt"
+
+// APIBasePath is the path prefix under which all versioned routes are registered
+const APIBasePath = "/api/v1"
+
+// topicLocation returns the canonical URL of a topic (used for Location headers)
+func topicLocation(topicID int) string {
+	return fmt.Sprintf("%s/topics/%d", APIBasePath, topicID)
+}
+
+// postLocation returns the canonical URL of a post (used for Location headers)
+func postLocation(postID int) string {
+	return fmt.Sprintf("%s/posts/%d", APIBasePath, postID)
+}
+
+// commentLocation returns the canonical URL of a comment (used for Location headers)
+func commentLocation(commentID int) string {
+	return fmt.Sprintf("%s/comments/%d", APIBasePath, commentID)
+}
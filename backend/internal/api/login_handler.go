@@ -1,7 +1,9 @@
 package api
 
 import (
+	"errors"
 	"net/http"
+	"time"
 
 	"github.com/adzzfarr/gossip-with-go/backend/internal/service"
 
@@ -24,7 +26,7 @@ func NewLoginHandler(loginService *service.LoginService, jwtService *service.JWT
 
 // LoginCredentials defines expected JSON input for user login
 type LoginCredentials struct {
-	Username string `json:"username" binding:"required"`
+	Username string `json:"username" binding:"required"` // Username or email
 	Password string `json:"password" binding:"required"`
 }
 
@@ -40,9 +42,19 @@ func (handler *LoginHandler) LoginUser(ctx *gin.Context) {
 	}
 
 	// Call service layer to authenticate user
-	user, err := handler.LoginService.Login(req.Username, req.Password)
+	user, err := handler.LoginService.Login(req.Username, req.Password, ctx.ClientIP())
 
 	if err != nil {
+		// Too many consecutive failures (Too Many Requests 429)
+		// The message is the same whether or not the username exists
+		if errors.Is(err, service.ErrLoginLocked) {
+			ctx.JSON(
+				http.StatusTooManyRequests,
+				gin.H{"error": "Too many failed login attempts, try again later"},
+			)
+			return
+		}
+
 		// Authentication failed
 		ctx.JSON(
 			http.StatusUnauthorized,
@@ -52,7 +64,7 @@ func (handler *LoginHandler) LoginUser(ctx *gin.Context) {
 	}
 
 	// Generate JWT token
-	token, err := handler.JWTService.GenerateToken(user.UserID, user.Username)
+	token, expiresAt, err := handler.JWTService.GenerateToken(user.UserID, user.Username, user.TokenVersion)
 
 	if err != nil {
 		ctx.JSON(
@@ -62,12 +74,14 @@ func (handler *LoginHandler) LoginUser(ctx *gin.Context) {
 		return
 	}
 
-	// Return token to client
+	// Return token to client, with when it expires so the client can log in again beforehand
 	ctx.JSON(
 		http.StatusOK,
 		gin.H{
-			"message": "Login successful",
-			"token":   token,
+			"message":   "Login successful",
+			"token":     token,
+			"expiresAt": expiresAt.UTC().Format(time.RFC3339),
+			"expiresIn": int64(handler.JWTService.TokenDuration.Seconds()),
 		},
 	)
 }
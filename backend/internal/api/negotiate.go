@@ -0,0 +1,43 @@
+package api
+
+import (
+	"encoding/xml"
+
+	"github.com/gin-gonic/gin"
+)
+
+// wantsXML reports whether the client's Accept header prefers XML over JSON (JSON is the default)
+func wantsXML(ctx *gin.Context) bool {
+	switch ctx.NegotiateFormat(gin.MIMEJSON, gin.MIMEXML, gin.MIMEXML2) {
+	case gin.MIMEXML, gin.MIMEXML2:
+		return true
+	default:
+		return false
+	}
+}
+
+// respondNegotiated writes body as XML if the client asks for it, otherwise as JSON
+func respondNegotiated(ctx *gin.Context, status int, body any) {
+	if wantsXML(ctx) {
+		ctx.XML(status, body)
+		return
+	}
+
+	ctx.JSON(status, body)
+}
+
+// xmlList wraps a slice in a single root element, since XML (unlike JSON) has no top-level arrays
+type xmlList struct {
+	XMLName xml.Name
+	Items   any `xml:"item"`
+}
+
+// respondNegotiatedList writes a slice as a JSON array, or as XML under a rootName element
+func respondNegotiatedList(ctx *gin.Context, status int, rootName string, items any) {
+	if wantsXML(ctx) {
+		ctx.XML(status, xmlList{XMLName: xml.Name{Local: rootName}, Items: items})
+		return
+	}
+
+	ctx.JSON(status, items)
+}
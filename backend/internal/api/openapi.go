@@ -0,0 +1,461 @@
+package api
+
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/adzzfarr/gossip-with-go/backend/internal/data"
+	"github.com/adzzfarr/gossip-with-go/backend/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// authRequirement describes how an operation authenticates its caller
+type authRequirement int
+
+const (
+	authNone     authRequirement = iota // Public
+	authOptional                        // Public, but a bearer token personalises the response
+	authRequired                        // Bearer token required
+	authAdmin                           // Bearer token of an admin required
+)
+
+// queryParam documents a query string parameter
+type queryParam struct {
+	Name        string
+	Type        string // OpenAPI primitive type, e.g. "integer"
+	Description string
+}
+
+// apiOperation documents a single route under APIBasePath
+// Request and Response hold zero values of the body types, so schemas are generated from the same structs the handlers use
+type apiOperation struct {
+	Method   string
+	Path     string // Gin-style path, e.g. "/topics/:topicID"
+	Summary  string
+	Auth     authRequirement
+	Query    []queryParam
+	Request  any // nil if the operation takes no body
+	Status   int // Success status code
+	Response any // nil if the success response has no body
+}
+
+// Response bodies built with gin.H in the handlers, mirrored here for documentation only
+type (
+	errorResponse struct {
+		Error string `json:"error"`
+		Code  string `json:"code,omitempty"` // Only on 401s from AuthMiddleware (AUTH_MISSING, AUTH_MALFORMED, AUTH_EXPIRED, AUTH_INVALID, AUTH_REVOKED)
+	}
+
+	registrationResponse struct {
+		Message string    `json:"message"`
+		User    data.User `json:"user"`
+	}
+
+	messageResponse struct {
+		Message string `json:"message"`
+	}
+
+	loginResponse struct {
+		Message   string `json:"message"`
+		Token     string `json:"token"`
+		ExpiresAt string `json:"expiresAt"` // RFC 3339
+		ExpiresIn int64  `json:"expiresIn"` // Seconds
+	}
+
+	voteResponse struct {
+		Message   string `json:"message"`
+		VoteCount int    `json:"voteCount"`
+		UserVote  *int   `json:"userVote"`
+	}
+
+	countResponse struct {
+		Count int `json:"count"`
+	}
+
+	voteCountsResponse struct {
+		VoteCounts map[string]int `json:"voteCounts"` // Keyed by post ID
+	}
+)
+
+var (
+	limitParam  = queryParam{"limit", "integer", "Page size (larger values are clamped to the endpoint's maximum)"}
+	offsetParam = queryParam{"offset", "integer", "Number of items to skip"}
+	fieldsParam = queryParam{"fields", "string", "Comma-separated item fields to return (JSON only; the ID is always included)"}
+)
+
+// apiOperations lists every route registered under APIBasePath
+// Keep in sync with the routes in cmd/server/main.go
+var apiOperations = []apiOperation{
+	// Auth
+	{http.MethodPost, "/users", "Register a new user", authNone, nil, UserRegistrationRequest{}, http.StatusCreated, registrationResponse{}},
+	{http.MethodPost, "/login", "Log in and receive a bearer token", authNone, nil, LoginCredentials{}, http.StatusOK, loginResponse{}},
+	{http.MethodGet, "/auth/password-policy", "Get the password rules enforced on registration", authNone, nil, nil, http.StatusOK, service.PasswordPolicy{}},
+	{http.MethodPost, "/auth/forgot-password", "Email a single-use password reset token to the user with this username or email, if they have one (always succeeds)", authNone, nil, ForgotPasswordRequest{}, http.StatusOK, messageResponse{}},
+	{http.MethodPost, "/auth/reset-password", "Set a new password using a reset token", authNone, nil, ResetPasswordRequest{}, http.StatusOK, messageResponse{}},
+	{http.MethodGet, "/version", "Get the running build's version, commit, build time and uptime", authNone, nil, nil, http.StatusOK, versionResponse{}},
+	{http.MethodGet, "/config/limits", "Get the maximum lengths of titles, descriptions, posts and comments", authNone, nil, nil, http.StatusOK, service.ContentLimits{}},
+
+	// Topics
+	{http.MethodGet, "/topics", "List topics, pinned first (or fetch specific topics via 'ids')", authOptional,
+		[]queryParam{
+			limitParam,
+			offsetParam,
+			fieldsParam,
+			{"createdBy", "integer", "Only list topics created by this user ID (always counted exactly; cannot be combined with includeLatestPost or exactCount=false)"},
+			{"includeLatestPost", "boolean", "Embed each topic's most recent post (ID, title and creation time) as 'latestPost'"},
+			{"exactCount", "boolean", "'false' estimates 'total' from planner statistics (fast, but may lag recent writes; 'totalEstimated' is then set), 'true' always counts exactly; by default only large tables are estimated"},
+			{"ids", "string", "Comma-separated topic IDs; returns a plain array of those topics"},
+			{"stream", "boolean", "If 'true', streams every topic as a plain array (pagination is ignored)"},
+		},
+		nil, http.StatusOK, data.PagedResponse[*data.Topic]{}},
+	{http.MethodGet, "/topics/:topicID", "Get a topic", authOptional, nil, nil, http.StatusOK, data.Topic{}},
+	{http.MethodGet, "/topics/trending", "List the topics with the most posts and comments in the trending window (default 48 hours; at most 10)", authOptional, nil, nil, http.StatusOK, []*data.TrendingTopic{}},
+	{http.MethodGet, "/topics/slug/:slug", "Get a topic by its slug", authOptional, nil, nil, http.StatusOK, data.Topic{}},
+	{http.MethodGet, "/topics/:topicID/full", "Get a topic with its first page of posts", authOptional, []queryParam{limitParam}, nil, http.StatusOK, data.TopicWithPosts{}},
+	{http.MethodPost, "/topics", "Create a topic (refused with 429 once the user has created the daily maximum in the past 24 hours)", authRequired, nil, CreateTopicRequest{}, http.StatusCreated, data.Topic{}},
+	{http.MethodPut, "/topics/:topicID", "Replace a topic's title and description", authRequired, nil, UpdateTopicRequest{}, http.StatusOK, data.Topic{}},
+	{http.MethodPatch, "/topics/:topicID", "Partially update a topic", authRequired, nil, PatchTopicRequest{}, http.StatusOK, data.Topic{}},
+	{http.MethodPatch, "/topics/:topicID/description", "Update only a topic's description", authRequired, nil, UpdateTopicDescriptionRequest{}, http.StatusOK, data.Topic{}},
+	{http.MethodDelete, "/topics/:topicID", "Delete a topic", authRequired, nil, nil, http.StatusNoContent, nil},
+	{http.MethodPost, "/topics/:topicID/pin", "Pin a topic", authAdmin, nil, nil, http.StatusOK, data.Topic{}},
+	{http.MethodDelete, "/topics/:topicID/pin", "Unpin a topic", authAdmin, nil, nil, http.StatusOK, data.Topic{}},
+
+	// Posts
+	{http.MethodGet, "/topics/:topicID/posts", "List a topic's posts, pinned first", authOptional,
+		[]queryParam{limitParam, offsetParam, fieldsParam, {"sort", "string", "One of 'new' (default) or 'controversial'"}},
+		nil, http.StatusOK, data.PagedResponse[*data.Post]{}},
+	{http.MethodGet, "/posts/:postID", "Get a post", authOptional,
+		[]queryParam{{"includeTopic", "boolean", "Embed the post's topic (ID, title and slug) as 'topic'"}},
+		nil, http.StatusOK, data.Post{}},
+	{http.MethodPost, "/topics/:topicID/posts", "Create a post (supports Idempotency-Key)", authRequired, nil, CreatePostRequest{}, http.StatusCreated, data.Post{}},
+	{http.MethodPut, "/posts/:postID", "Replace a post's title and content", authRequired, nil, UpdatePostRequest{}, http.StatusOK, data.Post{}},
+	{http.MethodPatch, "/posts/:postID", "Partially update a post", authRequired, nil, PatchPostRequest{}, http.StatusOK, data.Post{}},
+	{http.MethodDelete, "/posts/:postID", "Delete a post", authRequired, nil, nil, http.StatusNoContent, nil},
+	{http.MethodPost, "/posts/:postID/lock", "Lock or unlock a post against new comments (creator or admin)", authRequired, nil, nil, http.StatusOK, data.Post{}},
+	{http.MethodPost, "/posts/:postID/pin", "Pin a post", authAdmin, nil, nil, http.StatusOK, data.Post{}},
+	{http.MethodDelete, "/posts/:postID/pin", "Unpin a post", authAdmin, nil, nil, http.StatusOK, data.Post{}},
+	{http.MethodGet, "/topics/:topicID/posts.csv", "Export a topic's posts as CSV", authAdmin, nil, nil, http.StatusOK, nil},
+
+	// Comments
+	{http.MethodGet, "/posts/:postID/comments", "List a post's comments (or, given 'cursor', a page of them wrapped with 'nextCursor')", authOptional,
+		[]queryParam{
+			{"sort", "string", "One of 'old' (default), 'new' or 'top'"},
+			{"cursor", "string", "Opaque position from a previous page's 'nextCursor'; pass it empty to start keyset pagination (oldest first only)"},
+			limitParam,
+		},
+		nil, http.StatusOK, []*data.Comment{}},
+	{http.MethodGet, "/posts/:postID/comments/count", "Count a post's comments", authOptional, nil, nil, http.StatusOK, countResponse{}},
+	{http.MethodGet, "/posts/:postID/comments/stream", "Stream new comments on a post as server-sent 'comment' events", authOptional, nil, nil, http.StatusOK, nil},
+	{http.MethodGet, "/comments/:commentID", "Get a comment", authOptional, nil, nil, http.StatusOK, data.Comment{}},
+	{http.MethodPost, "/posts/:postID/comments", "Create a comment (supports Idempotency-Key)", authRequired, nil, CreateCommentRequest{}, http.StatusCreated, data.Comment{}},
+	{http.MethodPost, "/topics/:topicID/posts/:postID/comments", "Create a comment on a post within a topic", authRequired, nil, CreateCommentRequest{}, http.StatusCreated, data.Comment{}},
+	{http.MethodPut, "/comments/:commentID", "Replace a comment's content", authRequired, nil, UpdateCommentRequest{}, http.StatusOK, data.Comment{}},
+	{http.MethodDelete, "/comments/:commentID", "Delete a comment (if replies quote it, its content is replaced with \"[deleted]\" instead)", authRequired, nil, nil, http.StatusNoContent, nil},
+
+	// Webhooks
+	{http.MethodPost, "/topics/:topicID/webhooks", "Register a webhook for new posts in a topic (deliveries are signed in X-Signature)", authRequired, nil, CreateWebhookRequest{}, http.StatusCreated, data.Webhook{}},
+	{http.MethodDelete, "/webhooks/:webhookID", "Remove a webhook", authRequired, nil, nil, http.StatusNoContent, nil},
+
+	// Votes
+	{http.MethodGet, "/posts/votes", "Get the current vote counts of several posts", authOptional,
+		[]queryParam{{"postIDs", "string", "Comma-separated post IDs (at most 200)"}},
+		nil, http.StatusOK, voteCountsResponse{}},
+	{http.MethodPost, "/posts/:postID/vote", "Vote on a post", authRequired, nil, VoteRequest{}, http.StatusOK, voteResponse{}},
+	{http.MethodDelete, "/posts/:postID/vote", "Remove a vote from a post", authRequired, nil, nil, http.StatusOK, voteResponse{}},
+	{http.MethodPost, "/comments/:commentID/vote", "Vote on a comment", authRequired, nil, VoteRequest{}, http.StatusOK, voteResponse{}},
+	{http.MethodDelete, "/comments/:commentID/vote", "Remove a vote from a comment", authRequired, nil, nil, http.StatusOK, voteResponse{}},
+
+	// User Profiles
+	{http.MethodGet, "/me", "Get the authenticated user's own profile, including their last login time", authRequired, nil, nil, http.StatusOK, data.User{}},
+	{http.MethodPatch, "/me/username", "Change the authenticated user's username (log in again afterwards, as existing tokens carry the old name)", authRequired, nil, ChangeUsernameRequest{}, http.StatusOK, data.User{}},
+	{http.MethodDelete, "/me/sessions", "Log out everywhere: revoke every token issued to the authenticated user so far, including this one (501 if the server does not check tokens against accounts)", authRequired, nil, nil, http.StatusNoContent, nil},
+	{http.MethodGet, "/users/:id", "Get a user's profile", authRequired, nil, nil, http.StatusOK, data.User{}},
+	{http.MethodGet, "/users/usernames", "Resolve up to 200 user IDs to usernames, as an object keyed by user ID (unknown IDs are left out)", authOptional,
+		[]queryParam{{"ids", "string", "Comma-separated user IDs"}},
+		nil, http.StatusOK, map[int]string{}},
+	{http.MethodGet, "/users/active", "List the users who posted or commented most in the last 24 hours (at most 20)", authOptional, nil, nil, http.StatusOK, []*data.ActiveUser{}},
+	{http.MethodGet, "/users/username/:username", "Get a user's public profile and karma by username", authOptional, nil, nil, http.StatusOK, data.UserProfile{}},
+	{http.MethodGet, "/users/:id/posts", "List a user's posts", authRequired, nil, nil, http.StatusOK, []*data.Post{}},
+	{http.MethodGet, "/users/:id/comments", "List a user's comments", authRequired, nil, nil, http.StatusOK, []*data.Comment{}},
+	{http.MethodGet, "/users/:id/karma", "Get a user's karma", authRequired, nil, nil, http.StatusOK, data.UserKarma{}},
+
+	// Admin
+	{http.MethodGet, "/admin/audit", "List audit log entries, most recent first", authAdmin, []queryParam{limitParam, offsetParam}, nil, http.StatusOK, data.PagedResponse[*data.AuditLogEntry]{}},
+	{http.MethodGet, "/admin/users", "List users with their post and comment counts, oldest first", authAdmin,
+		[]queryParam{{"q", "string", "Case-insensitive username prefix"}, {"limit", "integer", "Page size (at most 25)"}, offsetParam},
+		nil, http.StatusOK, data.PagedResponse[*data.UserSummary]{}},
+	{http.MethodPost, "/admin/users/:userID/ban", "Suspend a user from all authenticated routes, permanently unless a duration is given", authAdmin, nil, BanUserRequest{}, http.StatusOK, BanUserResponse{}},
+	{http.MethodPost, "/admin/users/:userID/unban", "Lift a user's suspension", authAdmin, nil, nil, http.StatusNoContent, nil},
+	{http.MethodPost, "/admin/comments/delete", "Delete up to 500 comments at once, returning how many were deleted", authAdmin, nil, DeleteCommentsRequest{}, http.StatusOK, DeleteCommentsResponse{}},
+	{http.MethodPost, "/admin/posts/bulk", "Import up to 1000 posts in one transaction; any invalid post rejects the batch", authAdmin, nil, CreatePostsBatchRequest{}, http.StatusCreated, CreatePostsBatchResponse{}},
+	{http.MethodPost, "/admin/votes/recount", "Recompute stored post and comment vote counts from the votes table, returning those corrected", authAdmin, nil, nil, http.StatusOK, data.VoteRecount{}},
+}
+
+var (
+	openAPISpecOnce sync.Once
+	openAPISpec     map[string]any
+)
+
+// OpenAPISpec returns the OpenAPI 3 document describing apiOperations
+func OpenAPISpec() map[string]any {
+	openAPISpecOnce.Do(func() {
+		openAPISpec = buildOpenAPISpec(apiOperations)
+	})
+	return openAPISpec
+}
+
+// GetOpenAPISpec handles GET requests for the OpenAPI document
+func GetOpenAPISpec(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, OpenAPISpec())
+}
+
+// buildOpenAPISpec generates the OpenAPI document for the given operations
+func buildOpenAPISpec(operations []apiOperation) map[string]any {
+	schemas := schemaGenerator{components: map[string]any{}}
+	errorSchema := schemas.schemaFor(reflect.TypeOf(errorResponse{}))
+
+	errorContent := func(description string) map[string]any {
+		return map[string]any{
+			"description": description,
+			"content":     map[string]any{"application/json": map[string]any{"schema": errorSchema}},
+		}
+	}
+
+	paths := map[string]any{}
+	for _, op := range operations {
+		path, params := openAPIPath(op.Path)
+
+		for _, param := range op.Query {
+			params = append(params, map[string]any{
+				"name":        param.Name,
+				"in":          "query",
+				"description": param.Description,
+				"schema":      map[string]any{"type": param.Type},
+			})
+		}
+
+		success := map[string]any{"description": http.StatusText(op.Status)}
+		if op.Response != nil {
+			success["content"] = map[string]any{
+				"application/json": map[string]any{"schema": schemas.schemaFor(reflect.TypeOf(op.Response))},
+			}
+		}
+
+		responses := map[string]any{
+			strconv.Itoa(op.Status): success,
+			"default":               errorContent("Error"),
+		}
+
+		operation := map[string]any{
+			"summary":   op.Summary,
+			"responses": responses,
+		}
+
+		if len(params) > 0 {
+			operation["parameters"] = params
+		}
+
+		if op.Request != nil {
+			operation["requestBody"] = map[string]any{
+				"required": true,
+				"content": map[string]any{
+					"application/json": map[string]any{"schema": schemas.schemaFor(reflect.TypeOf(op.Request))},
+				},
+			}
+		}
+
+		bearer := map[string]any{"bearerAuth": []string{}}
+		switch op.Auth {
+		case authOptional:
+			operation["security"] = []any{map[string]any{}, bearer}
+		case authRequired:
+			operation["security"] = []any{bearer}
+			responses["401"] = errorContent("Missing or invalid bearer token")
+		case authAdmin:
+			operation["security"] = []any{bearer}
+			responses["401"] = errorContent("Missing or invalid bearer token")
+			responses["403"] = errorContent("Admin role required")
+		}
+
+		pathItem, ok := paths[path].(map[string]any)
+		if !ok {
+			pathItem = map[string]any{}
+			paths[path] = pathItem
+		}
+		pathItem[strings.ToLower(op.Method)] = operation
+	}
+
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":   "Gossip with Go API",
+			"version": "1.0.0",
+		},
+		"servers": []any{map[string]any{"url": APIBasePath}},
+		"paths":   paths,
+		"components": map[string]any{
+			"schemas": schemas.components,
+			"securitySchemes": map[string]any{
+				"bearerAuth": map[string]any{
+					"type":         "http",
+					"scheme":       "bearer",
+					"bearerFormat": "JWT",
+				},
+			},
+		},
+	}
+}
+
+// openAPIPath converts a Gin path (":param") to an OpenAPI path ("{param}") and returns its path parameters
+func openAPIPath(ginPath string) (string, []any) {
+	segments := strings.Split(ginPath, "/")
+	params := []any{}
+
+	for i, segment := range segments {
+		name, ok := strings.CutPrefix(segment, ":")
+		if !ok {
+			continue
+		}
+
+		segments[i] = "{" + name + "}"
+		params = append(params, map[string]any{
+			"name":     name,
+			"in":       "path",
+			"required": true,
+			"schema":   map[string]any{"type": "integer"},
+		})
+	}
+
+	return strings.Join(segments, "/"), params
+}
+
+// schemaGenerator converts Go types to OpenAPI schemas, collecting named structs as components
+type schemaGenerator struct {
+	components map[string]any
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// schemaFor returns the schema for t, referencing a component schema for named structs
+func (g *schemaGenerator) schemaFor(t reflect.Type) map[string]any {
+	if t == timeType {
+		return map[string]any{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		schema := g.schemaFor(t.Elem())
+		if _, isRef := schema["$ref"]; !isRef {
+			schema["nullable"] = true
+		}
+		return schema
+
+	case reflect.Struct:
+		name := schemaName(t)
+		if _, exists := g.components[name]; !exists {
+			g.components[name] = map[string]any{} // Placeholder so recursive types terminate
+			g.components[name] = g.structSchema(t)
+		}
+		return map[string]any{"$ref": "#/components/schemas/" + name}
+
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": g.schemaFor(t.Elem())}
+
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": true}
+
+	case reflect.String:
+		return map[string]any{"type": "string"}
+
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+
+	default:
+		return map[string]any{}
+	}
+}
+
+// structSchema builds an object schema from a struct's json (and binding) tags
+func (g *schemaGenerator) structSchema(t reflect.Type) map[string]any {
+	properties := map[string]any{}
+	required := []string{}
+
+	g.collectFields(t, properties, &required)
+
+	schema := map[string]any{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+
+	return schema
+}
+
+// collectFields adds t's JSON-visible fields to properties, flattening embedded structs as encoding/json does
+func (g *schemaGenerator) collectFields(t reflect.Type, properties map[string]any, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, options, _ := strings.Cut(tag, ",")
+
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			g.collectFields(field.Type, properties, required)
+			continue
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		properties[name] = g.schemaFor(field.Type)
+
+		// Fields are always present unless omitempty or nullable, and request fields may be explicitly required
+		omitEmpty := strings.Contains(options, "omitempty")
+		if strings.Contains(field.Tag.Get("binding"), "required") ||
+			(!omitEmpty && field.Type.Kind() != reflect.Pointer && field.Type.Kind() != reflect.Map) {
+			*required = append(*required, name)
+		}
+	}
+}
+
+// schemaName derives a component name from a (possibly generic or unexported) struct type
+// e.g. data.PagedResponse[*data.Topic] becomes "PagedResponse_Topic"
+func schemaName(t reflect.Type) string {
+	name := t.Name()
+
+	if base, args, isGeneric := strings.Cut(name, "["); isGeneric {
+		parts := []string{base}
+		for _, arg := range strings.Split(strings.TrimSuffix(args, "]"), ",") {
+			arg = arg[strings.LastIndex(arg, ".")+1:]
+			parts = append(parts, strings.TrimLeft(arg, "*[]"))
+		}
+		name = strings.Join(parts, "_")
+	}
+
+	runes := []rune(name)
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
@@ -0,0 +1,56 @@
+package api
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Page size bounds for list endpoints
+const (
+	DefaultPageLimit = 20
+	MaxPageLimit     = 100 // Cap for endpoints without a more specific one below
+)
+
+// Per-endpoint page size caps, passed to parsePagination/parseLimit by each list handler
+const (
+	MaxTopicsPageLimit     = MaxPageLimit
+	MaxPostsPageLimit      = MaxPageLimit
+	MaxCommentsPageLimit   = MaxPageLimit
+	MaxAuditLogPageLimit   = MaxPageLimit
+	MaxUserSearchPageLimit = 25 // Each row aggregates the user's post and comment counts
+)
+
+// Pagination errors, whose messages are returned to clients with 400
+var (
+	errInvalidLimit  = errors.New("Invalid limit")
+	errInvalidOffset = errors.New("Invalid offset")
+)
+
+// parsePagination reads the 'limit' (default DefaultPageLimit, clamped to maxLimit) and 'offset' (default 0) query parameters
+// Returns errInvalidLimit or errInvalidOffset if either is non-numeric, the limit is not positive, or the offset is negative
+func parsePagination(ctx *gin.Context, maxLimit int) (limit, offset int, err error) {
+	limit, err = parseLimit(ctx, maxLimit)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	offset, err = strconv.Atoi(ctx.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		return 0, 0, errInvalidOffset
+	}
+
+	return limit, offset, nil
+}
+
+// parseLimit reads the 'limit' query parameter, for endpoints that return a single page
+// Limits above maxLimit are clamped rather than rejected; paged responses report the effective limit
+func parseLimit(ctx *gin.Context, maxLimit int) (int, error) {
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", strconv.Itoa(DefaultPageLimit)))
+	if err != nil || limit <= 0 {
+		return 0, errInvalidLimit
+	}
+
+	return min(limit, maxLimit), nil
+}
@@ -1,10 +1,15 @@
 package api
 
 import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
 	"strconv"
 	"strings"
 
+	"github.com/adzzfarr/gossip-with-go/backend/internal/data"
 	"github.com/adzzfarr/gossip-with-go/backend/internal/service"
 
 	"github.com/gin-gonic/gin"
@@ -12,15 +17,20 @@ import (
 
 // PostHandler handles HTTP requests related to posts
 type PostHandler struct {
-	PostService *service.PostService
+	PostService        *service.PostService
+	IdempotencyService *service.IdempotencyService
 }
 
 // NewPostHandler creates a new instance of PostHandler
-func NewPostHandler(postService *service.PostService) *PostHandler {
-	return &PostHandler{PostService: postService}
+func NewPostHandler(postService *service.PostService, idempotencyService *service.IdempotencyService) *PostHandler {
+	return &PostHandler{
+		PostService:        postService,
+		IdempotencyService: idempotencyService,
+	}
 }
 
 // GetPostsByTopicID handles GET requests for posts in a specific topic
+// Supports optional 'limit' (default 20, max 100) and 'offset' (default 0) query parameters
 func (handler *PostHandler) GetPostsByTopicID(ctx *gin.Context) {
 	// Get topicID from URL parameter
 	topicIDStr := ctx.Param("topicID")
@@ -33,6 +43,15 @@ func (handler *PostHandler) GetPostsByTopicID(ctx *gin.Context) {
 		return
 	}
 
+	// Parse pagination query parameters
+	limit, offset, err := parsePagination(ctx, MaxPostsPageLimit)
+	if err != nil {
+		ctx.JSON(
+			http.StatusBadRequest,
+			gin.H{"error": err.Error()})
+		return
+	}
+
 	// Get userID from context (nil if unauthenticated)
 	var userID *int
 	if uid, ok := ctx.Get("userID"); ok {
@@ -40,21 +59,44 @@ func (handler *PostHandler) GetPostsByTopicID(ctx *gin.Context) {
 		userID = &uidInt
 	}
 
+	sort := ctx.DefaultQuery("sort", data.PostSortNew)
+
 	// Call service layer
-	posts, err := handler.PostService.GetPostsByTopicID(topicID, userID)
+	posts, err := handler.PostService.GetPostsByTopicID(topicID, userID, sort, limit, offset)
 	if err != nil {
-		// Send ISE status to client
+		// Check for not found errors (Not Found 404)
+		if errors.Is(err, data.ErrTopicNotFound) {
+			ctx.JSON(
+				http.StatusNotFound,
+				gin.H{"error": "Topic not found"},
+			)
+			return
+		}
+
+		// Check for validation errors (Bad Request 400)
+		if strings.Contains(err.Error(), "invalid topic ID") ||
+			errors.Is(err, service.ErrValidation) {
+			ctx.JSON(
+				http.StatusBadRequest,
+				gin.H{"error": err.Error()},
+			)
+			return
+		}
+
+		// Otherwise, send ISE status to client
 		ctx.JSON(
 			http.StatusInternalServerError,
 			gin.H{"error": "Failed to fetch posts for the topic"})
 		return
 	}
 
-	// Gin serializes 'posts' slice into JSON
-	ctx.JSON(http.StatusOK, posts)
+	// Gin serializes 'posts' page into JSON (or XML if requested), keeping only the requested fields
+	respondPage(ctx, http.StatusOK, posts, "postID")
 }
 
 // GetPostByID handles GET requests for a specific post by its ID
+// Sets Last-Modified and answers 304 if If-Modified-Since shows the client already has the post
+// With includeTopic=true, the post's topic (ID, title and slug) is embedded as 'topic'
 func (handler *PostHandler) GetPostByID(ctx *gin.Context) {
 	// Get postID from URL parameter
 	postID, err := strconv.Atoi(ctx.Param("postID"))
@@ -66,13 +108,32 @@ func (handler *PostHandler) GetPostByID(ctx *gin.Context) {
 		return
 	}
 
+	// Parse includeTopic query parameter (default false)
+	includeTopic := false
+	if includeTopicStr, ok := ctx.GetQuery("includeTopic"); ok {
+		includeTopic, err = strconv.ParseBool(includeTopicStr)
+		if err != nil {
+			ctx.JSON(
+				http.StatusBadRequest,
+				gin.H{"error": "Invalid includeTopic"},
+			)
+			return
+		}
+	}
+
 	var userID *int
 	if uid, ok := ctx.Get("userID"); ok {
 		uidInt := uid.(int)
 		userID = &uidInt
 	}
 
-	post, err := handler.PostService.GetPostByID(postID, userID)
+	var post *data.Post
+	if includeTopic {
+		post, err = handler.PostService.GetPostWithTopic(postID, userID)
+	} else {
+		post, err = handler.PostService.GetPostByID(postID, userID)
+	}
+
 	if err != nil {
 		// Check for not found errors (Not Found 404)
 		if strings.Contains(err.Error(), "not found") {
@@ -91,17 +152,28 @@ func (handler *PostHandler) GetPostByID(ctx *gin.Context) {
 		return
 	}
 
-	// Gin serializes 'post' object into JSON
-	ctx.JSON(http.StatusOK, post)
+	// userVote and isOwner depend on the caller, so caches must keep separate copies per Authorization
+	ctx.Writer.Header().Add("Vary", "Authorization")
+
+	// Skip the body if the client's copy is current (ModifiedAt covers edits, pinning, locking and votes)
+	// Not with includeTopic, as the embedded topic can change without the post
+	if !includeTopic && checkNotModified(ctx, post.ModifiedAt) {
+		return
+	}
+
+	// Gin serializes 'post' object into JSON (or XML if requested)
+	respondNegotiated(ctx, http.StatusOK, post)
 }
 
 // CreatePostRequest defines expected JSON input for new posts
+// Title and content are checked by the service layer, so every failing field is reported at once
 type CreatePostRequest struct {
-	Title   string `json:"title" binding:"required"`
-	Content string `json:"content" binding:"required"`
+	Title   string `json:"title"`
+	Content string `json:"content"`
 }
 
 // CreatePost handles POST requests for creating new posts
+// Supports an optional Idempotency-Key header so that retried requests do not create duplicates
 func (handler *PostHandler) CreatePost(ctx *gin.Context) {
 	// Get authenticated user's ID from context (set by AuthMiddleware)
 	userID, exists := ctx.Get("userID")
@@ -135,6 +207,43 @@ func (handler *PostHandler) CreatePost(ctx *gin.Context) {
 		return
 	}
 
+	// Return the original post if this request has already been processed
+	idempotent, ok := claimIdempotencyKey(
+		ctx,
+		handler.IdempotencyService,
+		userID.(int),
+		"post",
+		gin.H{"topicID": topicID, "title": req.Title, "content": req.Content},
+	)
+	if !ok {
+		return
+	}
+
+	if idempotent.ResourceID != 0 {
+		uid := userID.(int)
+		post, err := handler.PostService.GetPostByID(idempotent.ResourceID, &uid)
+		if err != nil {
+			// The original post has since been deleted (Gone 410)
+			if errors.Is(err, data.ErrPostNotFound) {
+				ctx.JSON(
+					http.StatusGone,
+					gin.H{"error": "The post created with this idempotency key has been deleted"},
+				)
+				return
+			}
+
+			ctx.JSON(
+				http.StatusInternalServerError,
+				gin.H{"error": "Failed to fetch original post"},
+			)
+			return
+		}
+
+		ctx.Header("Location", postLocation(post.PostID))
+		ctx.JSON(http.StatusCreated, post)
+		return
+	}
+
 	// Call service layer to create post
 	post, err := handler.PostService.CreatePost(
 		topicID,
@@ -144,13 +253,19 @@ func (handler *PostHandler) CreatePost(ctx *gin.Context) {
 	)
 
 	if err != nil {
+		releaseIdempotencyKey(handler.IdempotencyService, userID.(int), idempotent)
+
 		errMsg := err.Error()
 
-		// Check for validation errors (Bad Request 400)
-		if strings.Contains(errMsg, "cannot be empty") ||
-			strings.Contains(errMsg, "exceeds maximum length") {
+		// Check for field validation errors (Bad Request 400, listing every failed field)
+		if respondValidationErrors(ctx, err) {
+			return
+		}
+
+		// Check for full topics (Conflict 409)
+		if errors.Is(err, service.ErrLimitReached) {
 			ctx.JSON(
-				http.StatusBadRequest,
+				http.StatusConflict,
 				gin.H{"error": errMsg},
 			)
 			return
@@ -174,7 +289,10 @@ func (handler *PostHandler) CreatePost(ctx *gin.Context) {
 		return
 	}
 
+	completeIdempotencyKey(handler.IdempotencyService, userID.(int), "post", idempotent, post.PostID)
+
 	// Gin serializes post object into JSON
+	ctx.Header("Location", postLocation(post.PostID))
 	ctx.JSON(http.StatusCreated, post)
 }
 
@@ -269,6 +387,99 @@ func (handler *PostHandler) UpdatePost(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, updatedPost)
 }
 
+// PatchPostRequest defines expected JSON input for partially updating posts
+// Omitted fields are left unchanged
+type PatchPostRequest struct {
+	Title   *string `json:"title"`
+	Content *string `json:"content"`
+}
+
+// PatchPost handles PATCH requests for partially updating existing posts
+func (handler *PostHandler) PatchPost(ctx *gin.Context) {
+	// Get authenticated user's ID from context (set by AuthMiddleware)
+	userID, exists := ctx.Get("userID")
+
+	if !exists {
+		ctx.JSON(
+			http.StatusUnauthorized,
+			gin.H{"error": "Unauthorized"},
+		)
+		return
+	}
+
+	// Get postID from URL parameter
+	postIDStr := ctx.Param("postID")
+	postID, err := strconv.Atoi(postIDStr)
+
+	if err != nil {
+		ctx.JSON(
+			http.StatusBadRequest,
+			gin.H{"error": "Invalid post ID"})
+		return
+	}
+
+	// Parse request body JSON into PatchPostRequest struct
+	var req PatchPostRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(
+			http.StatusBadRequest,
+			gin.H{"error": "Invalid input format"},
+		)
+		return
+	}
+
+	// Call service layer to update post
+	updatedPost, err := handler.PostService.PatchPost(
+		postID,
+		req.Title,
+		req.Content,
+		userID.(int),
+	)
+
+	if err != nil {
+		errMsg := err.Error()
+
+		// Check for not found errors (Not Found 404)
+		if strings.Contains(errMsg, "not found") {
+			ctx.JSON(
+				http.StatusNotFound,
+				gin.H{"error": "Post not found"},
+			)
+			return
+		}
+
+		// Check for authorization errors (Forbidden 403)
+		if strings.Contains(errMsg, "not authorized") {
+			ctx.JSON(
+				http.StatusForbidden,
+				gin.H{"error": errMsg},
+			)
+			return
+		}
+
+		// Check for validation errors (Bad Request 400)
+		if strings.Contains(errMsg, "cannot be empty") ||
+			strings.Contains(errMsg, "exceeds maximum length") ||
+			strings.Contains(errMsg, "no fields to update") {
+			ctx.JSON(
+				http.StatusBadRequest,
+				gin.H{"error": errMsg},
+			)
+			return
+		}
+
+		// Otherwise, send ISE status to client
+		ctx.JSON(
+			http.StatusInternalServerError,
+			gin.H{"error": "Failed to update post"},
+		)
+		return
+	}
+
+	// Return updated post
+	ctx.JSON(http.StatusOK, updatedPost)
+}
+
 // DeletePost handles DELETE requests for deleting existing posts
 func (handler *PostHandler) DeletePost(ctx *gin.Context) {
 	// Get authenticated user's ID from context (set by AuthMiddleware)
@@ -336,3 +547,304 @@ func (handler *PostHandler) DeletePost(ctx *gin.Context) {
 	// Return No Content status on successful deletion
 	ctx.Status(http.StatusNoContent)
 }
+
+// CreatePostsBatchRequest defines expected JSON input for importing many posts at once
+type CreatePostsBatchRequest struct {
+	Posts []data.PostInput `json:"posts" binding:"required"`
+}
+
+// CreatePostsBatchResponse lists the posts created by a bulk import, in request order
+type CreatePostsBatchResponse struct {
+	Created int          `json:"created"`
+	Posts   []*data.Post `json:"posts"`
+}
+
+// CreatePostsBatch handles POST requests to import up to service.MaxBulkCreatePosts posts in one transaction (admin only)
+// Any invalid post rejects the whole batch, with 'errors' naming each failing post by index
+func (handler *PostHandler) CreatePostsBatch(ctx *gin.Context) {
+	// Get authenticated admin's ID from context (set by AuthMiddleware)
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(
+			http.StatusUnauthorized,
+			gin.H{"error": "Unauthorized"},
+		)
+		return
+	}
+
+	// Parse request body JSON into CreatePostsBatchRequest struct
+	var req CreatePostsBatchRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(
+			http.StatusBadRequest,
+			gin.H{"error": "Invalid input format or missing fields"},
+		)
+		return
+	}
+
+	// Call service layer
+	posts, err := handler.PostService.CreatePostsBatch(req.Posts, userID.(int))
+	if err != nil {
+		// Check for per-post validation errors (Bad Request 400)
+		if respondValidationErrors(ctx, err) {
+			return
+		}
+
+		// Check for other validation errors (Bad Request 400)
+		if errors.Is(err, service.ErrValidation) {
+			ctx.JSON(
+				http.StatusBadRequest,
+				gin.H{"error": err.Error()},
+			)
+			return
+		}
+
+		// Check for posts referencing missing topics or authors (Bad Request 400)
+		if errors.Is(err, data.ErrTopicNotFound) || errors.Is(err, data.ErrUserNotFound) {
+			ctx.JSON(
+				http.StatusBadRequest,
+				gin.H{"error": err.Error()},
+			)
+			return
+		}
+
+		// Otherwise, send ISE status to client
+		ctx.JSON(
+			http.StatusInternalServerError,
+			gin.H{"error": "Failed to create posts"},
+		)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, CreatePostsBatchResponse{Created: len(posts), Posts: posts})
+}
+
+// PinPost handles POST requests to pin a post (admin only)
+func (handler *PostHandler) PinPost(ctx *gin.Context) {
+	handler.setPostPinned(ctx, true)
+}
+
+// UnpinPost handles DELETE requests to unpin a post (admin only)
+func (handler *PostHandler) UnpinPost(ctx *gin.Context) {
+	handler.setPostPinned(ctx, false)
+}
+
+// setPostPinned updates the pinned flag of the post in the URL and returns the updated post
+func (handler *PostHandler) setPostPinned(ctx *gin.Context, pinned bool) {
+	// Get postID from URL parameter
+	postIDStr := ctx.Param("postID")
+	postID, err := strconv.Atoi(postIDStr)
+	if err != nil {
+		ctx.JSON(
+			http.StatusBadRequest,
+			gin.H{"error": "Invalid post ID"},
+		)
+		return
+	}
+
+	// Call service layer
+	post, err := handler.PostService.SetPostPinned(postID, pinned)
+	if err != nil {
+		errMsg := err.Error()
+
+		// Check for not found errors (Not Found 404)
+		if strings.Contains(errMsg, "not found") {
+			ctx.JSON(
+				http.StatusNotFound,
+				gin.H{"error": errMsg},
+			)
+			return
+		}
+
+		// Check for validation errors (Bad Request 400)
+		if strings.Contains(errMsg, "invalid post ID") {
+			ctx.JSON(
+				http.StatusBadRequest,
+				gin.H{"error": errMsg},
+			)
+			return
+		}
+
+		// Otherwise, send ISE status to client
+		ctx.JSON(
+			http.StatusInternalServerError,
+			gin.H{"error": "Failed to update post"},
+		)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, post)
+}
+
+// TogglePostLock handles POST requests to lock or unlock a post against new comments
+// Only the post's creator or an admin may do so
+func (handler *PostHandler) TogglePostLock(ctx *gin.Context) {
+	// Get authenticated user's ID from context (set by AuthMiddleware)
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(
+			http.StatusUnauthorized,
+			gin.H{"error": "Unauthorized"},
+		)
+		return
+	}
+
+	// Get postID from URL parameter
+	postIDStr := ctx.Param("postID")
+	postID, err := strconv.Atoi(postIDStr)
+	if err != nil {
+		ctx.JSON(
+			http.StatusBadRequest,
+			gin.H{"error": "Invalid post ID"},
+		)
+		return
+	}
+
+	// Call service layer to toggle lock
+	post, err := handler.PostService.TogglePostLock(postID, userID.(int))
+	if err != nil {
+		errMsg := err.Error()
+
+		// Check for not found errors (Not Found 404)
+		if strings.Contains(errMsg, "not found") {
+			ctx.JSON(
+				http.StatusNotFound,
+				gin.H{"error": errMsg},
+			)
+			return
+		}
+
+		// Check for authorization errors (Forbidden 403)
+		if strings.Contains(errMsg, "not authorized") {
+			ctx.JSON(
+				http.StatusForbidden,
+				gin.H{"error": errMsg},
+			)
+			return
+		}
+
+		// Check for validation errors (Bad Request 400)
+		if strings.Contains(errMsg, "invalid user ID") ||
+			strings.Contains(errMsg, "invalid post ID") {
+			ctx.JSON(
+				http.StatusBadRequest,
+				gin.H{"error": errMsg},
+			)
+			return
+		}
+
+		// Otherwise, send ISE status to client
+		ctx.JSON(
+			http.StatusInternalServerError,
+			gin.H{"error": "Failed to toggle post lock"},
+		)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, post)
+}
+
+// postsCSVHeader is the header row of a topic's posts CSV export
+var postsCSVHeader = []string{"post_id", "title", "author_username", "vote_count", "created_at"}
+
+// postsCSVFlushRows is how many CSV rows are written between flushes to the client
+const postsCSVFlushRows = 100
+
+// csvSafeCell prefixes user-supplied text that spreadsheets would read as a formula with a single quote,
+// so opening an export cannot run e.g. =HYPERLINK(...) from a post title
+func csvSafeCell(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+
+	return value
+}
+
+// ExportTopicPostsCSV handles GET requests to download a topic's posts as CSV (admin only)
+// Rows are streamed to the client as they are read from the database
+func (handler *PostHandler) ExportTopicPostsCSV(ctx *gin.Context) {
+	// Get topicID from URL parameter
+	topicIDStr := ctx.Param("topicID")
+	topicID, err := strconv.Atoi(topicIDStr)
+	if err != nil {
+		ctx.JSON(
+			http.StatusBadRequest,
+			gin.H{"error": "Invalid topic ID"},
+		)
+		return
+	}
+
+	csvWriter := csv.NewWriter(ctx.Writer)
+	rows := 0
+
+	// Headers are only sent once the topic is known to exist, so errors can still get a JSON response
+	startCSV := func() {
+		ctx.Header("Content-Type", "text/csv; charset=utf-8")
+		ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="topic-%d-posts.csv"`, topicID))
+		ctx.Status(http.StatusOK)
+		csvWriter.Write(postsCSVHeader)
+	}
+
+	// Call service layer, writing each post as it arrives
+	err = handler.PostService.ForEachPostInTopic(topicID, func(post *data.Post) error {
+		if rows == 0 {
+			startCSV()
+		}
+
+		csvWriter.Write([]string{
+			strconv.Itoa(post.PostID),
+			csvSafeCell(post.Title),
+			csvSafeCell(post.Username),
+			strconv.Itoa(post.VoteCount),
+			post.CreatedAt.UTC().Format(data.TimestampFormat),
+		})
+
+		rows++
+		if rows%postsCSVFlushRows == 0 {
+			csvWriter.Flush()
+			ctx.Writer.Flush()
+		}
+
+		return csvWriter.Error()
+	})
+
+	if err != nil {
+		// Part of the CSV has already been sent, so the status can no longer change
+		if rows > 0 {
+			log.Printf("Failed to export posts for topic %d after %d rows: %v", topicID, rows, err)
+			return
+		}
+
+		// Check for not found errors (Not Found 404)
+		if errors.Is(err, data.ErrTopicNotFound) {
+			ctx.JSON(
+				http.StatusNotFound,
+				gin.H{"error": "Topic not found"},
+			)
+			return
+		}
+
+		// Check for validation errors (Bad Request 400)
+		if strings.Contains(err.Error(), "invalid topic ID") {
+			ctx.JSON(
+				http.StatusBadRequest,
+				gin.H{"error": err.Error()},
+			)
+			return
+		}
+
+		// Otherwise, send ISE status to client
+		ctx.JSON(
+			http.StatusInternalServerError,
+			gin.H{"error": "Failed to export posts"},
+		)
+		return
+	}
+
+	// Topics without posts still get a header row
+	if rows == 0 {
+		startCSV()
+	}
+
+	csvWriter.Flush()
+}
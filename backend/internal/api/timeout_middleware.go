@@ -0,0 +1,254 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultRequestTimeout is the default per-request deadline
+const DefaultRequestTimeout = 30 * time.Second
+
+// LongLivedRoutes are the routes that stream for as long as they need (comment streams and topic exports),
+// to be excluded from TimeoutMiddleware
+var LongLivedRoutes = []string{
+	APIBasePath + "/posts/:postID/comments/stream",
+	APIBasePath + "/topics?stream=true",
+	APIBasePath + "/topics/:topicID/posts.csv",
+}
+
+// TimeoutMiddleware gives each request a deadline of d
+// If the handlers have not finished by then, the client gets 503 "request timed out" and later writes are discarded.
+// Handlers should pass ctx.Request.Context() down so that in-flight work is cancelled as well.
+// Streaming handlers that call Flush are sent as they go, so a timeout can only cut them short.
+// Routes in excludedRoutes (full route patterns, e.g. "/api/v1/posts/:postID/comments/stream") have no deadline.
+// A route may carry a query (e.g. "/api/v1/topics?stream=true") to exclude only requests with those parameters
+func TimeoutMiddleware(d time.Duration, excludedRoutes ...string) gin.HandlerFunc {
+	excluded := make(map[string][]url.Values, len(excludedRoutes))
+	for _, route := range excludedRoutes {
+		path, rawQuery, _ := strings.Cut(route, "?")
+		query, _ := url.ParseQuery(rawQuery)
+		excluded[path] = append(excluded[path], query)
+	}
+
+	return func(ctx *gin.Context) {
+		if isExcludedFromTimeout(ctx, excluded) {
+			ctx.Next()
+			return
+		}
+
+		timeoutCtx, cancel := context.WithTimeout(ctx.Request.Context(), d)
+		defer cancel()
+
+		ctx.Request = ctx.Request.WithContext(timeoutCtx)
+
+		// Buffer the response so that it can be replaced by a 503 if the deadline passes first
+		original := ctx.Writer
+		writer := &timeoutWriter{
+			ResponseWriter: original,
+			header:         original.Header().Clone(),
+			status:         http.StatusOK,
+		}
+		ctx.Writer = writer
+
+		done := make(chan struct{})
+		var panicValue any
+
+		go func() {
+			defer close(done)
+			defer func() { panicValue = recover() }()
+
+			ctx.Next()
+		}()
+
+		select {
+		case <-done:
+			// Re-raise handler panics on this goroutine so recovery middleware can handle them
+			if panicValue != nil {
+				ctx.Writer = original
+				panic(panicValue)
+			}
+
+			writer.mu.Lock()
+			defer writer.mu.Unlock()
+
+			ctx.Writer = original
+			if !writer.streaming {
+				writer.flushTo(original)
+			}
+
+		case <-timeoutCtx.Done():
+			writer.mu.Lock()
+			writer.timedOut = true
+			streaming := writer.streaming
+			writer.mu.Unlock()
+
+			// A streamed response has already been sent in part, so it can only be cut short
+			if streaming {
+				<-done
+				ctx.Writer = original
+				ctx.Abort()
+				return
+			}
+
+			body, _ := json.Marshal(gin.H{"error": "request timed out"})
+			original.Header().Set("Content-Type", "application/json; charset=utf-8")
+			original.WriteHeader(http.StatusServiceUnavailable)
+			original.Write(body)
+			original.Flush()
+
+			// The gin.Context must not be recycled while the handlers still hold it,
+			// so wait for them to return (their writes are now discarded)
+			<-done
+
+			ctx.Writer = original
+			ctx.Abort()
+		}
+	}
+}
+
+// isExcludedFromTimeout reports whether the request matches one of the excluded routes and its query parameters
+func isExcludedFromTimeout(ctx *gin.Context, excluded map[string][]url.Values) bool {
+	for _, query := range excluded[ctx.FullPath()] {
+		matches := true
+		for key, values := range query {
+			if ctx.Query(key) != values[0] {
+				matches = false
+				break
+			}
+		}
+
+		if matches {
+			return true
+		}
+	}
+
+	return false
+}
+
+// timeoutWriter holds the status, headers and body written by handlers until the request completes
+type timeoutWriter struct {
+	gin.ResponseWriter
+	mu        sync.Mutex
+	header    http.Header
+	status    int
+	body      bytes.Buffer
+	written   bool // Whether a status has been written
+	timedOut  bool // Whether the 503 response has already been sent
+	streaming bool // Whether the response has been flushed, so writes go straight to the client
+}
+
+func (w *timeoutWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *timeoutWriter) WriteHeader(code int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.timedOut || w.written || w.streaming {
+		return
+	}
+
+	w.status = code
+	w.written = true
+}
+
+// WriteHeaderNow is a no-op: the status is sent once the handlers return
+func (w *timeoutWriter) WriteHeaderNow() {}
+
+func (w *timeoutWriter) Write(data []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+
+	if w.streaming {
+		return w.ResponseWriter.Write(data)
+	}
+
+	w.written = true
+	return w.body.Write(data)
+}
+
+func (w *timeoutWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *timeoutWriter) Status() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.status
+}
+
+func (w *timeoutWriter) Size() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.streaming {
+		return w.ResponseWriter.Size()
+	}
+	if !w.written {
+		return -1
+	}
+	return w.body.Len()
+}
+
+func (w *timeoutWriter) Written() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.written
+}
+
+// Flush switches the response to streaming: the buffered status, headers and body are sent,
+// and later writes go straight to the client
+func (w *timeoutWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.timedOut {
+		return
+	}
+
+	if !w.streaming {
+		w.written = true
+		w.flushTo(w.ResponseWriter)
+		w.body.Reset()
+		w.streaming = true
+	}
+
+	w.ResponseWriter.Flush()
+}
+
+// flushTo copies the buffered response to the underlying writer (caller holds mu)
+func (w *timeoutWriter) flushTo(dst gin.ResponseWriter) {
+	dstHeader := dst.Header()
+	for key := range dstHeader {
+		if _, ok := w.header[key]; !ok {
+			dstHeader.Del(key)
+		}
+	}
+	for key, values := range w.header {
+		dstHeader[key] = values
+	}
+
+	if !w.written {
+		return
+	}
+
+	dst.WriteHeader(w.status)
+	if w.body.Len() > 0 {
+		dst.Write(w.body.Bytes())
+	}
+}
@@ -1,10 +1,14 @@
 package api
 
 import (
+	"encoding/json"
+	"errors"
+	"log"
 	"net/http"
 	"strconv"
 	"strings"
 
+	"github.com/adzzfarr/gossip-with-go/backend/internal/data"
 	"github.com/adzzfarr/gossip-with-go/backend/internal/service"
 	"github.com/gin-gonic/gin"
 )
@@ -20,9 +24,84 @@ func NewTopicHandler(topicService *service.TopicService) *TopicHandler {
 }
 
 // GetAllTopics handles GET requests for topics
+// Supports optional 'limit' (default 20, max 100) and 'offset' (default 0) query parameters
+// If an 'ids' query parameter is given, returns only those topics instead (see getTopicsByIDs)
+// With 'stream=true', returns every topic as a streamed JSON array instead (see streamTopics)
+// If a 'createdBy' user ID is given, only that user's topics are listed
+// With includeLatestPost=true, each topic's most recent post is embedded as 'latestPost'
+// exactCount=false reports the planner's estimate as the total (cheap, but may lag recent writes), exactCount=true
+// always counts; by default the count is exact unless the table is large
+// A user's topics are always counted exactly and listed without latest posts, so createdBy refuses includeLatestPost=true
+// and exactCount=false (400); exactCount=true is what it does anyway, so it is accepted
 func (handler *TopicHandler) GetAllTopics(ctx *gin.Context) {
-	// Call service layer
-	topics, err := handler.TopicService.GetAllTopics()
+	if idsStr, ok := ctx.GetQuery("ids"); ok {
+		handler.getTopicsByIDs(ctx, idsStr)
+		return
+	}
+
+	if ctx.Query("stream") == "true" {
+		handler.streamTopics(ctx)
+		return
+	}
+
+	// Parse pagination query parameters
+	limit, offset, err := parsePagination(ctx, MaxTopicsPageLimit)
+	if err != nil {
+		ctx.JSON(
+			http.StatusBadRequest,
+			gin.H{"error": err.Error()})
+		return
+	}
+
+	// Parse includeLatestPost query parameter (default false)
+	includeLatestPost := false
+	if includeLatestPostStr, ok := ctx.GetQuery("includeLatestPost"); ok {
+		includeLatestPost, err = strconv.ParseBool(includeLatestPostStr)
+		if err != nil {
+			ctx.JSON(
+				http.StatusBadRequest,
+				gin.H{"error": "Invalid includeLatestPost"})
+			return
+		}
+	}
+
+	// Parse exactCount query parameter (default: exact unless the topics table is large)
+	var exactCount *bool
+	if exactCountStr, ok := ctx.GetQuery("exactCount"); ok {
+		exact, parseErr := strconv.ParseBool(exactCountStr)
+		if parseErr != nil {
+			ctx.JSON(
+				http.StatusBadRequest,
+				gin.H{"error": "Invalid exactCount"})
+			return
+		}
+		exactCount = &exact
+	}
+
+	// Call service layer, filtering by creator if requested
+	var topics *data.PagedResponse[*data.Topic]
+	if createdByStr, ok := ctx.GetQuery("createdBy"); ok {
+		createdBy, convErr := strconv.Atoi(createdByStr)
+		if convErr != nil || createdBy <= 0 {
+			ctx.JSON(
+				http.StatusBadRequest,
+				gin.H{"error": "Invalid createdBy"})
+			return
+		}
+
+		if includeLatestPost || (exactCount != nil && !*exactCount) {
+			ctx.JSON(
+				http.StatusBadRequest,
+				gin.H{"error": "createdBy cannot be combined with includeLatestPost or exactCount=false"})
+			return
+		}
+
+		topics, err = handler.TopicService.GetTopicsByUser(createdBy, limit, offset)
+	} else if includeLatestPost {
+		topics, err = handler.TopicService.GetAllTopicsWithLatestPost(limit, offset, exactCount)
+	} else {
+		topics, err = handler.TopicService.GetAllTopics(limit, offset, exactCount)
+	}
 
 	if err != nil {
 		// Send ISE status to client
@@ -32,11 +111,129 @@ func (handler *TopicHandler) GetAllTopics(ctx *gin.Context) {
 		return
 	}
 
-	// Gin serializes 'topics' slice into JSON
-	ctx.JSON(http.StatusOK, topics)
+	// Gin serializes 'topics' page into JSON (or XML if requested), keeping only the requested fields
+	respondPage(ctx, http.StatusOK, topics, "topicID")
+}
+
+// topicsStreamFlushItems is how many streamed topics are written between flushes to the client
+const topicsStreamFlushItems = 100
+
+// streamTopics handles GET requests for every topic (?stream=true) as a plain JSON array, written topic by topic
+// (the route is exempt from the request timeout, so long exports are not cut short)
+// as rows are read so memory stays flat however many topics exist; pagination and field parameters are ignored
+func (handler *TopicHandler) streamTopics(ctx *gin.Context) {
+	encoder := json.NewEncoder(ctx.Writer)
+	items := 0
+
+	// Headers are only sent once the first row arrives, so errors can still get a JSON response
+	startArray := func() {
+		ctx.Header("Content-Type", "application/json; charset=utf-8")
+		ctx.Status(http.StatusOK)
+		ctx.Writer.WriteString("[")
+	}
+
+	// Call service layer, writing each topic as it arrives
+	err := handler.TopicService.ForEachTopic(func(topic *data.Topic) error {
+		if items == 0 {
+			startArray()
+		} else {
+			ctx.Writer.WriteString(",")
+		}
+
+		items++
+		if err := encoder.Encode(topic); err != nil {
+			return err
+		}
+
+		if items%topicsStreamFlushItems == 0 {
+			ctx.Writer.Flush()
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		// Part of the array has already been sent, so the status can no longer change (the array is left unclosed)
+		if items > 0 {
+			log.Printf("Failed to stream topics after %d items: %v", items, err)
+			return
+		}
+
+		ctx.JSON(
+			http.StatusInternalServerError,
+			gin.H{"error": "Failed to fetch topics"})
+		return
+	}
+
+	// No topics still makes a valid (empty) array
+	if items == 0 {
+		startArray()
+	}
+
+	ctx.Writer.WriteString("]")
+}
+
+// getTopicsByIDs handles GET requests for a batch of topics given as comma-separated IDs (e.g. ?ids=1,2,3)
+// Returns a plain array of topics in the requested order, skipping IDs that do not exist
+func (handler *TopicHandler) getTopicsByIDs(ctx *gin.Context, idsStr string) {
+	// Parse comma-separated topic IDs
+	var topicIDs []int
+	for _, idStr := range strings.Split(idsStr, ",") {
+		topicID, err := strconv.Atoi(strings.TrimSpace(idStr))
+		if err != nil || topicID <= 0 {
+			ctx.JSON(
+				http.StatusBadRequest,
+				gin.H{"error": "Invalid topic IDs"})
+			return
+		}
+
+		topicIDs = append(topicIDs, topicID)
+	}
+
+	// Call service layer
+	topics, err := handler.TopicService.GetTopicsByIDs(topicIDs)
+
+	if err != nil {
+		errMsg := err.Error()
+
+		// Check for validation errors (Bad Request 400)
+		if strings.Contains(errMsg, "too many topic IDs") ||
+			strings.Contains(errMsg, "invalid topic ID") {
+			ctx.JSON(
+				http.StatusBadRequest,
+				gin.H{"error": errMsg},
+			)
+			return
+		}
+
+		// Otherwise, send ISE status to client
+		ctx.JSON(
+			http.StatusInternalServerError,
+			gin.H{"error": "Failed to fetch topics"},
+		)
+		return
+	}
+
+	respondNegotiatedList(ctx, http.StatusOK, "topics", topics)
+}
+
+// GetTrendingTopics handles GET requests for the topics with the most recent posts and comments
+func (handler *TopicHandler) GetTrendingTopics(ctx *gin.Context) {
+	// Call service layer
+	topics, err := handler.TopicService.GetTrendingTopics()
+	if err != nil {
+		ctx.JSON(
+			http.StatusInternalServerError,
+			gin.H{"error": "Failed to fetch trending topics"},
+		)
+		return
+	}
+
+	respondNegotiatedList(ctx, http.StatusOK, "topics", topics)
 }
 
 // GetTopicByID handles GET requests for a specific topic by its ID
+// Sets Last-Modified and answers 304 if If-Modified-Since shows the client already has the topic
 func (handler *TopicHandler) GetTopicByID(ctx *gin.Context) {
 	// Get topicID from URL parameter
 	topicIDStr := ctx.Param("topicID")
@@ -81,13 +278,120 @@ func (handler *TopicHandler) GetTopicByID(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(http.StatusOK, topic)
+	// Skip the body if the client's copy is current
+	if checkNotModified(ctx, topic.UpdatedAt) {
+		return
+	}
+
+	respondNegotiated(ctx, http.StatusOK, topic)
+}
+
+// GetTopicBySlug handles GET requests for a specific topic by its slug
+func (handler *TopicHandler) GetTopicBySlug(ctx *gin.Context) {
+	// Call service layer
+	topic, err := handler.TopicService.GetTopicBySlug(ctx.Param("slug"))
+
+	if err != nil {
+		errMsg := err.Error()
+
+		// Check for not found errors (Not Found 404)
+		if strings.Contains(errMsg, "not found") {
+			ctx.JSON(
+				http.StatusNotFound,
+				gin.H{"error": errMsg},
+			)
+			return
+		}
+
+		// Check for validation errors (Bad Request 400)
+		if strings.Contains(errMsg, "invalid slug") {
+			ctx.JSON(
+				http.StatusBadRequest,
+				gin.H{"error": errMsg},
+			)
+			return
+		}
+
+		// Otherwise, send ISE status to client
+		ctx.JSON(
+			http.StatusInternalServerError,
+			gin.H{"error": "Failed to fetch topic"},
+		)
+		return
+	}
+
+	respondNegotiated(ctx, http.StatusOK, topic)
+}
+
+// GetTopicWithPosts handles GET requests for a topic together with its first page of posts
+// Supports optional 'limit' (default 20, max 100) query parameter
+func (handler *TopicHandler) GetTopicWithPosts(ctx *gin.Context) {
+	// Get topicID from URL parameter
+	topicIDStr := ctx.Param("topicID")
+	topicID, err := strconv.Atoi(topicIDStr)
+
+	if err != nil {
+		ctx.JSON(
+			http.StatusBadRequest,
+			gin.H{"error": "Invalid topic ID"})
+		return
+	}
+
+	// Parse page size query parameter
+	limit, err := parseLimit(ctx, MaxPostsPageLimit)
+	if err != nil {
+		ctx.JSON(
+			http.StatusBadRequest,
+			gin.H{"error": err.Error()})
+		return
+	}
+
+	// Get userID from context (nil if unauthenticated)
+	var userID *int
+	if uid, ok := ctx.Get("userID"); ok {
+		uidInt := uid.(int)
+		userID = &uidInt
+	}
+
+	// Call service layer
+	topicWithPosts, err := handler.TopicService.GetTopicWithPosts(topicID, userID, limit)
+
+	if err != nil {
+		// Check for not found errors (Not Found 404)
+		if errors.Is(err, data.ErrTopicNotFound) {
+			ctx.JSON(
+				http.StatusNotFound,
+				gin.H{"error": "Topic not found"},
+			)
+			return
+		}
+
+		// Check for validation errors (Bad Request 400)
+		if strings.Contains(err.Error(), "invalid topic ID") {
+			ctx.JSON(
+				http.StatusBadRequest,
+				gin.H{"error": err.Error()},
+			)
+			return
+		}
+
+		// Otherwise, send ISE status to client
+		ctx.JSON(
+			http.StatusInternalServerError,
+			gin.H{"error": "Failed to fetch topic"},
+		)
+		return
+	}
+
+	respondNegotiated(ctx, http.StatusOK, topicWithPosts)
 }
 
 // CreateTopicRequest defines expected JSON input for new topics
+// Title and description are checked by the service layer, so every failing field is reported at once
 type CreateTopicRequest struct {
-	Title       string `json:"title" binding:"required"`
-	Description string `json:"description" binding:"required"`
+	Title          string `json:"title"`
+	Description    string `json:"description"`
+	AllowDuplicate bool   `json:"allowDuplicate,omitempty"` // Create even if another topic has the same title (ignoring case)
 }
 
 // CreateTopic handles POST requests for creating new topics
@@ -117,9 +421,33 @@ func (handler *TopicHandler) CreateTopic(ctx *gin.Context) {
 		req.Title,
 		req.Description,
 		userID.(int),
+		req.AllowDuplicate,
 	)
 
 	if err != nil {
+		// Check for field validation errors (Bad Request 400, listing every failed field)
+		if respondValidationErrors(ctx, err) {
+			return
+		}
+
+		// Check for duplicate titles (Conflict 409)
+		if errors.Is(err, service.ErrDuplicateTopicTitle) {
+			ctx.JSON(
+				http.StatusConflict,
+				gin.H{"error": err.Error()},
+			)
+			return
+		}
+
+		// Check for the daily topic cap (Too Many Requests 429)
+		if errors.Is(err, service.ErrDailyTopicLimit) {
+			ctx.JSON(
+				http.StatusTooManyRequests,
+				gin.H{"error": err.Error()},
+			)
+			return
+		}
+
 		ctx.JSON(
 			http.StatusBadRequest,
 			gin.H{"error": err.Error()},
@@ -128,6 +456,7 @@ func (handler *TopicHandler) CreateTopic(ctx *gin.Context) {
 	}
 
 	// Return created topic
+	ctx.Header("Location", topicLocation(topic.TopicID))
 	ctx.JSON(http.StatusCreated, topic)
 }
 
@@ -222,6 +551,188 @@ func (handler *TopicHandler) UpdateTopic(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, updatedTopic)
 }
 
+// PatchTopicRequest defines expected JSON input for partially updating topics
+// Omitted fields are left unchanged
+type PatchTopicRequest struct {
+	Title       *string `json:"title"`
+	Description *string `json:"description"`
+}
+
+// PatchTopic handles PATCH requests for partially updating existing topics
+func (handler *TopicHandler) PatchTopic(ctx *gin.Context) {
+	// Get authenticated user's ID from context (set by AuthMiddleware)
+	userID, exists := ctx.Get("userID")
+
+	if !exists {
+		ctx.JSON(
+			http.StatusUnauthorized,
+			gin.H{"error": "Unauthorized"},
+		)
+		return
+	}
+
+	// Get topicID from URL parameter
+	topicIDStr := ctx.Param("topicID")
+	topicID, err := strconv.Atoi(topicIDStr)
+
+	if err != nil {
+		ctx.JSON(
+			http.StatusBadRequest,
+			gin.H{"error": "Invalid topic ID"})
+		return
+	}
+
+	// Parse request body JSON into PatchTopicRequest struct
+	var req PatchTopicRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(
+			http.StatusBadRequest,
+			gin.H{"error": "Invalid input format"},
+		)
+		return
+	}
+
+	// Call service layer to update topic
+	updatedTopic, err := handler.TopicService.PatchTopic(
+		topicID,
+		req.Title,
+		req.Description,
+		userID.(int),
+	)
+
+	if err != nil {
+		errMsg := err.Error()
+
+		// Check for not found errors (Not Found 404)
+		if strings.Contains(errMsg, "not found") {
+			ctx.JSON(
+				http.StatusNotFound,
+				gin.H{"error": errMsg},
+			)
+			return
+		}
+
+		// Check for authorization errors (Forbidden 403)
+		if strings.Contains(errMsg, "not authorized") {
+			ctx.JSON(
+				http.StatusForbidden,
+				gin.H{"error": errMsg},
+			)
+			return
+		}
+
+		// Check for validation errors (Bad Request 400)
+		if strings.Contains(errMsg, "cannot be empty") ||
+			strings.Contains(errMsg, "exceeds maximum length") ||
+			strings.Contains(errMsg, "no fields to update") {
+			ctx.JSON(
+				http.StatusBadRequest,
+				gin.H{"error": errMsg},
+			)
+			return
+		}
+
+		// Otherwise, send ISE status to client
+		ctx.JSON(
+			http.StatusInternalServerError,
+			gin.H{"error": "Failed to update topic"},
+		)
+		return
+	}
+
+	// Return updated topic
+	ctx.JSON(http.StatusOK, updatedTopic)
+}
+
+// UpdateTopicDescriptionRequest defines expected JSON input for updating a topic's description
+type UpdateTopicDescriptionRequest struct {
+	Description string `json:"description" binding:"required"`
+}
+
+// UpdateTopicDescription handles PATCH requests for updating only a topic's description
+func (handler *TopicHandler) UpdateTopicDescription(ctx *gin.Context) {
+	// Get authenticated user's ID from context (set by AuthMiddleware)
+	userID, exists := ctx.Get("userID")
+
+	if !exists {
+		ctx.JSON(
+			http.StatusUnauthorized,
+			gin.H{"error": "Unauthorized"},
+		)
+		return
+	}
+
+	// Get topicID from URL parameter
+	topicIDStr := ctx.Param("topicID")
+	topicID, err := strconv.Atoi(topicIDStr)
+
+	if err != nil {
+		ctx.JSON(
+			http.StatusBadRequest,
+			gin.H{"error": "Invalid topic ID"})
+		return
+	}
+
+	// Parse request body JSON into UpdateTopicDescriptionRequest struct
+	var req UpdateTopicDescriptionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(
+			http.StatusBadRequest,
+			gin.H{"error": "Invalid input format or missing fields"},
+		)
+		return
+	}
+
+	// Call service layer to update the description
+	updatedTopic, err := handler.TopicService.UpdateTopicDescription(
+		topicID,
+		req.Description,
+		userID.(int),
+	)
+
+	if err != nil {
+		errMsg := err.Error()
+
+		// Check for not found errors (Not Found 404)
+		if strings.Contains(errMsg, "not found") {
+			ctx.JSON(
+				http.StatusNotFound,
+				gin.H{"error": errMsg},
+			)
+			return
+		}
+
+		// Check for authorization errors (Forbidden 403)
+		if strings.Contains(errMsg, "not authorized") {
+			ctx.JSON(
+				http.StatusForbidden,
+				gin.H{"error": errMsg},
+			)
+			return
+		}
+
+		// Check for validation errors (Bad Request 400)
+		if strings.Contains(errMsg, "cannot be empty") ||
+			strings.Contains(errMsg, "exceeds maximum length") {
+			ctx.JSON(
+				http.StatusBadRequest,
+				gin.H{"error": errMsg},
+			)
+			return
+		}
+
+		// Otherwise, send ISE status to client
+		ctx.JSON(
+			http.StatusInternalServerError,
+			gin.H{"error": "Failed to update topic description"},
+		)
+		return
+	}
+
+	// Return updated topic
+	ctx.JSON(http.StatusOK, updatedTopic)
+}
+
 // DeleteTopic handles DELETE requests for deleting existing topics
 func (handler *TopicHandler) DeleteTopic(ctx *gin.Context) {
 	// Get authenticated user's ID from context (set by AuthMiddleware)
@@ -289,3 +800,60 @@ func (handler *TopicHandler) DeleteTopic(ctx *gin.Context) {
 	// Return No Content status on successful deletion
 	ctx.Status(http.StatusNoContent)
 }
+
+// PinTopic handles POST requests to pin a topic (admin only)
+func (handler *TopicHandler) PinTopic(ctx *gin.Context) {
+	handler.setTopicPinned(ctx, true)
+}
+
+// UnpinTopic handles DELETE requests to unpin a topic (admin only)
+func (handler *TopicHandler) UnpinTopic(ctx *gin.Context) {
+	handler.setTopicPinned(ctx, false)
+}
+
+// setTopicPinned updates the pinned flag of the topic in the URL and returns the updated topic
+func (handler *TopicHandler) setTopicPinned(ctx *gin.Context, pinned bool) {
+	// Get topicID from URL parameter
+	topicIDStr := ctx.Param("topicID")
+	topicID, err := strconv.Atoi(topicIDStr)
+	if err != nil {
+		ctx.JSON(
+			http.StatusBadRequest,
+			gin.H{"error": "Invalid topic ID"},
+		)
+		return
+	}
+
+	// Call service layer
+	topic, err := handler.TopicService.SetTopicPinned(topicID, pinned)
+	if err != nil {
+		errMsg := err.Error()
+
+		// Check for not found errors (Not Found 404)
+		if strings.Contains(errMsg, "not found") {
+			ctx.JSON(
+				http.StatusNotFound,
+				gin.H{"error": errMsg},
+			)
+			return
+		}
+
+		// Check for validation errors (Bad Request 400)
+		if strings.Contains(errMsg, "invalid topic ID") {
+			ctx.JSON(
+				http.StatusBadRequest,
+				gin.H{"error": errMsg},
+			)
+			return
+		}
+
+		// Otherwise, send ISE status to client
+		ctx.JSON(
+			http.StatusInternalServerError,
+			gin.H{"error": "Failed to update topic"},
+		)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, topic)
+}
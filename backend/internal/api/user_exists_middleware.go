@@ -0,0 +1,55 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/adzzfarr/gossip-with-go/backend/internal/data"
+	"github.com/adzzfarr/gossip-with-go/backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UserExistsMiddleware rejects requests whose token belongs to a user that no longer exists, or was issued
+// before the user revoked their sessions, with 401
+// Must run after AuthMiddleware, which sets the userID and tokenVersion in context; tokens are otherwise trusted until they expire
+func UserExistsMiddleware(userService *service.UserService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		// Get authenticated user's ID from context (set by AuthMiddleware)
+		userID, exists := ctx.Get("userID")
+
+		if !exists {
+			ctx.JSON(
+				http.StatusUnauthorized,
+				gin.H{"error": "Unauthorized"},
+			)
+			ctx.Abort()
+			return
+		}
+
+		tokenVersion, err := userService.CurrentTokenVersion(userID.(int))
+
+		if err != nil {
+			if errors.Is(err, data.ErrUserNotFound) {
+				abortUnauthorized(ctx, AuthCodeInvalid, "account no longer exists")
+				return
+			}
+
+			ctx.JSON(
+				http.StatusInternalServerError,
+				gin.H{"error": "Failed to verify account status"},
+			)
+			ctx.Abort()
+			return
+		}
+
+		// Tokens issued before the user's last RevokeSessions carry an older version
+		if ctx.GetInt("tokenVersion") < tokenVersion {
+			abortUnauthorized(ctx, AuthCodeRevoked, "Token has been revoked")
+			return
+		}
+
+		// Proceed to next handler
+		ctx.Next()
+	}
+}
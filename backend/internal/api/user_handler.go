@@ -1,9 +1,12 @@
 package api
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 
+	"github.com/adzzfarr/gossip-with-go/backend/internal/data"
 	"github.com/adzzfarr/gossip-with-go/backend/internal/service"
 	"github.com/gin-gonic/gin"
 )
@@ -12,11 +15,30 @@ import (
 type UserRegistrationRequest struct {
 	Username string `json:"username" binding:"required"`
 	Password string `json:"password" binding:"required"`
+	Email    string `json:"email"` // Optional; lets the user log in with it instead of their username
+}
+
+// ChangeUsernameRequest defines expected JSON input for renaming the authenticated user
+type ChangeUsernameRequest struct {
+	Username string `json:"username" binding:"required"`
+}
+
+// ForgotPasswordRequest defines expected JSON input for requesting a password reset
+type ForgotPasswordRequest struct {
+	Username string `json:"username" binding:"required"` // Username or email
+}
+
+// ResetPasswordRequest defines expected JSON input for setting a new password with a reset token
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required"`
 }
 
 // UserHandler holds UserService instance to perform business logic
 type UserHandler struct {
-	UserService *service.UserService
+	UserService        *service.UserService
+	RegistrationClosed bool // Refuse new signups (403); login and all other endpoints are unaffected
+	SessionsUnchecked  bool // Tokens are not checked against the database (AUTH_USER_CHECK=off), so revoking sessions is refused (501)
 }
 
 // NewUserHandler creates a new instance of UserHandler
@@ -25,7 +47,16 @@ func NewUserHandler(userService *service.UserService) *UserHandler {
 }
 
 // RegisterUser handles POST requests for user registration
+// Answers 403 while registration is closed (see RegistrationClosed)
 func (handler *UserHandler) RegisterUser(ctx *gin.Context) {
+	// Check whether signups are open (Forbidden 403)
+	if handler.RegistrationClosed {
+		ctx.JSON(
+			http.StatusForbidden,
+			gin.H{"error": "registration is currently closed"})
+		return
+	}
+
 	// Parse request body JSON into UserRegistrationRequest struct format
 	var req UserRegistrationRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
@@ -36,14 +67,40 @@ func (handler *UserHandler) RegisterUser(ctx *gin.Context) {
 	}
 
 	// Call Service Layer
-	user, err := handler.UserService.RegisterUser(req.Username, req.Password)
+	user, err := handler.UserService.RegisterUser(req.Username, req.Password, req.Email)
 
 	if err != nil {
-		// Since service layer handles input validation (password length, complexity)
-		// and unique username checks, errors here are likely client-related (Bad Request 400)
+		// Check for taken usernames (Conflict 409)
+		if errors.Is(err, data.ErrUsernameTaken) {
+			ctx.JSON(
+				http.StatusConflict,
+				gin.H{"error": "username is already taken"},
+			)
+			return
+		}
+
+		// Check for taken emails (Conflict 409)
+		if errors.Is(err, data.ErrEmailTaken) {
+			ctx.JSON(
+				http.StatusConflict,
+				gin.H{"error": "email is already taken"},
+			)
+			return
+		}
+
+		// Check for validation errors, e.g. password length or complexity (Bad Request 400)
+		if errors.Is(err, service.ErrValidation) {
+			ctx.JSON(
+				http.StatusBadRequest,
+				gin.H{"error": err.Error()},
+			)
+			return
+		}
+
+		// Otherwise, send ISE status to client without exposing database details
 		ctx.JSON(
-			http.StatusBadRequest,
-			gin.H{"error": err.Error()},
+			http.StatusInternalServerError,
+			gin.H{"error": "Failed to register user"},
 		)
 		return
 	}
@@ -58,6 +115,148 @@ func (handler *UserHandler) RegisterUser(ctx *gin.Context) {
 	)
 }
 
+// GetCurrentUser handles GET requests for the authenticated user's own profile
+func (handler *UserHandler) GetCurrentUser(ctx *gin.Context) {
+	// Get authenticated user's ID from context (set by AuthMiddleware)
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(
+			http.StatusUnauthorized,
+			gin.H{"error": "Unauthorized"},
+		)
+		return
+	}
+
+	// Call Service Layer
+	user, err := handler.UserService.GetCurrentUser(userID.(int))
+	if err != nil {
+		// The token may outlive the account (Not Found 404)
+		if strings.Contains(err.Error(), "not found") {
+			ctx.JSON(
+				http.StatusNotFound,
+				gin.H{"error": "User not found"},
+			)
+			return
+		}
+
+		ctx.JSON(
+			http.StatusInternalServerError,
+			gin.H{"error": "Failed to fetch user"},
+		)
+		return
+	}
+
+	// Serialize user object (excluding PasswordHash) into JSON
+	ctx.JSON(http.StatusOK, user)
+}
+
+// RevokeSessions handles DELETE requests to log the authenticated user out everywhere
+// Every token issued so far, including the one used for this request, is refused afterwards
+func (handler *UserHandler) RevokeSessions(ctx *gin.Context) {
+	// Revoked tokens would still be accepted, so refuse rather than report a logout that did not happen
+	if handler.SessionsUnchecked {
+		ctx.JSON(
+			http.StatusNotImplemented,
+			gin.H{"error": "session revocation is not available on this server"},
+		)
+		return
+	}
+
+	// Get authenticated user's ID from context (set by AuthMiddleware)
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(
+			http.StatusUnauthorized,
+			gin.H{"error": "Unauthorized"},
+		)
+		return
+	}
+
+	// Call Service Layer
+	if err := handler.UserService.RevokeSessions(userID.(int)); err != nil {
+		if errors.Is(err, data.ErrUserNotFound) {
+			ctx.JSON(
+				http.StatusNotFound,
+				gin.H{"error": "User not found"},
+			)
+			return
+		}
+
+		ctx.JSON(
+			http.StatusInternalServerError,
+			gin.H{"error": "Failed to revoke sessions"},
+		)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// ChangeUsername handles PATCH requests to rename the authenticated user
+// Existing tokens still carry the old username claim, so clients should log in again to refresh it
+func (handler *UserHandler) ChangeUsername(ctx *gin.Context) {
+	// Get authenticated user's ID from context (set by AuthMiddleware)
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(
+			http.StatusUnauthorized,
+			gin.H{"error": "Unauthorized"},
+		)
+		return
+	}
+
+	// Parse request body JSON into ChangeUsernameRequest struct
+	var req ChangeUsernameRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(
+			http.StatusBadRequest,
+			gin.H{"error": "Invalid input format or missing fields"},
+		)
+		return
+	}
+
+	// Call Service Layer
+	user, err := handler.UserService.ChangeUsername(userID.(int), req.Username)
+	if err != nil {
+		// Check for taken usernames (Conflict 409)
+		if errors.Is(err, data.ErrUsernameTaken) {
+			ctx.JSON(
+				http.StatusConflict,
+				gin.H{"error": "username is already taken"},
+			)
+			return
+		}
+
+		// Check for validation errors (Bad Request 400)
+		if errors.Is(err, service.ErrValidation) {
+			ctx.JSON(
+				http.StatusBadRequest,
+				gin.H{"error": err.Error()},
+			)
+			return
+		}
+
+		// Check for not found errors (Not Found 404)
+		if strings.Contains(err.Error(), "not found") {
+			ctx.JSON(
+				http.StatusNotFound,
+				gin.H{"error": "User not found"},
+			)
+			return
+		}
+
+		// Otherwise, send ISE status to client
+		ctx.JSON(
+			http.StatusInternalServerError,
+			gin.H{"error": "Failed to change username"},
+		)
+		return
+	}
+
+	// Serialize user object (excluding PasswordHash) into JSON
+	ctx.JSON(http.StatusOK, user)
+}
+
 // GetUserByID handles GET requests to fetch user profile by userID
 func (handler *UserHandler) GetUserByID(ctx *gin.Context) {
 	// Extract userID from URL parameters
@@ -74,7 +273,7 @@ func (handler *UserHandler) GetUserByID(ctx *gin.Context) {
 	// Call Service Layer
 	user, err := handler.UserService.GetUserByID(userID)
 	if err != nil {
-		if err.Error() == "user not found" {
+		if errors.Is(err, data.ErrUserNotFound) {
 			ctx.JSON(
 				http.StatusNotFound,
 				gin.H{"error": "User not found"},
@@ -93,6 +292,40 @@ func (handler *UserHandler) GetUserByID(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, user)
 }
 
+// GetUserByUsername handles GET requests to fetch a user's public profile by username
+func (handler *UserHandler) GetUserByUsername(ctx *gin.Context) {
+	// Call Service Layer
+	profile, err := handler.UserService.GetUserProfileByUsername(ctx.Param("username"))
+	if err != nil {
+		// Check for not found errors (Not Found 404)
+		if errors.Is(err, data.ErrUserNotFound) {
+			ctx.JSON(
+				http.StatusNotFound,
+				gin.H{"error": "User not found"},
+			)
+			return
+		}
+
+		// Check for validation errors (Bad Request 400)
+		if errors.Is(err, service.ErrValidation) {
+			ctx.JSON(
+				http.StatusBadRequest,
+				gin.H{"error": err.Error()},
+			)
+			return
+		}
+
+		// Otherwise, send ISE status to client
+		ctx.JSON(
+			http.StatusInternalServerError,
+			gin.H{"error": "Failed to fetch user"},
+		)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, profile)
+}
+
 // GetUserPosts handles GET requests to fetch all posts by a specific user
 func (handler *UserHandler) GetUserPosts(ctx *gin.Context) {
 	// Extract userID from URL parameters
@@ -144,3 +377,184 @@ func (handler *UserHandler) GetUserComments(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, comments)
 }
+
+// GetUserKarma handles GET requests to fetch a user's aggregate vote score
+func (handler *UserHandler) GetUserKarma(ctx *gin.Context) {
+	// Extract userID from URL parameters
+	userIDStr := ctx.Param("id")
+	userID, err := strconv.Atoi(userIDStr)
+	if err != nil {
+		ctx.JSON(
+			http.StatusBadRequest,
+			gin.H{"error": "Invalid user ID"},
+		)
+		return
+	}
+
+	// Call Service Layer
+	karma, err := handler.UserService.GetUserKarma(userID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			ctx.JSON(
+				http.StatusNotFound,
+				gin.H{"error": "User not found"},
+			)
+			return
+		}
+
+		ctx.JSON(
+			http.StatusInternalServerError,
+			gin.H{"error": "Failed to fetch user karma"},
+		)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, karma)
+}
+
+// GetActiveUsers handles GET requests for the users who posted or commented most in the last 24 hours
+func (handler *UserHandler) GetActiveUsers(ctx *gin.Context) {
+	// Call Service Layer
+	users, err := handler.UserService.GetActiveUsers()
+	if err != nil {
+		ctx.JSON(
+			http.StatusInternalServerError,
+			gin.H{"error": "Failed to fetch active users"},
+		)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, users)
+}
+
+// GetUsernamesByIDs handles GET requests resolving several user IDs to usernames (e.g. ?ids=1,2,3)
+// Responds with an object mapping each found user ID to its username; unknown IDs are left out
+func (handler *UserHandler) GetUsernamesByIDs(ctx *gin.Context) {
+	idsStr, ok := ctx.GetQuery("ids")
+	if !ok {
+		ctx.JSON(
+			http.StatusBadRequest,
+			gin.H{"error": "Missing user IDs"},
+		)
+		return
+	}
+
+	// Parse comma-separated user IDs
+	var userIDs []int
+	for _, idStr := range strings.Split(idsStr, ",") {
+		userID, err := strconv.Atoi(strings.TrimSpace(idStr))
+		if err != nil || userID <= 0 {
+			ctx.JSON(
+				http.StatusBadRequest,
+				gin.H{"error": "Invalid user IDs"},
+			)
+			return
+		}
+
+		userIDs = append(userIDs, userID)
+	}
+
+	// Call Service Layer
+	usernames, err := handler.UserService.GetUsernamesByIDs(userIDs)
+	if err != nil {
+		errMsg := err.Error()
+
+		// Check for validation errors (Bad Request 400)
+		if strings.Contains(errMsg, "too many user IDs") ||
+			strings.Contains(errMsg, "invalid user ID") {
+			ctx.JSON(
+				http.StatusBadRequest,
+				gin.H{"error": errMsg},
+			)
+			return
+		}
+
+		// Otherwise, send ISE status to client
+		ctx.JSON(
+			http.StatusInternalServerError,
+			gin.H{"error": "Failed to fetch usernames"},
+		)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, usernames)
+}
+
+// ForgotPassword handles POST requests to send a password reset token to a user
+// Always responds 200 for well-formed requests, so it cannot be used to discover which accounts exist
+func (handler *UserHandler) ForgotPassword(ctx *gin.Context) {
+	// Parse request body JSON into ForgotPasswordRequest struct
+	var req ForgotPasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(
+			http.StatusBadRequest,
+			gin.H{"error": "Invalid input format or missing fields"},
+		)
+		return
+	}
+
+	// Call Service Layer
+	if err := handler.UserService.RequestPasswordReset(req.Username); err != nil {
+		ctx.JSON(
+			http.StatusInternalServerError,
+			gin.H{"error": "Failed to request password reset"},
+		)
+		return
+	}
+
+	ctx.JSON(
+		http.StatusOK,
+		gin.H{"message": "If the account exists, a password reset has been sent"},
+	)
+}
+
+// ResetPassword handles POST requests to set a new password using a reset token
+func (handler *UserHandler) ResetPassword(ctx *gin.Context) {
+	// Parse request body JSON into ResetPasswordRequest struct
+	var req ResetPasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(
+			http.StatusBadRequest,
+			gin.H{"error": "Invalid input format or missing fields"},
+		)
+		return
+	}
+
+	// Call Service Layer
+	if err := handler.UserService.ResetPassword(req.Token, req.Password); err != nil {
+		// Check for unknown, expired or used tokens (Bad Request 400)
+		if errors.Is(err, data.ErrInvalidResetToken) {
+			ctx.JSON(
+				http.StatusBadRequest,
+				gin.H{"error": "Invalid or expired reset token"},
+			)
+			return
+		}
+
+		// Check for validation errors (Bad Request 400)
+		if errors.Is(err, service.ErrValidation) {
+			ctx.JSON(
+				http.StatusBadRequest,
+				gin.H{"error": err.Error()},
+			)
+			return
+		}
+
+		// Otherwise, send ISE status to client
+		ctx.JSON(
+			http.StatusInternalServerError,
+			gin.H{"error": "Failed to reset password"},
+		)
+		return
+	}
+
+	ctx.JSON(
+		http.StatusOK,
+		gin.H{"message": "Password reset successfully"},
+	)
+}
+
+// GetPasswordPolicy handles GET requests for the password rules enforced on registration
+func (handler *UserHandler) GetPasswordPolicy(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, handler.UserService.PasswordPolicy)
+}
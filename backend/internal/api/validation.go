@@ -0,0 +1,24 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/adzzfarr/gossip-with-go/backend/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// respondValidationErrors writes 400 listing every failed field as 'errors' if err is a service.ValidationErrors
+// 'error' carries the joined messages, as in other error responses; returns false (writing nothing) for other errors
+func respondValidationErrors(ctx *gin.Context, err error) bool {
+	var validationErrs service.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return false
+	}
+
+	ctx.JSON(
+		http.StatusBadRequest,
+		gin.H{"error": err.Error(), "errors": validationErrs},
+	)
+	return true
+}
@@ -0,0 +1,31 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BuildInfo identifies the running build
+type BuildInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildTime string `json:"buildTime"`
+}
+
+// versionResponse is the body returned by the version endpoint
+type versionResponse struct {
+	BuildInfo
+	UptimeSeconds int64 `json:"uptimeSeconds"`
+}
+
+// VersionHandler returns a handler reporting the build and how long the server has been running since startTime
+func VersionHandler(build BuildInfo, startTime time.Time) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.JSON(http.StatusOK, versionResponse{
+			BuildInfo:     build,
+			UptimeSeconds: int64(time.Since(startTime).Seconds()),
+		})
+	}
+}
@@ -1,8 +1,10 @@
 package api
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/adzzfarr/gossip-with-go/backend/internal/service"
 	"github.com/gin-gonic/gin"
@@ -26,6 +28,46 @@ type VoteRequest struct {
 	VoteType int `json:"voteType" binding:"required"` // 1 for upvote, -1 for downvote
 }
 
+// GetVoteCountsForPosts handles GET requests for the current vote counts of several posts (e.g. ?postIDs=1,2,3)
+// Responds with the counts keyed by post ID; posts without votes have a count of 0
+func (handler *VoteHandler) GetVoteCountsForPosts(ctx *gin.Context) {
+	// Parse comma-separated post IDs
+	var postIDs []int
+	for _, idStr := range strings.Split(ctx.Query("postIDs"), ",") {
+		postID, err := strconv.Atoi(strings.TrimSpace(idStr))
+		if err != nil || postID <= 0 {
+			ctx.JSON(
+				http.StatusBadRequest,
+				gin.H{"error": "Invalid post IDs"})
+			return
+		}
+
+		postIDs = append(postIDs, postID)
+	}
+
+	// Call service layer
+	counts, err := handler.VoteService.GetVoteCountsForPosts(postIDs)
+	if err != nil {
+		// Check for validation errors (Bad Request 400)
+		if errors.Is(err, service.ErrValidation) {
+			ctx.JSON(
+				http.StatusBadRequest,
+				gin.H{"error": err.Error()},
+			)
+			return
+		}
+
+		// Otherwise, send ISE status to client
+		ctx.JSON(
+			http.StatusInternalServerError,
+			gin.H{"error": "Failed to fetch vote counts"},
+		)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"voteCounts": counts})
+}
+
 // VoteOnPost handles POST requests for voting on a post
 func (handler *VoteHandler) VoteOnPost(ctx *gin.Context) {
 	// Get postID from URL parameter
@@ -169,6 +211,15 @@ func (handler *VoteHandler) VoteOnComment(ctx *gin.Context) {
 
 	err = handler.VoteService.VoteOnComment(userID, commentID, req.VoteType)
 	if err != nil {
+		// Check for missing or deleted comments (Not Found 404)
+		if strings.Contains(err.Error(), "not found") {
+			ctx.JSON(
+				http.StatusNotFound,
+				gin.H{"error": "Comment not found"},
+			)
+			return
+		}
+
 		ctx.JSON(
 			http.StatusInternalServerError,
 			gin.H{"error": err.Error()},
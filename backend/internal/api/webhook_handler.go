@@ -0,0 +1,161 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/adzzfarr/gossip-with-go/backend/internal/data"
+	"github.com/adzzfarr/gossip-with-go/backend/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// WebhookHandler holds instance of WebhookService to perform business logic
+type WebhookHandler struct {
+	WebhookService *service.WebhookService
+}
+
+// NewWebhookHandler creates a new instance of WebhookHandler
+func NewWebhookHandler(webhookService *service.WebhookService) *WebhookHandler {
+	return &WebhookHandler{WebhookService: webhookService}
+}
+
+// CreateWebhookRequest defines expected JSON input for registering a webhook
+type CreateWebhookRequest struct {
+	URL    string `json:"url" binding:"required"`
+	Secret string `json:"secret" binding:"required"` // Used to sign deliveries; never returned
+}
+
+// CreateWebhook handles POST requests to register a webhook for new posts in a topic
+func (handler *WebhookHandler) CreateWebhook(ctx *gin.Context) {
+	// Get authenticated user's ID from context (set by AuthMiddleware)
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(
+			http.StatusUnauthorized,
+			gin.H{"error": "Unauthorized"},
+		)
+		return
+	}
+
+	// Get topicID from URL parameter
+	topicID, err := strconv.Atoi(ctx.Param("topicID"))
+	if err != nil {
+		ctx.JSON(
+			http.StatusBadRequest,
+			gin.H{"error": "Invalid topic ID"},
+		)
+		return
+	}
+
+	// Parse request body JSON into CreateWebhookRequest struct
+	var req CreateWebhookRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(
+			http.StatusBadRequest,
+			gin.H{"error": "Invalid input format or missing fields"},
+		)
+		return
+	}
+
+	// Call service layer to register webhook
+	webhook, err := handler.WebhookService.CreateWebhook(topicID, userID.(int), req.URL, req.Secret)
+	if err != nil {
+		// Check for not found errors (Not Found 404)
+		if errors.Is(err, data.ErrTopicNotFound) {
+			ctx.JSON(
+				http.StatusNotFound,
+				gin.H{"error": "Topic not found"},
+			)
+			return
+		}
+
+		// Check for validation errors (Bad Request 400)
+		if errors.Is(err, service.ErrValidation) ||
+			strings.Contains(err.Error(), "invalid topic ID") ||
+			strings.Contains(err.Error(), "invalid user ID") {
+			ctx.JSON(
+				http.StatusBadRequest,
+				gin.H{"error": err.Error()},
+			)
+			return
+		}
+
+		// Otherwise, send ISE status to client
+		ctx.JSON(
+			http.StatusInternalServerError,
+			gin.H{"error": "Failed to create webhook"},
+		)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, webhook)
+}
+
+// DeleteWebhook handles DELETE requests to remove a webhook registered by the user
+func (handler *WebhookHandler) DeleteWebhook(ctx *gin.Context) {
+	// Get authenticated user's ID from context (set by AuthMiddleware)
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(
+			http.StatusUnauthorized,
+			gin.H{"error": "Unauthorized"},
+		)
+		return
+	}
+
+	// Get webhookID from URL parameter
+	webhookID, err := strconv.Atoi(ctx.Param("webhookID"))
+	if err != nil {
+		ctx.JSON(
+			http.StatusBadRequest,
+			gin.H{"error": "Invalid webhook ID"},
+		)
+		return
+	}
+
+	// Call service layer to delete webhook
+	err = handler.WebhookService.DeleteWebhook(webhookID, userID.(int))
+	if err != nil {
+		errMsg := err.Error()
+
+		// Check for not found errors (Not Found 404)
+		if strings.Contains(errMsg, "not found") {
+			ctx.JSON(
+				http.StatusNotFound,
+				gin.H{"error": errMsg},
+			)
+			return
+		}
+
+		// Check for authorization errors (Forbidden 403)
+		if strings.Contains(errMsg, "not authorized") {
+			ctx.JSON(
+				http.StatusForbidden,
+				gin.H{"error": errMsg},
+			)
+			return
+		}
+
+		// Check for validation errors (Bad Request 400)
+		if strings.Contains(errMsg, "invalid user ID") ||
+			strings.Contains(errMsg, "invalid webhook ID") {
+			ctx.JSON(
+				http.StatusBadRequest,
+				gin.H{"error": errMsg},
+			)
+			return
+		}
+
+		// Otherwise, send ISE status to client
+		ctx.JSON(
+			http.StatusInternalServerError,
+			gin.H{"error": "Failed to delete webhook"},
+		)
+		return
+	}
+
+	// Return No Content status on successful deletion
+	ctx.Status(http.StatusNoContent)
+}
@@ -0,0 +1,92 @@
+package data
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Audit log actions
+const (
+	AuditActionDelete = "delete"
+	AuditActionBan    = "ban"
+	AuditActionUnban  = "unban"
+
+	AuditActionRecountVotes = "recount_votes"
+)
+
+// insertAuditLog records an action within the caller's transaction,
+// so the entry is only kept if the action itself commits
+func insertAuditLog(
+	ctx context.Context,
+	tx pgx.Tx,
+	actorUserID int,
+	action, targetType string,
+	targetID int,
+	metadata map[string]any,
+) error {
+	query := `
+		INSERT INTO audit_log (actor_user_id, action, target_type, target_id, metadata)
+		VALUES ($1, $2, $3, $4, $5)`
+
+	_, err := tx.Exec(ctx, query, actorUserID, action, targetType, targetID, metadata)
+	if err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+
+	return nil
+}
+
+// GetAuditLog fetches a page of audit log entries, most recent first
+func (repo *Repository) GetAuditLog(limit, offset int) (*PagedResponse[*AuditLogEntry], error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Count all entries for pagination metadata
+	var total int
+	err := repo.DB.QueryRow(ctx, `SELECT COUNT(*) FROM audit_log`).Scan(&total)
+	if err != nil {
+		return nil, fmt.Errorf("count audit log failed: %w", err)
+	}
+
+	// Fetch one extra row to determine whether another page exists
+	query := `
+		SELECT id, actor_user_id, action, target_type, target_id, created_at, metadata
+		FROM audit_log
+		ORDER BY created_at DESC, id DESC
+		LIMIT $1 OFFSET $2`
+
+	rows, err := repo.DB.Query(ctx, query, limit+1, offset)
+	if err != nil {
+		return nil, fmt.Errorf("query audit log failed: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []*AuditLogEntry{}
+	for rows.Next() {
+		var entry AuditLogEntry
+
+		err := rows.Scan(
+			&entry.AuditID,
+			&entry.ActorUserID,
+			&entry.Action,
+			&entry.TargetType,
+			&entry.TargetID,
+			&entry.CreatedAt,
+			&entry.Metadata,
+		)
+
+		if err != nil {
+			return nil, fmt.Errorf("error scanning audit log row: %w", err)
+		}
+
+		entries = append(entries, &entry)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error encountered during row iteration: %w", err)
+	}
+
+	return newPagedResponse(entries, total, limit, offset), nil
+}
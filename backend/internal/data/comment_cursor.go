@@ -0,0 +1,40 @@
+package data
+
+import (
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EncodeCommentCursor builds the opaque cursor pointing just after the given comment
+// Its (created_at, comment_id) position stays valid however many comments are added later
+func EncodeCommentCursor(createdAt time.Time, commentID int) string {
+	raw := createdAt.UTC().Format(time.RFC3339Nano) + "|" + strconv.Itoa(commentID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeCommentCursor returns the keyset position encoded by EncodeCommentCursor
+func DecodeCommentCursor(cursor string) (time.Time, int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, 0, ErrInvalidCursor
+	}
+
+	createdAtStr, commentIDStr, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return time.Time{}, 0, ErrInvalidCursor
+	}
+
+	createdAt, err := time.Parse(time.RFC3339Nano, createdAtStr)
+	if err != nil {
+		return time.Time{}, 0, ErrInvalidCursor
+	}
+
+	commentID, err := strconv.Atoi(commentIDStr)
+	if err != nil || commentID <= 0 {
+		return time.Time{}, 0, ErrInvalidCursor
+	}
+
+	return createdAt, commentID, nil
+}
@@ -0,0 +1,16 @@
+package data
+
+import "errors"
+
+// Sentinel errors returned (wrapped) by the repository layer
+// Callers should match them with errors.Is rather than comparing error strings
+var (
+	ErrTopicNotFound = errors.New("topic not found")
+	ErrPostNotFound  = errors.New("post not found")
+	ErrUserNotFound  = errors.New("user not found")
+	ErrUsernameTaken = errors.New("username is already taken")
+	ErrEmailTaken    = errors.New("email is already taken")
+	ErrInvalidCursor = errors.New("invalid cursor")
+
+	ErrInvalidResetToken = errors.New("invalid or expired reset token")
+)
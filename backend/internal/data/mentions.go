@@ -0,0 +1,36 @@
+package data
+
+import (
+	"context"
+	"fmt"
+)
+
+// NotificationTypeMention is the notification type for being mentioned in a comment
+const NotificationTypeMention = "mention"
+
+// CreateMentions records the users mentioned in a comment and notifies each of them
+// Mentions already recorded for the comment are skipped, so repeating the call notifies nobody twice
+// Returns the number of notifications created
+func (repo *Repository) CreateMentions(commentID, actorID int, userIDs []int) (int, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// A single statement, so mentions are never recorded without their notifications
+	query := `
+		WITH mentioned AS (
+			INSERT INTO comment_mentions (comment_id, user_id)
+			SELECT $1, unnest($2::int[])
+			ON CONFLICT DO NOTHING
+			RETURNING user_id
+		)
+		INSERT INTO notifications (user_id, type, actor_id, comment_id)
+		SELECT user_id, $3, $4, $1
+		FROM mentioned`
+
+	result, err := repo.DB.Exec(ctx, query, commentID, userIDs, NotificationTypeMention, actorID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create mentions: %w", err)
+	}
+
+	return int(result.RowsAffected()), nil
+}
@@ -1,56 +1,265 @@
 package data
 
-import "time"
+import (
+	"encoding/json"
+	"encoding/xml"
+	"time"
+)
 
-// json and db tags are used for serialisation and database mapping respectively
+// json/xml and db tags are used for serialisation and database mapping respectively
+
+// TimestampFormat is the JSON format for all timestamps: RFC3339 with millisecond precision, always UTC
+const TimestampFormat = "2006-01-02T15:04:05.000Z07:00"
+
+// formatTimestamp formats a timestamp for JSON output using TimestampFormat
+func formatTimestamp(t time.Time) string {
+	return t.UTC().Format(TimestampFormat)
+}
 
 // User struct
 type User struct {
-	UserID       int       `json:"userID" db:"user_id"` // Primary key
-	Username     string    `json:"username" db:"username"`
-	PasswordHash string    `json:"-" db:"password_hash"` // Exclude from JSON output for security
-	CreatedAt    time.Time `json:"createdAt" db:"created_at"`
-	UpdatedAt    time.Time `json:"updatedAt" db:"updated_at"`
+	UserID       int        `json:"userID" db:"user_id"` // Primary key
+	Username     string     `json:"username" db:"username"`
+	PasswordHash string     `json:"-" db:"password_hash"`       // Exclude from JSON output for security
+	Email        *string    `json:"email,omitempty" db:"email"` // Only set on the user's own profile (nil if none was given)
+	CreatedAt    time.Time  `json:"createdAt" db:"created_at"`
+	UpdatedAt    time.Time  `json:"updatedAt" db:"updated_at"`
+	LastLoginAt  *time.Time `json:"lastLoginAt,omitempty" db:"last_login_at"` // Only set on the user's own profile (nil if never logged in)
+	TokenVersion int        `json:"-" db:"token_version"`                     // Only set for login; tokens issued with an older version are refused
+}
+
+// MarshalJSON serializes User with timestamps in TimestampFormat
+func (u User) MarshalJSON() ([]byte, error) {
+	type alias User // Alias has no methods, avoiding infinite recursion
+
+	var lastLoginAt *string
+	if u.LastLoginAt != nil {
+		formatted := formatTimestamp(*u.LastLoginAt)
+		lastLoginAt = &formatted
+	}
+
+	return json.Marshal(struct {
+		alias
+		CreatedAt   string  `json:"createdAt"`
+		UpdatedAt   string  `json:"updatedAt"`
+		LastLoginAt *string `json:"lastLoginAt,omitempty"`
+	}{
+		alias:       alias(u),
+		CreatedAt:   formatTimestamp(u.CreatedAt),
+		UpdatedAt:   formatTimestamp(u.UpdatedAt),
+		LastLoginAt: lastLoginAt,
+	})
+}
+
+// UserSummary struct
+// A user with their activity counts, as listed to admins
+type UserSummary struct {
+	UserID       int        `json:"userID" db:"user_id"`
+	Username     string     `json:"username" db:"username"`
+	IsAdmin      bool       `json:"isAdmin" db:"is_admin"`
+	CreatedAt    time.Time  `json:"createdAt" db:"created_at"`
+	LastLoginAt  *time.Time `json:"lastLoginAt,omitempty" db:"last_login_at"` // Nil if the user has not logged in since it was tracked
+	PostCount    int        `json:"postCount" db:"post_count"`
+	CommentCount int        `json:"commentCount" db:"comment_count"`
+}
+
+// MarshalJSON serializes UserSummary with timestamps in TimestampFormat
+func (u UserSummary) MarshalJSON() ([]byte, error) {
+	type alias UserSummary // Alias has no methods, avoiding infinite recursion
+
+	var lastLoginAt *string
+	if u.LastLoginAt != nil {
+		formatted := formatTimestamp(*u.LastLoginAt)
+		lastLoginAt = &formatted
+	}
+
+	return json.Marshal(struct {
+		alias
+		CreatedAt   string  `json:"createdAt"`
+		LastLoginAt *string `json:"lastLoginAt,omitempty"`
+	}{
+		alias:       alias(u),
+		CreatedAt:   formatTimestamp(u.CreatedAt),
+		LastLoginAt: lastLoginAt,
+	})
 }
 
 // Topic struct
 type Topic struct {
-	TopicID     int       `json:"topicID" db:"topic_id"` // Primary key
-	Title       string    `json:"title" db:"title"`
-	Description string    `json:"description" db:"description"`
-	CreatedBy   int       `json:"createdBy" db:"created_by"`
-	Username    string    `json:"username" db:"username"`
-	CreatedAt   time.Time `json:"createdAt" db:"created_at"`
-	UpdatedAt   time.Time `json:"updatedAt" db:"updated_at"`
+	TopicID     int       `json:"topicID" xml:"topicID" db:"topic_id"` // Primary key
+	Title       string    `json:"title" xml:"title" db:"title"`
+	Slug        string    `json:"slug" xml:"slug" db:"slug"` // URL-friendly, unique form of the title
+	Description string    `json:"description" xml:"description" db:"description"`
+	CreatedBy   int       `json:"createdBy" xml:"createdBy" db:"created_by"`
+	Username    string    `json:"username" xml:"username" db:"username"`
+	CreatedAt   time.Time `json:"createdAt" xml:"createdAt" db:"created_at"`
+	UpdatedAt   time.Time `json:"updatedAt" xml:"updatedAt" db:"updated_at"`
+	Pinned      bool      `json:"pinned" xml:"pinned" db:"pinned"`                        // Pinned topics are listed first
+	LatestPost  *Post     `json:"latestPost,omitempty" xml:"latestPost,omitempty" db:"-"` // Only set when listing topics with includeLatestPost
+}
+
+// MarshalJSON serializes Topic with timestamps in TimestampFormat
+func (t Topic) MarshalJSON() ([]byte, error) {
+	type alias Topic // Alias has no methods, avoiding infinite recursion
+	return json.Marshal(struct {
+		alias
+		CreatedAt string `json:"createdAt"`
+		UpdatedAt string `json:"updatedAt"`
+	}{
+		alias:     alias(t),
+		CreatedAt: formatTimestamp(t.CreatedAt),
+		UpdatedAt: formatTimestamp(t.UpdatedAt),
+	})
+}
+
+// MarshalXML serializes Topic as a <topic> element with timestamps in TimestampFormat
+func (t Topic) MarshalXML(encoder *xml.Encoder, start xml.StartElement) error {
+	type alias Topic // Alias has no methods, avoiding infinite recursion
+	start.Name = xml.Name{Local: "topic"}
+	return encoder.EncodeElement(struct {
+		alias
+		CreatedAt string `xml:"createdAt"`
+		UpdatedAt string `xml:"updatedAt"`
+	}{
+		alias:     alias(t),
+		CreatedAt: formatTimestamp(t.CreatedAt),
+		UpdatedAt: formatTimestamp(t.UpdatedAt),
+	}, start)
 }
 
 // Post struct
 type Post struct {
-	PostID     int       `json:"postID" db:"post_id"`   // Primary key
-	TopicID    int       `json:"topicID" db:"topic_id"` // Foreign key to Topic
-	TopicTitle string    `json:"topicTitle" db:"topic_title"`
-	Title      string    `json:"title" db:"title"`
-	Content    string    `json:"content" db:"content"`
-	CreatedBy  int       `json:"createdBy" db:"created_by"`
-	Username   string    `json:"username" db:"username"`
-	CreatedAt  time.Time `json:"createdAt" db:"created_at"`
-	UpdatedAt  time.Time `json:"updatedAt" db:"updated_at"`
-	VoteCount  int       `json:"voteCount" db:"vote_count"`
-	UserVote   *int      `json:"userVote,omitempty" db:"user_vote"` // Current user's vote on post
+	PostID     int        `json:"postID" xml:"postID" db:"post_id"`    // Primary key
+	TopicID    int        `json:"topicID" xml:"topicID" db:"topic_id"` // Foreign key to Topic
+	TopicTitle string     `json:"topicTitle" xml:"topicTitle" db:"topic_title"`
+	Title      string     `json:"title" xml:"title" db:"title"`
+	Content    string     `json:"content" xml:"content" db:"content"`
+	CreatedBy  int        `json:"createdBy" xml:"createdBy" db:"created_by"`
+	Username   string     `json:"username" xml:"username" db:"username"`
+	CreatedAt  time.Time  `json:"createdAt" xml:"createdAt" db:"created_at"`
+	UpdatedAt  time.Time  `json:"updatedAt" xml:"updatedAt" db:"updated_at"`
+	VoteCount  int        `json:"voteCount" xml:"voteCount" db:"vote_count"`                  // Net score (Upvotes - Downvotes)
+	Upvotes    int        `json:"upvotes" xml:"upvotes" db:"upvotes"`                         // Only set when fetching a post by ID or a topic's posts
+	Downvotes  int        `json:"downvotes" xml:"downvotes" db:"downvotes"`                   // Only set when fetching a post by ID or a topic's posts
+	Pinned     bool       `json:"pinned" xml:"pinned" db:"pinned"`                            // Pinned posts are listed first within their topic
+	Locked     bool       `json:"locked" xml:"locked" db:"locked"`                            // Locked posts accept no new comments
+	UserVote   *int       `json:"userVote,omitempty" xml:"userVote,omitempty" db:"user_vote"` // Current user's vote on post
+	IsOwner    bool       `json:"isOwner" xml:"isOwner" db:"-"`                               // Whether the current user created the post (set by service layer)
+	Topic      *PostTopic `json:"topic,omitempty" xml:"topic,omitempty" db:"-"`               // Only set when fetching a post with its topic
+	ModifiedAt time.Time  `json:"-" xml:"-" db:"modified_at"`                                 // Last edit, pin, lock or vote change, for Last-Modified (only set when fetching a post by ID)
+}
+
+// PostInput struct
+// A post to create in a batch import
+type PostInput struct {
+	TopicID   int    `json:"topicID"`
+	Title     string `json:"title"`
+	Content   string `json:"content"`
+	CreatedBy int    `json:"createdBy,omitempty"` // Author; 0 lets the service choose (the importing admin)
+}
+
+// PostTopic struct
+// The topic a post belongs to, as embedded for breadcrumbs
+type PostTopic struct {
+	TopicID int    `json:"topicID" xml:"topicID"`
+	Title   string `json:"title" xml:"title"`
+	Slug    string `json:"slug" xml:"slug"`
+}
+
+// MarshalJSON serializes Post with timestamps in TimestampFormat
+func (p Post) MarshalJSON() ([]byte, error) {
+	type alias Post // Alias has no methods, avoiding infinite recursion
+	return json.Marshal(struct {
+		alias
+		CreatedAt string `json:"createdAt"`
+		UpdatedAt string `json:"updatedAt"`
+	}{
+		alias:     alias(p),
+		CreatedAt: formatTimestamp(p.CreatedAt),
+		UpdatedAt: formatTimestamp(p.UpdatedAt),
+	})
+}
+
+// MarshalXML serializes Post as a <post> element with timestamps in TimestampFormat
+func (p Post) MarshalXML(encoder *xml.Encoder, start xml.StartElement) error {
+	type alias Post // Alias has no methods, avoiding infinite recursion
+	start.Name = xml.Name{Local: "post"}
+	return encoder.EncodeElement(struct {
+		alias
+		CreatedAt string `xml:"createdAt"`
+		UpdatedAt string `xml:"updatedAt"`
+	}{
+		alias:     alias(p),
+		CreatedAt: formatTimestamp(p.CreatedAt),
+		UpdatedAt: formatTimestamp(p.UpdatedAt),
+	}, start)
 }
 
 // Comment struct
 type Comment struct {
-	CommentID int       `json:"commentID" db:"comment_id"` // Primary key
-	PostID    int       `json:"postID" db:"post_id"`       // Foreign key to Post
-	PostTitle string    `json:"postTitle" db:"post_title"`
-	Content   string    `json:"content" db:"content"`
-	CreatedBy int       `json:"createdBy" db:"created_by"`
-	Username  string    `json:"username" db:"username"`
-	CreatedAt time.Time `json:"createdAt" db:"created_at"`
-	UpdatedAt time.Time `json:"updatedAt" db:"updated_at"`
-	VoteCount int       `json:"voteCount" db:"vote_count"`
-	UserVote  *int      `json:"userVote,omitempty" db:"user_vote"` // Current user's vote on comment
+	CommentID       int        `json:"commentID" xml:"commentID" db:"comment_id"` // Primary key
+	PostID          int        `json:"postID" xml:"postID" db:"post_id"`          // Foreign key to Post
+	PostTitle       string     `json:"postTitle" xml:"postTitle" db:"post_title"`
+	Content         string     `json:"content" xml:"content" db:"content"`
+	CreatedBy       int        `json:"createdBy" xml:"createdBy" db:"created_by"`
+	Username        string     `json:"username" xml:"username" db:"username"`
+	CreatedAt       time.Time  `json:"createdAt" xml:"createdAt" db:"created_at"`
+	UpdatedAt       time.Time  `json:"updatedAt" xml:"updatedAt" db:"updated_at"`
+	VoteCount       int        `json:"voteCount" xml:"voteCount" db:"vote_count"`
+	UserVote        *int       `json:"userVote,omitempty" xml:"userVote,omitempty" db:"user_vote"`                       // Current user's vote on comment
+	QuotedCommentID *int       `json:"quotedCommentID,omitempty" xml:"quotedCommentID,omitempty" db:"quoted_comment_id"` // Comment (on the same post) quoted by this one
+	DeletedAt       *time.Time `json:"deletedAt,omitempty" xml:"deletedAt,omitempty" db:"deleted_at"`                    // Set if deleted while quoted by replies (content is then DeletedCommentContent)
+	IsOwner         bool       `json:"isOwner" xml:"isOwner" db:"-"`                                                     // Whether the current user created the comment (set by service layer)
+}
+
+// DeletedCommentContent replaces the content of a comment deleted while replies quote it
+const DeletedCommentContent = "[deleted]"
+
+// MarshalJSON serializes Comment with timestamps in TimestampFormat
+func (c Comment) MarshalJSON() ([]byte, error) {
+	type alias Comment // Alias has no methods, avoiding infinite recursion
+
+	var deletedAt *string
+	if c.DeletedAt != nil {
+		formatted := formatTimestamp(*c.DeletedAt)
+		deletedAt = &formatted
+	}
+
+	return json.Marshal(struct {
+		alias
+		CreatedAt string  `json:"createdAt"`
+		UpdatedAt string  `json:"updatedAt"`
+		DeletedAt *string `json:"deletedAt,omitempty"`
+	}{
+		alias:     alias(c),
+		CreatedAt: formatTimestamp(c.CreatedAt),
+		UpdatedAt: formatTimestamp(c.UpdatedAt),
+		DeletedAt: deletedAt,
+	})
+}
+
+// MarshalXML serializes Comment as a <comment> element with timestamps in TimestampFormat
+func (c Comment) MarshalXML(encoder *xml.Encoder, start xml.StartElement) error {
+	type alias Comment // Alias has no methods, avoiding infinite recursion
+	start.Name = xml.Name{Local: "comment"}
+
+	var deletedAt *string
+	if c.DeletedAt != nil {
+		formatted := formatTimestamp(*c.DeletedAt)
+		deletedAt = &formatted
+	}
+
+	return encoder.EncodeElement(struct {
+		alias
+		CreatedAt string  `xml:"createdAt"`
+		UpdatedAt string  `xml:"updatedAt"`
+		DeletedAt *string `xml:"deletedAt,omitempty"`
+	}{
+		alias:     alias(c),
+		CreatedAt: formatTimestamp(c.CreatedAt),
+		UpdatedAt: formatTimestamp(c.UpdatedAt),
+		DeletedAt: deletedAt,
+	}, start)
 }
 
 // Vote struct
@@ -63,3 +272,144 @@ type Vote struct {
 	CreatedAt time.Time `json:"createdAt" db:"created_at"`
 	UpdatedAt time.Time `json:"updatedAt" db:"updated_at"`
 }
+
+// PagedResponse struct
+// Wraps a single page of list results with pagination metadata
+type PagedResponse[T any] struct {
+	XMLName        xml.Name `json:"-" xml:"page"`
+	Items          []T      `json:"items" xml:"items>item"`
+	Total          int      `json:"total" xml:"total"`                                       // Total number of items across all pages
+	TotalEstimated bool     `json:"totalEstimated,omitempty" xml:"totalEstimated,omitempty"` // Whether Total is the planner's estimate rather than an exact count (topics list only)
+	Limit          int      `json:"limit" xml:"limit"`                                       // Effective page size, after clamping to the endpoint's maximum
+	HasMore        bool     `json:"hasMore" xml:"hasMore"`                                   // Whether another page exists after this one
+	NextCursor     *string  `json:"nextCursor,omitempty" xml:"nextCursor,omitempty"`         // Cursor for the next page: an offset, or an opaque keyset position for comments (nil on the last page)
+}
+
+// UserKarma struct
+// A user's aggregate vote score across their posts and comments
+type UserKarma struct {
+	PostKarma    int `json:"postKarma"`
+	CommentKarma int `json:"commentKarma"`
+	Total        int `json:"total"`
+}
+
+// TrendingTopic struct
+// A topic with the number of posts and comments created in it recently
+type TrendingTopic struct {
+	TopicID       int    `json:"topicID" db:"topic_id"`
+	Title         string `json:"title" db:"title"`
+	Slug          string `json:"slug" db:"slug"`
+	ActivityCount int    `json:"activityCount" db:"activity_count"`
+}
+
+// VoteCountFix struct
+// A post or comment whose stored vote count disagreed with its votes, and was corrected
+type VoteCountFix struct {
+	ID       int `json:"id"`       // Post or comment ID
+	Stored   int `json:"stored"`   // Vote count before the fix
+	Computed int `json:"computed"` // Sum of the votes, now stored
+}
+
+// VoteRecount struct
+// The posts and comments corrected by a vote recount (empty if every count was already right)
+type VoteRecount struct {
+	PostsFixed    []VoteCountFix `json:"postsFixed"`
+	CommentsFixed []VoteCountFix `json:"commentsFixed"`
+}
+
+// UserProfile struct
+// The public view of a user, looked up by username
+type UserProfile struct {
+	UserID    int       `json:"userID" db:"user_id"`
+	Username  string    `json:"username" db:"username"`
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+	Karma     UserKarma `json:"karma"`
+}
+
+// MarshalJSON serializes UserProfile with timestamps in TimestampFormat
+func (u UserProfile) MarshalJSON() ([]byte, error) {
+	type alias UserProfile // Alias has no methods, avoiding infinite recursion
+
+	return json.Marshal(struct {
+		alias
+		CreatedAt string `json:"createdAt"`
+	}{
+		alias:     alias(u),
+		CreatedAt: formatTimestamp(u.CreatedAt),
+	})
+}
+
+// ActiveUser struct
+// A user with the number of posts and comments they created recently
+type ActiveUser struct {
+	UserID        int    `json:"userID" db:"user_id"`
+	Username      string `json:"username" db:"username"`
+	ActivityCount int    `json:"activityCount" db:"activity_count"`
+}
+
+// TopicWithPosts struct
+// A topic together with the first page of its posts, for rendering a topic page in one request
+type TopicWithPosts struct {
+	XMLName xml.Name `json:"-" xml:"topicWithPosts"`
+	Topic   *Topic   `json:"topic" xml:"topic"`
+	Posts   []*Post  `json:"posts" xml:"posts>post"`
+	HasMore bool     `json:"hasMore" xml:"hasMore"` // Whether more posts exist beyond the first page
+}
+
+// IdempotencyKey struct
+// Records the resource created by a request carrying an Idempotency-Key header
+type IdempotencyKey struct {
+	Key          string    `json:"key" db:"idempotency_key"`
+	UserID       int       `json:"userID" db:"user_id"`
+	ResourceType string    `json:"resourceType" db:"resource_type"` // "post" or "comment"
+	ResourceID   *int      `json:"resourceID" db:"resource_id"`     // nil while the resource is still being created
+	RequestHash  string    `json:"-" db:"request_hash"`             // Used to detect key reuse with a different body
+	CreatedAt    time.Time `json:"createdAt" db:"created_at"`
+}
+
+// AuditLogEntry struct
+// Records who performed an irreversible action (e.g. a deletion) on which resource
+type AuditLogEntry struct {
+	AuditID     int            `json:"auditID" db:"id"`                // Primary key
+	ActorUserID *int           `json:"actorUserID" db:"actor_user_id"` // Nil if the actor has since been deleted
+	Action      string         `json:"action" db:"action"`             // e.g. "delete"
+	TargetType  string         `json:"targetType" db:"target_type"`    // "topic", "post", "comment" or "user"
+	TargetID    int            `json:"targetID" db:"target_id"`
+	CreatedAt   time.Time      `json:"createdAt" db:"created_at"`
+	Metadata    map[string]any `json:"metadata" db:"metadata"` // e.g. the deleted content's owner
+}
+
+// MarshalJSON serializes AuditLogEntry with timestamps in TimestampFormat
+func (e AuditLogEntry) MarshalJSON() ([]byte, error) {
+	type alias AuditLogEntry // Alias has no methods, avoiding infinite recursion
+	return json.Marshal(struct {
+		alias
+		CreatedAt string `json:"createdAt"`
+	}{
+		alias:     alias(e),
+		CreatedAt: formatTimestamp(e.CreatedAt),
+	})
+}
+
+// Webhook struct
+// An HTTP endpoint notified when a post is created in a topic
+type Webhook struct {
+	WebhookID int       `json:"webhookID" db:"id"`   // Primary key
+	UserID    int       `json:"userID" db:"user_id"` // Foreign key to User (owner)
+	TopicID   int       `json:"topicID" db:"topic_id"`
+	URL       string    `json:"url" db:"url"`
+	Secret    string    `json:"-" db:"secret"` // Never returned to clients
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+}
+
+// MarshalJSON serializes Webhook with timestamps in TimestampFormat
+func (w Webhook) MarshalJSON() ([]byte, error) {
+	type alias Webhook // Alias has no methods, avoiding infinite recursion
+	return json.Marshal(struct {
+		alias
+		CreatedAt string `json:"createdAt"`
+	}{
+		alias:     alias(w),
+		CreatedAt: formatTimestamp(w.CreatedAt),
+	})
+}
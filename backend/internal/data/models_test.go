@@ -0,0 +1,129 @@
+// Run `go test -v ./internal/data -run "TestTimestampSerialization|TestXMLSerialization"` in /backend
+
+package data
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+	"time"
+)
+
+func TestTimestampSerialization(t *testing.T) {
+	// Known timestamp in a non-UTC zone with nanosecond precision
+	zone := time.FixedZone("UTC+8", 8*60*60)
+	timestamp := time.Date(2024, time.March, 5, 22, 7, 9, 123456789, zone)
+	expected := "2024-03-05T14:07:09.123Z"
+
+	models := map[string]any{
+		"User":    User{CreatedAt: timestamp, UpdatedAt: timestamp},
+		"Topic":   &Topic{CreatedAt: timestamp, UpdatedAt: timestamp},
+		"Post":    Post{CreatedAt: timestamp, UpdatedAt: timestamp},
+		"Comment": &Comment{CreatedAt: timestamp, UpdatedAt: timestamp},
+	}
+
+	for name, model := range models {
+		t.Run(name, func(t *testing.T) {
+			encoded, err := json.Marshal(model)
+			if err != nil {
+				t.Fatalf("failed to marshal %s: %v", name, err)
+			}
+
+			var fields map[string]any
+			if err := json.Unmarshal(encoded, &fields); err != nil {
+				t.Fatalf("failed to unmarshal %s: %v", name, err)
+			}
+
+			if fields["createdAt"] != expected {
+				t.Errorf("expected createdAt %q, got %v", expected, fields["createdAt"])
+			}
+
+			if fields["updatedAt"] != expected {
+				t.Errorf("expected updatedAt %q, got %v", expected, fields["updatedAt"])
+			}
+		})
+	}
+
+	// Other fields are still serialized alongside the formatted timestamps
+	t.Run("OtherFieldsPreserved", func(t *testing.T) {
+		encoded, _ := json.Marshal(User{UserID: 7, Username: "alice", PasswordHash: "secret", CreatedAt: timestamp})
+
+		var fields map[string]any
+		json.Unmarshal(encoded, &fields)
+
+		if fields["userID"] != float64(7) || fields["username"] != "alice" {
+			t.Errorf("expected userID and username to be preserved, got %s", encoded)
+		}
+
+		if _, ok := fields["PasswordHash"]; ok {
+			t.Errorf("expected password hash to be excluded, got %s", encoded)
+		}
+	})
+}
+
+func TestXMLSerialization(t *testing.T) {
+	zone := time.FixedZone("UTC+8", 8*60*60)
+	timestamp := time.Date(2024, time.March, 5, 22, 7, 9, 123456789, zone)
+	expected := "2024-03-05T14:07:09.123Z"
+
+	// Decoded shape shared by topics, posts and comments
+	type timestamps struct {
+		XMLName   xml.Name
+		Title     string `xml:"title"`
+		CreatedAt string `xml:"createdAt"`
+		UpdatedAt string `xml:"updatedAt"`
+	}
+
+	models := map[string]any{
+		"topic":   &Topic{Title: "Title", CreatedAt: timestamp, UpdatedAt: timestamp},
+		"post":    Post{Title: "Title", CreatedAt: timestamp, UpdatedAt: timestamp},
+		"comment": &Comment{CreatedAt: timestamp, UpdatedAt: timestamp},
+	}
+
+	for root, model := range models {
+		t.Run(root, func(t *testing.T) {
+			encoded, err := xml.Marshal(model)
+			if err != nil {
+				t.Fatalf("failed to marshal %s: %v", root, err)
+			}
+
+			var fields timestamps
+			if err := xml.Unmarshal(encoded, &fields); err != nil {
+				t.Fatalf("failed to unmarshal %s: %v", root, err)
+			}
+
+			if fields.XMLName.Local != root {
+				t.Errorf("expected root element %q, got %q", root, fields.XMLName.Local)
+			}
+
+			if fields.CreatedAt != expected || fields.UpdatedAt != expected {
+				t.Errorf("expected timestamps %q, got %s", expected, encoded)
+			}
+
+			if root != "comment" && fields.Title != "Title" {
+				t.Errorf("expected title to be preserved, got %s", encoded)
+			}
+		})
+	}
+
+	// Pages wrap their items in a single root element
+	t.Run("PagedResponse", func(t *testing.T) {
+		encoded, err := xml.Marshal(PagedResponse[*Topic]{Items: []*Topic{{TopicID: 1}, {TopicID: 2}}, Total: 2})
+		if err != nil {
+			t.Fatalf("failed to marshal page: %v", err)
+		}
+
+		var page struct {
+			XMLName xml.Name `xml:"page"`
+			Topics  []Topic  `xml:"items>topic"`
+			Total   int      `xml:"total"`
+		}
+		if err := xml.Unmarshal(encoded, &page); err != nil {
+			t.Fatalf("failed to unmarshal page: %v", err)
+		}
+
+		if len(page.Topics) != 2 || page.Topics[1].TopicID != 2 || page.Total != 2 {
+			t.Errorf("expected two topics in page, got %s", encoded)
+		}
+	})
+}
@@ -0,0 +1,85 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// CreatePasswordReset stores the hash of a new reset token for a user, valid for ttl
+// The expiry is computed from the database clock, which ResetPassword checks it against
+func (repo *Repository) CreatePasswordReset(userID int, tokenHash string, ttl time.Duration) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	query := `
+		INSERT INTO password_resets (user_id, token_hash, expires_at)
+		VALUES ($1, $2, NOW() + ($3 * INTERVAL '1 second'))`
+
+	if _, err := repo.DB.Exec(ctx, query, userID, tokenHash, ttl.Seconds()); err != nil {
+		return fmt.Errorf("failed to create password reset: %w", err)
+	}
+
+	return nil
+}
+
+// ResetPassword sets a new password hash for the user holding an unused, unexpired reset token,
+// using up that token and any others outstanding for the user
+// Returns ErrInvalidResetToken if no such token exists
+func (repo *Repository) ResetPassword(tokenHash, passwordHash string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Claim the token and change the password atomically
+	tx, err := repo.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx) // No-op once committed
+
+	// Claiming with a conditional UPDATE means concurrent resets cannot both use the same token
+	var userID int
+	err = tx.QueryRow(
+		ctx,
+		`UPDATE password_resets
+		SET used_at = NOW()
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+		RETURNING user_id`,
+		tokenHash,
+	).Scan(&userID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return ErrInvalidResetToken
+		}
+
+		return fmt.Errorf("failed to claim reset token: %w", err)
+	}
+
+	_, err = tx.Exec(
+		ctx,
+		`UPDATE users SET password_hash = $1, updated_at = NOW() WHERE user_id = $2`,
+		passwordHash,
+		userID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+
+	// Older links sent before this reset should stop working too
+	_, err = tx.Exec(
+		ctx,
+		`UPDATE password_resets SET used_at = NOW() WHERE user_id = $1 AND used_at IS NULL`,
+		userID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to revoke reset tokens: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit password reset: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,93 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// CreatePostsBatch inserts every post in a single multi-row INSERT, so either all are created or none are
+// Returns the created posts (with author usernames and topic titles) in input order
+// Returns ErrTopicNotFound or ErrUserNotFound (wrapped) if any post references a missing topic or author
+func (repo *Repository) CreatePostsBatch(posts []PostInput) ([]*Post, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	topicIDs := make([]int, len(posts))
+	titles := make([]string, len(posts))
+	contents := make([]string, len(posts))
+	authorIDs := make([]int, len(posts))
+	for i, post := range posts {
+		topicIDs[i] = post.TopicID
+		titles[i] = post.Title
+		contents[i] = post.Content
+		authorIDs[i] = post.CreatedBy
+	}
+
+	// Rows are inserted in input order, so serial post IDs follow it too
+	query := `
+		WITH inserted AS (
+			INSERT INTO posts (topic_id, title, content, created_by, created_at, updated_at)
+			SELECT input.topic_id, input.title, input.content, input.created_by, NOW(), NOW()
+			FROM unnest($1::int[], $2::text[], $3::text[], $4::int[])
+				WITH ORDINALITY AS input(topic_id, title, content, created_by, position)
+			ORDER BY input.position
+			RETURNING post_id, topic_id, title, content, created_by, created_at, updated_at
+		)
+		SELECT i.post_id, i.topic_id, t.title, i.title, i.content, i.created_by, u.username, i.created_at, i.updated_at
+		FROM inserted i
+		JOIN users u ON i.created_by = u.user_id
+		JOIN topics t ON i.topic_id = t.topic_id
+		ORDER BY i.post_id`
+
+	rows, err := repo.DB.Query(ctx, query, topicIDs, titles, contents, authorIDs)
+	if err != nil {
+		return nil, postsBatchError(err)
+	}
+	defer rows.Close()
+
+	created := make([]*Post, 0, len(posts))
+	for rows.Next() {
+		var post Post
+		err := rows.Scan(
+			&post.PostID,
+			&post.TopicID,
+			&post.TopicTitle,
+			&post.Title,
+			&post.Content,
+			&post.CreatedBy,
+			&post.Username,
+			&post.CreatedAt,
+			&post.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan post row: %w", err)
+		}
+
+		created = append(created, &post)
+	}
+
+	// Constraint violations usually surface only once the rows are read
+	if err := rows.Err(); err != nil {
+		return nil, postsBatchError(err)
+	}
+
+	return created, nil
+}
+
+// postsBatchError maps a foreign key violation from CreatePostsBatch to ErrTopicNotFound or ErrUserNotFound
+func postsBatchError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23503" { // foreign_key_violation error code
+		if strings.Contains(pgErr.ConstraintName, "topic") {
+			return fmt.Errorf("%w: %s", ErrTopicNotFound, pgErr.Detail)
+		}
+
+		return fmt.Errorf("%w: %s", ErrUserNotFound, pgErr.Detail)
+	}
+
+	return fmt.Errorf("failed to create posts: %w", err)
+}
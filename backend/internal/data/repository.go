@@ -2,15 +2,21 @@ package data
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 // Repository holds all database access methods
 type Repository struct {
-	DB *pgxpool.Pool // Connection pool created in ./db.go
+	DB     *pgxpool.Pool // Connection pool created in ./db.go; used for all writes
+	ReadDB *pgxpool.Pool // Optional read replica pool (see OpenReadDB) for read-heavy listings; nil to read from DB
 }
 
 // NewRepository initializes a new instance of Repository struct
@@ -18,18 +24,87 @@ func NewRepository(db *pgxpool.Pool) *Repository {
 	return &Repository{DB: db}
 }
 
-// GetAllTopics fetches all topics from the database
-func (repo *Repository) GetAllTopics() ([]*Topic, error) {
+// reader returns the pool for reads that may lag slightly behind writes: the replica if set, otherwise the primary
+func (repo *Repository) reader() *pgxpool.Pool {
+	if repo.ReadDB != nil {
+		return repo.ReadDB
+	}
+
+	return repo.DB
+}
+
+// newPagedResponse builds a PagedResponse from a result set fetched with limit+1 rows
+// The extra row (if present) only signals that another page exists and is trimmed off
+func newPagedResponse[T any](items []T, total, limit, offset int) *PagedResponse[T] {
+	page := &PagedResponse[T]{
+		Items: items,
+		Total: total,
+		Limit: limit,
+	}
+
+	if len(items) > limit {
+		page.Items = items[:limit]
+		page.HasMore = true
+
+		nextCursor := strconv.Itoa(offset + limit)
+		page.NextCursor = &nextCursor
+	}
+
+	return page
+}
+
+// GetAllTopics fetches a page of topics from the database
+// The total is the planner's estimate once it reaches estimateAbove (0 always counts exactly; see countTopics)
+func (repo *Repository) GetAllTopics(limit, offset, estimateAbove int) (*PagedResponse[*Topic], error) {
+	return repo.getAllTopics(repo.reader(), limit, offset, estimateAbove, false)
+}
+
+// GetAllTopicsFromPrimary fetches a page of topics as in GetAllTopics, but always from the primary, so the page
+// reflects every committed write (e.g. to fill a cache right after invalidating it, which a lagging replica would undo)
+func (repo *Repository) GetAllTopicsFromPrimary(limit, offset, estimateAbove int) (*PagedResponse[*Topic], error) {
+	return repo.getAllTopics(repo.DB, limit, offset, estimateAbove, false)
+}
+
+// GetAllTopicsWithLatestPost fetches a page of topics as in GetAllTopics, each with its most recent post
+// (ID, title and creation time only) embedded as Topic.LatestPost, or nil if the topic has no posts
+func (repo *Repository) GetAllTopicsWithLatestPost(limit, offset, estimateAbove int) (*PagedResponse[*Topic], error) {
+	return repo.getAllTopics(repo.reader(), limit, offset, estimateAbove, true)
+}
+
+// getAllTopics fetches a page of topics from db, joining each topic's latest post if includeLatestPost is set
+func (repo *Repository) getAllTopics(db *pgxpool.Pool, limit, offset, estimateAbove int, includeLatestPost bool) (*PagedResponse[*Topic], error) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel() // Ensures context is cleaned up when function returns
 
+	// Count all topics (or estimate, for large tables) for pagination metadata
+	total, estimated, err := countTopics(ctx, db, estimateAbove)
+	if err != nil {
+		return nil, err
+	}
+
+	// The latest post is looked up per topic on the page only, so the join stays cheap
+	latestPostColumns, latestPostJoin := "", ""
+	if includeLatestPost {
+		latestPostColumns = `, lp.post_id, lp.title, lp.created_at`
+		latestPostJoin = `
+        LEFT JOIN LATERAL (
+            SELECT p.post_id, p.title, p.created_at
+            FROM posts p
+            WHERE p.topic_id = t.topic_id
+            ORDER BY p.created_at DESC, p.post_id DESC
+            LIMIT 1
+        ) lp ON TRUE`
+	}
+
+	// Fetch one extra row to determine whether another page exists
 	query := `
-        SELECT t.topic_id, t.title, t.description, t.created_by, u.username, t.created_at, t.updated_at
+        SELECT t.topic_id, t.title, COALESCE(t.slug, ''), t.description, t.created_by, u.username, t.created_at, t.updated_at, t.pinned` + latestPostColumns + `
         FROM topics t
-        JOIN users u ON t.created_by = u.user_id
-        ORDER BY t.created_at DESC`
+        JOIN users u ON t.created_by = u.user_id` + latestPostJoin + `
+        ORDER BY t.pinned DESC, t.created_at DESC
+        LIMIT $1 OFFSET $2`
 
-	rows, err := repo.DB.Query(ctx, query)
+	rows, err := db.Query(ctx, query, limit+1, offset)
 	if err != nil {
 		return nil, fmt.Errorf("query all topics failed: %w", err)
 	}
@@ -41,21 +116,199 @@ func (repo *Repository) GetAllTopics() ([]*Topic, error) {
 		var t Topic
 
 		// Scan column values from current row into fields of the Topic struct (must match SELECT order)
+		dest := []any{
+			&t.TopicID,
+			&t.Title,
+			&t.Slug,
+			&t.Description,
+			&t.CreatedBy,
+			&t.Username,
+			&t.CreatedAt,
+			&t.UpdatedAt,
+			&t.Pinned,
+		}
+
+		// Latest post columns are NULL for topics without posts
+		var latestPostID *int
+		var latestPostTitle *string
+		var latestPostCreatedAt *time.Time
+		if includeLatestPost {
+			dest = append(dest, &latestPostID, &latestPostTitle, &latestPostCreatedAt)
+		}
+
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("error scanning topic row: %w", err)
+		}
+
+		if latestPostID != nil {
+			t.LatestPost = &Post{
+				PostID:     *latestPostID,
+				TopicID:    t.TopicID,
+				TopicTitle: t.Title,
+				Title:      *latestPostTitle,
+				CreatedAt:  *latestPostCreatedAt,
+			}
+		}
+
+		topics = append(topics, &t) // Append a pointer to the Topic to the slice
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error encountered during row iteration: %w", err)
+	}
+
+	page := newPagedResponse(topics, total, limit, offset)
+	page.TotalEstimated = estimated
+
+	return page, nil
+}
+
+// ForEachTopic calls fn for every topic, ordered as in GetAllTopics, as rows are read
+// Lets callers stream every topic without holding them all in memory; stops at the first error from fn
+func (repo *Repository) ForEachTopic(fn func(*Topic) error) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	query := `
+		SELECT t.topic_id, t.title, COALESCE(t.slug, ''), t.description, t.created_by, u.username, t.created_at, t.updated_at, t.pinned
+		FROM topics t
+		JOIN users u ON t.created_by = u.user_id
+		ORDER BY t.pinned DESC, t.created_at DESC`
+
+	rows, err := repo.reader().Query(ctx, query)
+	if err != nil {
+		return fmt.Errorf("query all topics failed: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var t Topic
+
+		err := rows.Scan(
+			&t.TopicID,
+			&t.Title,
+			&t.Slug,
+			&t.Description,
+			&t.CreatedBy,
+			&t.Username,
+			&t.CreatedAt,
+			&t.UpdatedAt,
+			&t.Pinned,
+		)
+
+		if err != nil {
+			return fmt.Errorf("error scanning topic row: %w", err)
+		}
+
+		if err := fn(&t); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error encountered during row iteration: %w", err)
+	}
+
+	return nil
+}
+
+// GetTopicsByUser fetches a page of the topics created by a user, ordered as in GetAllTopics
+func (repo *Repository) GetTopicsByUser(userID, limit, offset int) (*PagedResponse[*Topic], error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Count the user's topics for pagination metadata
+	var total int
+	err := repo.DB.QueryRow(ctx, `SELECT COUNT(*) FROM topics WHERE created_by = $1`, userID).Scan(&total)
+	if err != nil {
+		return nil, fmt.Errorf("count topics by user failed: %w", err)
+	}
+
+	// Fetch one extra row to determine whether another page exists
+	query := `
+        SELECT t.topic_id, t.title, COALESCE(t.slug, ''), t.description, t.created_by, u.username, t.created_at, t.updated_at, t.pinned
+        FROM topics t
+        JOIN users u ON t.created_by = u.user_id
+        WHERE t.created_by = $1
+        ORDER BY t.pinned DESC, t.created_at DESC
+        LIMIT $2 OFFSET $3`
+
+	rows, err := repo.DB.Query(ctx, query, userID, limit+1, offset)
+	if err != nil {
+		return nil, fmt.Errorf("query topics by user failed: %w", err)
+	}
+	defer rows.Close()
+
+	topics := []*Topic{}
+	for rows.Next() {
+		var t Topic
+
 		err := rows.Scan(
 			&t.TopicID,
 			&t.Title,
+			&t.Slug,
 			&t.Description,
 			&t.CreatedBy,
 			&t.Username,
 			&t.CreatedAt,
 			&t.UpdatedAt,
+			&t.Pinned,
 		)
 
 		if err != nil {
 			return nil, fmt.Errorf("error scanning topic row: %w", err)
 		}
 
-		topics = append(topics, &t) // Append a pointer to the Topic to the slice
+		topics = append(topics, &t)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error encountered during row iteration: %w", err)
+	}
+
+	return newPagedResponse(topics, total, limit, offset), nil
+}
+
+// GetTopicsByIDs retrieves the topics with the given IDs, in the order the IDs were given
+// IDs that do not match any topic are skipped
+func (repo *Repository) GetTopicsByIDs(topicIDs []int) ([]*Topic, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	query := `
+		SELECT t.topic_id, t.title, COALESCE(t.slug, ''), t.description, t.created_by, u.username, t.created_at, t.updated_at, t.pinned
+		FROM topics t
+		JOIN users u ON t.created_by = u.user_id
+		WHERE t.topic_id = ANY($1)
+		ORDER BY array_position($1, t.topic_id)`
+
+	rows, err := repo.DB.Query(ctx, query, topicIDs)
+	if err != nil {
+		return nil, fmt.Errorf("query topics by IDs failed: %w", err)
+	}
+	defer rows.Close()
+
+	topics := []*Topic{}
+	for rows.Next() {
+		var t Topic
+
+		err := rows.Scan(
+			&t.TopicID,
+			&t.Title,
+			&t.Slug,
+			&t.Description,
+			&t.CreatedBy,
+			&t.Username,
+			&t.CreatedAt,
+			&t.UpdatedAt,
+			&t.Pinned,
+		)
+
+		if err != nil {
+			return nil, fmt.Errorf("error scanning topic row: %w", err)
+		}
+
+		topics = append(topics, &t)
 	}
 
 	if err = rows.Err(); err != nil {
@@ -71,7 +324,7 @@ func (repo *Repository) GetTopicByID(topicID int) (*Topic, error) {
 
 	var topic Topic
 	query := `
-		SELECT t.topic_id, t.title, t.description, t.created_by, u.username, t.created_at, t.updated_at
+		SELECT t.topic_id, t.title, COALESCE(t.slug, ''), t.description, t.created_by, u.username, t.created_at, t.updated_at, t.pinned
         FROM topics t
         JOIN users u ON t.created_by = u.user_id
 		WHERE t.topic_id = $1`
@@ -79,16 +332,18 @@ func (repo *Repository) GetTopicByID(topicID int) (*Topic, error) {
 	err := repo.DB.QueryRow(ctx, query, topicID).Scan(
 		&topic.TopicID,
 		&topic.Title,
+		&topic.Slug,
 		&topic.Description,
 		&topic.CreatedBy,
 		&topic.Username,
 		&topic.CreatedAt,
 		&topic.UpdatedAt,
+		&topic.Pinned,
 	)
 
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			return nil, fmt.Errorf("topic not found: %d", topicID)
+			return nil, fmt.Errorf("%w: %d", ErrTopicNotFound, topicID)
 		}
 		return nil, fmt.Errorf("query to find topic failed: %w", err)
 	}
@@ -96,6 +351,73 @@ func (repo *Repository) GetTopicByID(topicID int) (*Topic, error) {
 	return &topic, nil
 }
 
+// TopicTitleExists checks whether a topic with the given title exists, ignoring case
+func (repo *Repository) TopicTitleExists(title string) (bool, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var exists bool
+	query := `SELECT EXISTS (SELECT 1 FROM topics WHERE LOWER(title) = LOWER($1))`
+
+	err := repo.DB.QueryRow(ctx, query, title).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check topic title: %w", err)
+	}
+
+	return exists, nil
+}
+
+// GetTopicBySlug fetches a topic by its unique slug
+func (repo *Repository) GetTopicBySlug(slug string) (*Topic, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var topic Topic
+	query := `
+		SELECT t.topic_id, t.title, COALESCE(t.slug, ''), t.description, t.created_by, u.username, t.created_at, t.updated_at, t.pinned
+        FROM topics t
+        JOIN users u ON t.created_by = u.user_id
+		WHERE t.slug = $1`
+
+	err := repo.DB.QueryRow(ctx, query, slug).Scan(
+		&topic.TopicID,
+		&topic.Title,
+		&topic.Slug,
+		&topic.Description,
+		&topic.CreatedBy,
+		&topic.Username,
+		&topic.CreatedAt,
+		&topic.UpdatedAt,
+		&topic.Pinned,
+	)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("%w: slug %q", ErrTopicNotFound, slug)
+		}
+		return nil, fmt.Errorf("query to find topic by slug failed: %w", err)
+	}
+
+	return &topic, nil
+}
+
+// TopicExists checks whether a topic with the given ID exists
+// Lighter than GetTopicByID as no columns or joins are fetched
+func (repo *Repository) TopicExists(topicID int) (bool, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var exists bool
+	query := `SELECT EXISTS (SELECT 1 FROM topics WHERE topic_id = $1)`
+
+	err := repo.DB.QueryRow(ctx, query, topicID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check topic existence: %w", err)
+	}
+
+	return exists, nil
+}
+
 // GetUserByUsername fetches user by their unique username
 // Used to check if a user exists (during registration) and to retrieve credentials (during login)
 func (repo *Repository) GetUserByUsername(username string) (*User, error) {
@@ -104,7 +426,7 @@ func (repo *Repository) GetUserByUsername(username string) (*User, error) {
 
 	var user User
 	query := `
-    	SELECT user_id, username, password_hash, created_at, updated_at
+    	SELECT user_id, username, password_hash, email, created_at, updated_at, token_version
         FROM users
         WHERE username = $1`
 
@@ -112,13 +434,15 @@ func (repo *Repository) GetUserByUsername(username string) (*User, error) {
 		&user.UserID,
 		&user.Username,
 		&user.PasswordHash,
+		&user.Email,
 		&user.CreatedAt,
 		&user.UpdatedAt,
+		&user.TokenVersion,
 	)
 
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			return nil, fmt.Errorf("user not found: %s", username)
+			return nil, fmt.Errorf("%w: %s", ErrUserNotFound, username)
 		}
 		return nil, fmt.Errorf("query to find user failed: %w", err)
 	}
@@ -127,6 +451,37 @@ func (repo *Repository) GetUserByUsername(username string) (*User, error) {
 	return &user, nil
 }
 
+// GetUserByEmail fetches user by their (lowercased) email, including credentials for login
+func (repo *Repository) GetUserByEmail(email string) (*User, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var user User
+	query := `
+		SELECT user_id, username, password_hash, email, created_at, updated_at, token_version
+		FROM users
+		WHERE email = $1`
+
+	err := repo.DB.QueryRow(ctx, query, email).Scan(
+		&user.UserID,
+		&user.Username,
+		&user.PasswordHash,
+		&user.Email,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+		&user.TokenVersion,
+	)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("%w with email: %s", ErrUserNotFound, email)
+		}
+		return nil, fmt.Errorf("query to find user failed: %w", err)
+	}
+
+	return &user, nil
+}
+
 // CreateUser inserts a new user record into the database
 // NOTE: Password MUST already be hashed (in service layer) before this function is called
 func (repo *Repository) CreateUser(user *User) (*User, error) {
@@ -134,8 +489,8 @@ func (repo *Repository) CreateUser(user *User) (*User, error) {
 	defer cancel()
 
 	query := `
-        INSERT INTO users (username, password_hash, created_at, updated_at)
-        VALUES ($1, $2, NOW(), NOW())
+        INSERT INTO users (username, password_hash, email, created_at, updated_at)
+        VALUES ($1, $2, $3, NOW(), NOW())
         RETURNING user_id, created_at, updated_at`
 
 	err := repo.DB.QueryRow(
@@ -143,6 +498,7 @@ func (repo *Repository) CreateUser(user *User) (*User, error) {
 		query,
 		user.Username,
 		user.PasswordHash,
+		user.Email,
 	).Scan(
 		&user.UserID,
 		&user.CreatedAt,
@@ -162,30 +518,44 @@ func (repo *Repository) CreateTopic(title, description string, userID int) (*Top
 	defer cancel()
 
 	query := `
-		INSERT INTO topics (title, description, created_by, created_at, updated_at)
-		VALUES ($1, $2, $3, NOW(), NOW())
-		RETURNING topic_id, title, description, created_by, created_at, updated_at
+		INSERT INTO topics (title, slug, description, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, NOW(), NOW())
+		RETURNING topic_id, title, slug, description, created_by, created_at, updated_at
 	`
 
 	// Scan returned row into Topic struct
+	// A concurrent insert may claim the same slug first, in which case the next free one is tried
 	var topic Topic
-	err := repo.DB.QueryRow(
-		ctx,
-		query,
-		title,
-		description,
-		userID,
-	).Scan(
-		&topic.TopicID,
-		&topic.Title,
-		&topic.Description,
-		&topic.CreatedBy,
-		&topic.CreatedAt,
-		&topic.UpdatedAt,
-	)
+	for attempt := 1; ; attempt++ {
+		slug, err := repo.uniqueTopicSlug(ctx, Slugify(title), 0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create topic: %w", err)
+		}
 
-	if err != nil {
-		return nil, fmt.Errorf("failed to create topic: %w", err)
+		err = repo.DB.QueryRow(
+			ctx,
+			query,
+			title,
+			slug,
+			description,
+			userID,
+		).Scan(
+			&topic.TopicID,
+			&topic.Title,
+			&topic.Slug,
+			&topic.Description,
+			&topic.CreatedBy,
+			&topic.CreatedAt,
+			&topic.UpdatedAt,
+		)
+
+		if err == nil {
+			break
+		}
+
+		if !isTopicSlugConflict(err) || attempt == maxSlugAttempts {
+			return nil, fmt.Errorf("failed to create topic: %w", err)
+		}
 	}
 
 	// Fetch username of topic creator
@@ -195,7 +565,7 @@ func (repo *Repository) CreateTopic(title, description string, userID int) (*Top
 		FROM users
 		WHERE user_id = $1`
 
-	err = repo.DB.QueryRow(ctx, userQuery, userID).Scan(&username)
+	err := repo.DB.QueryRow(ctx, userQuery, userID).Scan(&username)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch username: %w", err)
 	}
@@ -205,12 +575,34 @@ func (repo *Repository) CreateTopic(title, description string, userID int) (*Top
 	return &topic, nil
 }
 
-// GetPostsByTopicID fetches all posts for a given topic ID
-func (repo *Repository) GetPostsByTopicID(topicID int, userID *int) ([]*Post, error) {
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+// Post sort orders accepted by GetPostsByTopicID
+const (
+	PostSortNew           = "new"           // Newest first
+	PostSortControversial = "controversial" // Most evenly split votes first
+)
 
-	query := `
+// postSortOrders maps each post sort order to its ORDER BY clause (pinned posts always come first)
+// Controversy is the total vote count scaled by how evenly it is split:
+// (upvotes + downvotes) * min(upvotes, downvotes) / max(upvotes, downvotes, 1)
+// so 5 up / 5 down scores 10, while 10 up / 0 down scores 0
+var postSortOrders = map[string]string{
+	PostSortNew: "p.pinned DESC, p.created_at DESC",
+	PostSortControversial: `p.pinned DESC,
+		(v.upvotes + v.downvotes) * (LEAST(v.upvotes, v.downvotes)::float / GREATEST(v.upvotes, v.downvotes, 1)) DESC,
+		p.created_at DESC`,
+}
+
+// IsValidPostSort reports whether sort is a supported post sort order
+func IsValidPostSort(sort string) bool {
+	_, ok := postSortOrders[sort]
+	return ok
+}
+
+// postsByTopicQuery builds GetPostsByTopicID's page query for an ORDER BY clause from postSortOrders
+// ($1 topic ID, $2 optional user ID, $3 limit, $4 offset)
+// For the newest-first order, idx_posts_topic_pinned_created_at lets the page be read in order, stopping after $3 rows
+func postsByTopicQuery(orderBy string) string {
+	return `
 		SELECT 
 			p.post_id, 
 			p.topic_id, 
@@ -222,6 +614,10 @@ func (repo *Repository) GetPostsByTopicID(topicID int, userID *int) ([]*Post, er
 			p.created_at, 
 			p.updated_at,
 			p.vote_count,
+			v.upvotes,
+			v.downvotes,
+			p.pinned,
+			p.locked,
 			CASE 
 				WHEN $2::integer IS NOT NULL THEN (
 					SELECT vote_type FROM votes 
@@ -232,10 +628,44 @@ func (repo *Repository) GetPostsByTopicID(topicID int, userID *int) ([]*Post, er
 		FROM posts p
 		JOIN users u ON p.created_by = u.user_id
 		JOIN topics t ON p.topic_id = t.topic_id
+		CROSS JOIN LATERAL (
+			SELECT
+				COUNT(*) FILTER (WHERE vote_type = 1) AS upvotes,
+				COUNT(*) FILTER (WHERE vote_type = -1) AS downvotes
+			FROM votes
+			WHERE post_id = p.post_id
+		) v
 		WHERE p.topic_id = $1
-		ORDER BY p.created_at DESC`
+		ORDER BY ` + orderBy + `
+		LIMIT $3 OFFSET $4`
+}
+
+// GetPostsByTopicID fetches a page of posts for a given topic ID in the given sort order
+func (repo *Repository) GetPostsByTopicID(topicID int, userID *int, sort string, limit, offset int) (*PagedResponse[*Post], error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	orderBy, ok := postSortOrders[sort]
+	if !ok {
+		return nil, fmt.Errorf("invalid post sort: %s", sort)
+	}
 
-	rows, err := repo.DB.Query(ctx, query, topicID, userID)
+	// Count all posts in topic for pagination metadata
+	var total int
+	countQuery := `
+		SELECT COUNT(*)
+		FROM posts
+		WHERE topic_id = $1`
+
+	err := repo.reader().QueryRow(ctx, countQuery, topicID).Scan(&total)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count posts: %w", err)
+	}
+
+	// Fetch one extra row to determine whether another page exists
+	query := postsByTopicQuery(orderBy)
+
+	rows, err := repo.reader().Query(ctx, query, topicID, userID, limit+1, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query posts: %w", err)
 	}
@@ -256,6 +686,10 @@ func (repo *Repository) GetPostsByTopicID(topicID int, userID *int) ([]*Post, er
 			&post.CreatedAt,
 			&post.UpdatedAt,
 			&post.VoteCount,
+			&post.Upvotes,
+			&post.Downvotes,
+			&post.Pinned,
+			&post.Locked,
 			&post.UserVote,
 		)
 
@@ -270,27 +704,96 @@ func (repo *Repository) GetPostsByTopicID(topicID int, userID *int) ([]*Post, er
 		return nil, fmt.Errorf("error encountered during row iteration: %w", err)
 	}
 
-	return posts, nil
+	return newPagedResponse(posts, total, limit, offset), nil
 }
 
-// GetPostByID fetches a specific post by its ID
-func (repo *Repository) GetPostByID(postID int, userID *int) (*Post, error) {
+// ForEachPostInTopic calls fn for each post in a topic, oldest first, as rows are read
+// Lets callers stream large topics without holding every post in memory; stops at the first error from fn
+// Only the ID, title, author, vote count and creation time are populated
+func (repo *Repository) ForEachPostInTopic(topicID int, fn func(*Post) error) error {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	var post Post
 	query := `
-		SELECT 
-			p.post_id, 
-			p.topic_id,
-			t.title as topic_title, 
-			p.title, 
+		SELECT
+			p.post_id,
+			p.title,
+			u.username,
+			p.vote_count,
+			p.created_at
+		FROM posts p
+		JOIN users u ON p.created_by = u.user_id
+		WHERE p.topic_id = $1
+		ORDER BY p.created_at ASC, p.post_id ASC`
+
+	rows, err := repo.DB.Query(ctx, query, topicID)
+	if err != nil {
+		return fmt.Errorf("failed to query posts: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		post := Post{TopicID: topicID}
+
+		err := rows.Scan(
+			&post.PostID,
+			&post.Title,
+			&post.Username,
+			&post.VoteCount,
+			&post.CreatedAt,
+		)
+
+		if err != nil {
+			return fmt.Errorf("failed to scan post row: %w", err)
+		}
+
+		if err := fn(&post); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error encountered during row iteration: %w", err)
+	}
+
+	return nil
+}
+
+// GetPostByID fetches a specific post by its ID
+func (repo *Repository) GetPostByID(postID int, userID *int) (*Post, error) {
+	return repo.getPostByID(postID, userID, false)
+}
+
+// GetPostWithTopic fetches a specific post by its ID, with its topic's ID, title and slug embedded as Post.Topic
+func (repo *Repository) GetPostWithTopic(postID int, userID *int) (*Post, error) {
+	return repo.getPostByID(postID, userID, true)
+}
+
+// getPostByID fetches a post, embedding its topic if includeTopic is set (the topic is joined either way)
+func (repo *Repository) getPostByID(postID int, userID *int, includeTopic bool) (*Post, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var post Post
+	var topicSlug string
+	query := `
+		SELECT 
+			p.post_id, 
+			p.topic_id,
+			t.title as topic_title, 
+			COALESCE(t.slug, '') as topic_slug,
+			p.title, 
 			p.content, 
 			p.created_by, 
 			u.username, 
 			p.created_at, 
 			p.updated_at,
+			GREATEST(p.updated_at, p.votes_changed_at) AS modified_at,
 			p.vote_count,
+			v.upvotes,
+			v.downvotes,
+			p.pinned,
+			p.locked,
 			CASE
 				WHEN $2::integer IS NOT NULL THEN (
 					SELECT vote_type FROM votes
@@ -301,37 +804,204 @@ func (repo *Repository) GetPostByID(postID int, userID *int) (*Post, error) {
 		FROM posts p
 		JOIN users u ON p.created_by = u.user_id
 		JOIN topics t ON p.topic_id = t.topic_id
+		CROSS JOIN LATERAL (
+			SELECT
+				COUNT(*) FILTER (WHERE vote_type = 1) AS upvotes,
+				COUNT(*) FILTER (WHERE vote_type = -1) AS downvotes
+			FROM votes
+			WHERE post_id = p.post_id
+		) v
 		WHERE p.post_id = $1`
 
 	err := repo.DB.QueryRow(ctx, query, postID, userID).Scan(
 		&post.PostID,
 		&post.TopicID,
 		&post.TopicTitle,
+		&topicSlug,
 		&post.Title,
 		&post.Content,
 		&post.CreatedBy,
 		&post.Username,
 		&post.CreatedAt,
 		&post.UpdatedAt,
+		&post.ModifiedAt,
 		&post.VoteCount,
+		&post.Upvotes,
+		&post.Downvotes,
+		&post.Pinned,
+		&post.Locked,
 		&post.UserVote,
 	)
 
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			return nil, fmt.Errorf("post not found with ID: %d", postID)
+			return nil, fmt.Errorf("%w with ID: %d", ErrPostNotFound, postID)
 		}
 		return nil, fmt.Errorf("query to find post failed: %w", err)
 	}
 
+	if includeTopic {
+		post.Topic = &PostTopic{
+			TopicID: post.TopicID,
+			Title:   post.TopicTitle,
+			Slug:    topicSlug,
+		}
+	}
+
 	return &post, nil
 }
 
-// GetCommentsByPostID fetches all comments for a given post ID
-func (repo *Repository) GetCommentsByPostID(postID int, userID *int) ([]*Comment, error) {
+// PostExists checks whether a post with the given ID exists
+// Lighter than GetPostByID as no columns or joins are fetched
+func (repo *Repository) PostExists(postID int) (bool, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var exists bool
+	query := `SELECT EXISTS (SELECT 1 FROM posts WHERE post_id = $1)`
+
+	err := repo.DB.QueryRow(ctx, query, postID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check post existence: %w", err)
+	}
+
+	return exists, nil
+}
+
+// CountPostsInTopic returns the number of posts in a topic
+func (repo *Repository) CountPostsInTopic(topicID int) (int, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var count int
+	query := `SELECT COUNT(*) FROM posts WHERE topic_id = $1`
+
+	err := repo.DB.QueryRow(ctx, query, topicID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("count posts in topic failed: %w", err)
+	}
+
+	return count, nil
+}
+
+// CountUserTopicsWithin returns the number of topics the user has created in the trailing window
+// The window is measured against the database clock, like created_at itself
+func (repo *Repository) CountUserTopicsWithin(userID int, window time.Duration) (int, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var count int
+	query := `
+		SELECT COUNT(*) FROM topics
+		WHERE created_by = $1
+		AND created_at > NOW() - ($2 * INTERVAL '1 second')`
+
+	err := repo.DB.QueryRow(ctx, query, userID, window.Seconds()).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("count user topics failed: %w", err)
+	}
+
+	return count, nil
+}
+
+// CountCommentsByPostID returns the number of comments on a post (0 if it has none)
+func (repo *Repository) CountCommentsByPostID(postID int) (int, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var count int
+	query := `SELECT COUNT(*) FROM comments WHERE post_id = $1`
+
+	err := repo.DB.QueryRow(ctx, query, postID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("count comments on post failed: %w", err)
+	}
+
+	return count, nil
+}
+
+// PostBelongsToTopic checks whether a post with the given ID exists under the given topic
+func (repo *Repository) PostBelongsToTopic(postID, topicID int) (bool, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var belongs bool
+	query := `SELECT EXISTS (SELECT 1 FROM posts WHERE post_id = $1 AND topic_id = $2)`
+
+	err := repo.DB.QueryRow(ctx, query, postID, topicID).Scan(&belongs)
+	if err != nil {
+		return false, fmt.Errorf("failed to check post topic: %w", err)
+	}
+
+	return belongs, nil
+}
+
+// CommentBelongsToPost checks whether a comment with the given ID exists on the given post and has not been soft-deleted
+func (repo *Repository) CommentBelongsToPost(commentID, postID int) (bool, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var belongs bool
+	query := `SELECT EXISTS (SELECT 1 FROM comments WHERE comment_id = $1 AND post_id = $2 AND deleted_at IS NULL)`
+
+	err := repo.DB.QueryRow(ctx, query, commentID, postID).Scan(&belongs)
+	if err != nil {
+		return false, fmt.Errorf("failed to check comment post: %w", err)
+	}
+
+	return belongs, nil
+}
+
+// IsPostLocked checks whether a post is locked against new comments
+func (repo *Repository) IsPostLocked(postID int) (bool, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var locked bool
+	query := `SELECT locked FROM posts WHERE post_id = $1`
+
+	err := repo.DB.QueryRow(ctx, query, postID).Scan(&locked)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return false, fmt.Errorf("%w with ID: %d", ErrPostNotFound, postID)
+		}
+		return false, fmt.Errorf("failed to check post lock: %w", err)
+	}
+
+	return locked, nil
+}
+
+// Comment sort orders accepted by GetCommentsByPostID
+const (
+	CommentSortNew = "new" // Newest first
+	CommentSortOld = "old" // Oldest first (chronological reading order)
+	CommentSortTop = "top" // Highest vote count first
+)
+
+// commentSortOrders maps each comment sort order to its ORDER BY clause
+// Comment ID breaks ties so the order is stable
+var commentSortOrders = map[string]string{
+	CommentSortNew: "c.created_at DESC, c.comment_id DESC",
+	CommentSortOld: "c.created_at ASC, c.comment_id ASC",
+	CommentSortTop: "c.vote_count DESC, c.created_at ASC, c.comment_id ASC",
+}
+
+// IsValidCommentSort reports whether sort is a supported comment sort order
+func IsValidCommentSort(sort string) bool {
+	_, ok := commentSortOrders[sort]
+	return ok
+}
+
+// GetCommentsByPostID fetches all comments for a given post ID in the given sort order
+func (repo *Repository) GetCommentsByPostID(postID int, userID *int, sort string) ([]*Comment, error) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	orderBy, ok := commentSortOrders[sort]
+	if !ok {
+		return nil, fmt.Errorf("invalid comment sort: %s", sort)
+	}
+
 	query := `
 		SELECT 
 			c.comment_id, 
@@ -342,6 +1012,8 @@ func (repo *Repository) GetCommentsByPostID(postID int, userID *int) ([]*Comment
 			c.created_at, 
 			c.updated_at,
 			c.vote_count,
+			c.quoted_comment_id,
+			c.deleted_at,
 			CASE
 				WHEN $2::integer IS NOT NULL THEN (
 					SELECT vote_type FROM votes
@@ -352,9 +1024,9 @@ func (repo *Repository) GetCommentsByPostID(postID int, userID *int) ([]*Comment
 		FROM comments c
 		JOIN users u ON c.created_by = u.user_id
 		WHERE c.post_id = $1
-		ORDER BY c.created_at DESC`
+		ORDER BY ` + orderBy
 
-	rows, err := repo.DB.Query(ctx, query, postID, userID)
+	rows, err := repo.reader().Query(ctx, query, postID, userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query comments: %w", err)
 	}
@@ -373,6 +1045,8 @@ func (repo *Repository) GetCommentsByPostID(postID int, userID *int) ([]*Comment
 			&comment.CreatedAt,
 			&comment.UpdatedAt,
 			&comment.VoteCount,
+			&comment.QuotedCommentID,
+			&comment.DeletedAt,
 			&comment.UserVote,
 		)
 
@@ -390,6 +1064,106 @@ func (repo *Repository) GetCommentsByPostID(postID int, userID *int) ([]*Comment
 	return comments, nil
 }
 
+// GetCommentsByPostIDPaginated fetches up to limit comments on a post, oldest first, after the keyset position (afterCreatedAt, afterID)
+// A zero afterCreatedAt starts from the first comment; comments added meanwhile never shift later pages
+func (repo *Repository) GetCommentsByPostIDPaginated(postID int, userID *int, afterCreatedAt time.Time, afterID, limit int) (*PagedResponse[*Comment], error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Get total count
+	var total int
+	countQuery := `SELECT COUNT(*) FROM comments WHERE post_id = $1`
+	err := repo.reader().QueryRow(ctx, countQuery, postID).Scan(&total)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count comments: %w", err)
+	}
+
+	// Fetch one extra row to determine whether another page exists
+	query := `
+		SELECT 
+			c.comment_id, 
+			c.post_id, 
+			c.content, 
+			c.created_by, 
+			u.username, 
+			c.created_at, 
+			c.updated_at,
+			c.vote_count,
+			c.quoted_comment_id,
+			c.deleted_at,
+			CASE
+				WHEN $2::integer IS NOT NULL THEN (
+					SELECT vote_type FROM votes
+					WHERE user_id = $2 AND comment_id = c.comment_id
+				)
+				ELSE NULL
+			END AS user_vote
+		FROM comments c
+		JOIN users u ON c.created_by = u.user_id
+		WHERE c.post_id = $1
+		AND ($3::timestamp IS NULL OR (c.created_at, c.comment_id) > ($3::timestamp, $4::integer))
+		ORDER BY c.created_at ASC, c.comment_id ASC
+		LIMIT $5`
+
+	var after *time.Time
+	if !afterCreatedAt.IsZero() {
+		after = &afterCreatedAt
+	}
+
+	rows, err := repo.reader().Query(ctx, query, postID, userID, after, afterID, limit+1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query comments: %w", err)
+	}
+	defer rows.Close()
+
+	comments := []*Comment{}
+	for rows.Next() {
+		var comment Comment
+
+		err := rows.Scan(
+			&comment.CommentID,
+			&comment.PostID,
+			&comment.Content,
+			&comment.CreatedBy,
+			&comment.Username,
+			&comment.CreatedAt,
+			&comment.UpdatedAt,
+			&comment.VoteCount,
+			&comment.QuotedCommentID,
+			&comment.DeletedAt,
+			&comment.UserVote,
+		)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan comment row: %w", err)
+		}
+
+		comments = append(comments, &comment)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error encountered during row iteration: %w", err)
+	}
+
+	page := &PagedResponse[*Comment]{
+		Items: comments,
+		Total: total,
+		Limit: limit,
+	}
+
+	// Unlike newPagedResponse, the next cursor is the position of the last comment returned rather than an offset
+	if len(comments) > limit {
+		page.Items = comments[:limit]
+		page.HasMore = true
+
+		last := page.Items[limit-1]
+		nextCursor := EncodeCommentCursor(last.CreatedAt, last.CommentID)
+		page.NextCursor = &nextCursor
+	}
+
+	return page, nil
+}
+
 // GetCommentByID fetches a specific comment by its ID
 func (repo *Repository) GetCommentByID(commentID int, userID *int) (*Comment, error) {
 	ctx, cancel := context.WithCancel(context.Background())
@@ -407,6 +1181,8 @@ func (repo *Repository) GetCommentByID(commentID int, userID *int) (*Comment, er
 			c.created_at,
 			c.updated_at,
 			c.vote_count,
+			c.quoted_comment_id,
+			c.deleted_at,
 			CASE
 				WHEN $2::integer IS NOT NULL THEN (
 					SELECT vote_type FROM votes
@@ -429,6 +1205,8 @@ func (repo *Repository) GetCommentByID(commentID int, userID *int) (*Comment, er
 		&comment.CreatedAt,
 		&comment.UpdatedAt,
 		&comment.VoteCount,
+		&comment.QuotedCommentID,
+		&comment.DeletedAt,
 		&comment.UserVote,
 	)
 
@@ -492,15 +1270,41 @@ func (repo *Repository) CreatePost(topicID int, title, content string, userID in
 	return &post, nil
 }
 
+// IsDuplicateComment reports whether the user's most recent comment on the post,
+// made within the given window, has exactly the given content
+func (repo *Repository) IsDuplicateComment(postID, userID int, content string, window time.Duration) (bool, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var duplicate bool
+	query := `
+		SELECT COALESCE((
+			SELECT content = $3
+			FROM comments
+			WHERE post_id = $1 AND created_by = $2
+			AND created_at > NOW() - ($4 * INTERVAL '1 second')
+			ORDER BY created_at DESC
+			LIMIT 1
+		), FALSE)`
+
+	err := repo.DB.QueryRow(ctx, query, postID, userID, content, window.Seconds()).Scan(&duplicate)
+	if err != nil {
+		return false, fmt.Errorf("failed to check for duplicate comment: %w", err)
+	}
+
+	return duplicate, nil
+}
+
 // CreateComment inserts a new comment into the database
-func (repo *Repository) CreateComment(postID int, content string, userID int) (*Comment, error) {
+// quotedCommentID is optional (nil if the comment quotes nothing)
+func (repo *Repository) CreateComment(postID int, content string, userID int, quotedCommentID *int) (*Comment, error) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
 	query := `
-		INSERT INTO comments (post_id, content, created_by)
-		VALUES ($1, $2, $3)
-		RETURNING comment_id, post_id, content, created_by, created_at, updated_at`
+		INSERT INTO comments (post_id, content, created_by, quoted_comment_id)
+		VALUES ($1, $2, $3, $4)
+		RETURNING comment_id, post_id, content, created_by, created_at, updated_at, quoted_comment_id`
 
 	var comment Comment
 	err := repo.DB.QueryRow(
@@ -509,6 +1313,7 @@ func (repo *Repository) CreateComment(postID int, content string, userID int) (*
 		postID,
 		content,
 		userID,
+		quotedCommentID,
 	).Scan(
 		&comment.CommentID,
 		&comment.PostID,
@@ -516,6 +1321,7 @@ func (repo *Repository) CreateComment(postID int, content string, userID int) (*
 		&comment.CreatedBy,
 		&comment.CreatedAt,
 		&comment.UpdatedAt,
+		&comment.QuotedCommentID,
 	)
 
 	if err != nil {
@@ -547,9 +1353,10 @@ func (repo *Repository) UpdateTopic(topicID int, title, description string, user
 
 	// Verify that topic exists and was created by the user
 	var creatorID int
+	var currentTitle string
 
 	checkQuery := `
-		SELECT created_by
+		SELECT created_by, title
 		FROM topics
 		WHERE topic_id = $1`
 
@@ -557,7 +1364,7 @@ func (repo *Repository) UpdateTopic(topicID int, title, description string, user
 		ctx,
 		checkQuery,
 		topicID,
-	).Scan(&creatorID)
+	).Scan(&creatorID, &currentTitle)
 
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -571,14 +1378,15 @@ func (repo *Repository) UpdateTopic(topicID int, title, description string, user
 		return nil, fmt.Errorf("user %d is not authorized to update topic %d", userID, topicID)
 	}
 
-	// Update topic
+	// Update topic, regenerating the slug if the title changed (a nil slug keeps the current one)
 	query := `
 		UPDATE topics
-		SET title = $1, description = $2, updated_at = NOW()
+		SET title = $1, description = $2, slug = COALESCE($5, slug), updated_at = NOW()
 		WHERE topic_id = $3 AND created_by = $4
 		RETURNING 
 			topic_id, 
 			title, 
+			COALESCE(slug, ''),
 			description, 
 			created_by, 
 			(SELECT username FROM users WHERE user_id = $4) AS username,
@@ -586,66 +1394,275 @@ func (repo *Repository) UpdateTopic(topicID int, title, description string, user
 			updated_at`
 
 	var updatedTopic Topic
-	err = repo.DB.QueryRow(
-		ctx,
-		query,
-		title,
-		description,
-		topicID,
-		userID,
-	).Scan(
-		&updatedTopic.TopicID,
-		&updatedTopic.Title,
-		&updatedTopic.Description,
-		&updatedTopic.CreatedBy,
-		&updatedTopic.Username,
-		&updatedTopic.CreatedAt,
-		&updatedTopic.UpdatedAt,
-	)
+	for attempt := 1; ; attempt++ {
+		var slug *string
+		if title != currentTitle {
+			newSlug, err := repo.uniqueTopicSlug(ctx, Slugify(title), topicID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to update topic: %w", err)
+			}
+			slug = &newSlug
+		}
 
-	if err != nil {
-		return nil, fmt.Errorf("failed to update topic: %w", err)
+		err = repo.DB.QueryRow(
+			ctx,
+			query,
+			title,
+			description,
+			topicID,
+			userID,
+			slug,
+		).Scan(
+			&updatedTopic.TopicID,
+			&updatedTopic.Title,
+			&updatedTopic.Slug,
+			&updatedTopic.Description,
+			&updatedTopic.CreatedBy,
+			&updatedTopic.Username,
+			&updatedTopic.CreatedAt,
+			&updatedTopic.UpdatedAt,
+		)
+
+		if err == nil {
+			break
+		}
+
+		// Row was deleted between the ownership check and the update
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("topic with ID %d not found", topicID)
+		}
+
+		// A concurrent write claimed the new slug first, so try the next free one
+		if !isTopicSlugConflict(err) || attempt == maxSlugAttempts {
+			return nil, fmt.Errorf("failed to update topic: %w", err)
+		}
 	}
 
 	return &updatedTopic, nil
 }
 
-// UpdatePost updates an existing post's title and content
-func (repo *Repository) UpdatePost(postID int, title, content string, userID int) (*Post, error) {
+// PatchTopic updates only the provided (non-nil) fields of an existing topic
+func (repo *Repository) PatchTopic(topicID int, title, description *string, userID int) (*Topic, error) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	// Verify that post exists and was created by the user
+	// Verify that topic exists and was created by the user
 	var creatorID int
+	var currentTitle string
 
 	checkQuery := `
-		SELECT created_by
-		FROM posts
-		WHERE post_id = $1`
+		SELECT created_by, title
+		FROM topics
+		WHERE topic_id = $1`
 
 	err := repo.DB.QueryRow(
 		ctx,
 		checkQuery,
-		postID,
-	).Scan(&creatorID)
+		topicID,
+	).Scan(&creatorID, &currentTitle)
 
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			return nil, fmt.Errorf("post with ID %d not found", postID)
+			return nil, fmt.Errorf("topic with ID %d not found", topicID)
 		}
 
-		return nil, fmt.Errorf("failed to verify post ownership: %w", err)
+		return nil, fmt.Errorf("failed to verify topic ownership: %w", err)
 	}
 
 	if creatorID != userID {
-		return nil, fmt.Errorf("user %d is not authorized to update post %d", userID, postID)
+		return nil, fmt.Errorf("user %d is not authorized to update topic %d", userID, topicID)
 	}
 
-	// Update post
-	query := `
-		UPDATE posts
-		SET title = $1, content = $2, updated_at = NOW()
-		WHERE post_id = $3 AND created_by = $4
+	// Build SET clause from provided fields
+	var setClauses []string
+	var args []any
+
+	slugArg := -1 // Index of the slug in args, if the title changed
+	if title != nil {
+		args = append(args, *title)
+		setClauses = append(setClauses, fmt.Sprintf("title = $%d", len(args)))
+
+		if *title != currentTitle {
+			args = append(args, nil) // Filled in below
+			slugArg = len(args) - 1
+			setClauses = append(setClauses, fmt.Sprintf("slug = $%d", len(args)))
+		}
+	}
+
+	if description != nil {
+		args = append(args, *description)
+		setClauses = append(setClauses, fmt.Sprintf("description = $%d", len(args)))
+	}
+
+	setClauses = append(setClauses, "updated_at = NOW()")
+	args = append(args, topicID, userID)
+
+	// Update topic
+	query := fmt.Sprintf(`
+		UPDATE topics
+		SET %s
+		WHERE topic_id = $%d AND created_by = $%d
+		RETURNING 
+			topic_id, 
+			title, 
+			COALESCE(slug, ''),
+			description, 
+			created_by, 
+			(SELECT username FROM users WHERE user_id = $%d) AS username,
+			created_at, 
+			updated_at`,
+		strings.Join(setClauses, ", "),
+		len(args)-1,
+		len(args),
+		len(args),
+	)
+
+	var updatedTopic Topic
+	for attempt := 1; ; attempt++ {
+		if slugArg >= 0 {
+			slug, err := repo.uniqueTopicSlug(ctx, Slugify(*title), topicID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to update topic: %w", err)
+			}
+			args[slugArg] = slug
+		}
+
+		err = repo.DB.QueryRow(
+			ctx,
+			query,
+			args...,
+		).Scan(
+			&updatedTopic.TopicID,
+			&updatedTopic.Title,
+			&updatedTopic.Slug,
+			&updatedTopic.Description,
+			&updatedTopic.CreatedBy,
+			&updatedTopic.Username,
+			&updatedTopic.CreatedAt,
+			&updatedTopic.UpdatedAt,
+		)
+
+		if err == nil {
+			break
+		}
+
+		// Row was deleted between the ownership check and the update
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("topic with ID %d not found", topicID)
+		}
+
+		// A concurrent write claimed the new slug first, so try the next free one
+		if !isTopicSlugConflict(err) || attempt == maxSlugAttempts {
+			return nil, fmt.Errorf("failed to update topic: %w", err)
+		}
+	}
+
+	return &updatedTopic, nil
+}
+
+// UpdateTopicDescription updates only an existing topic's description, leaving its title and slug unchanged
+func (repo *Repository) UpdateTopicDescription(topicID int, description string, userID int) (*Topic, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Verify that topic exists and was created by the user
+	var creatorID int
+
+	checkQuery := `
+		SELECT created_by
+		FROM topics
+		WHERE topic_id = $1`
+
+	err := repo.DB.QueryRow(ctx, checkQuery, topicID).Scan(&creatorID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("topic with ID %d not found", topicID)
+		}
+
+		return nil, fmt.Errorf("failed to verify topic ownership: %w", err)
+	}
+
+	if creatorID != userID {
+		return nil, fmt.Errorf("user %d is not authorized to update topic %d", userID, topicID)
+	}
+
+	// Update description
+	query := `
+		UPDATE topics
+		SET description = $1, updated_at = NOW()
+		WHERE topic_id = $2 AND created_by = $3
+		RETURNING
+			topic_id,
+			title,
+			COALESCE(slug, ''),
+			description,
+			created_by,
+			(SELECT username FROM users WHERE user_id = $3) AS username,
+			created_at,
+			updated_at,
+			pinned`
+
+	var updatedTopic Topic
+	err = repo.DB.QueryRow(ctx, query, description, topicID, userID).Scan(
+		&updatedTopic.TopicID,
+		&updatedTopic.Title,
+		&updatedTopic.Slug,
+		&updatedTopic.Description,
+		&updatedTopic.CreatedBy,
+		&updatedTopic.Username,
+		&updatedTopic.CreatedAt,
+		&updatedTopic.UpdatedAt,
+		&updatedTopic.Pinned,
+	)
+
+	if err != nil {
+		// Row was deleted between the ownership check and the update
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("topic with ID %d not found", topicID)
+		}
+
+		return nil, fmt.Errorf("failed to update topic description: %w", err)
+	}
+
+	return &updatedTopic, nil
+}
+
+// UpdatePost updates an existing post's title and content
+func (repo *Repository) UpdatePost(postID int, title, content string, userID int) (*Post, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Verify that post exists and was created by the user
+	var creatorID int
+
+	checkQuery := `
+		SELECT created_by
+		FROM posts
+		WHERE post_id = $1`
+
+	err := repo.DB.QueryRow(
+		ctx,
+		checkQuery,
+		postID,
+	).Scan(&creatorID)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("post with ID %d not found", postID)
+		}
+
+		return nil, fmt.Errorf("failed to verify post ownership: %w", err)
+	}
+
+	if creatorID != userID {
+		return nil, fmt.Errorf("user %d is not authorized to update post %d", userID, postID)
+	}
+
+	// Update post
+	query := `
+		UPDATE posts
+		SET title = $1, content = $2, updated_at = NOW()
+		WHERE post_id = $3 AND created_by = $4
 		RETURNING 
 			post_id, 
 			topic_id, 
@@ -676,6 +1693,107 @@ func (repo *Repository) UpdatePost(postID int, title, content string, userID int
 	)
 
 	if err != nil {
+		// Row was deleted between the ownership check and the update
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("post with ID %d not found", postID)
+		}
+
+		return nil, fmt.Errorf("failed to update post: %w", err)
+	}
+
+	return &updatedPost, nil
+}
+
+// PatchPost updates only the provided (non-nil) fields of an existing post
+func (repo *Repository) PatchPost(postID int, title, content *string, userID int) (*Post, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Verify that post exists and was created by the user
+	var creatorID int
+
+	checkQuery := `
+		SELECT created_by
+		FROM posts
+		WHERE post_id = $1`
+
+	err := repo.DB.QueryRow(
+		ctx,
+		checkQuery,
+		postID,
+	).Scan(&creatorID)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("post with ID %d not found", postID)
+		}
+
+		return nil, fmt.Errorf("failed to verify post ownership: %w", err)
+	}
+
+	if creatorID != userID {
+		return nil, fmt.Errorf("user %d is not authorized to update post %d", userID, postID)
+	}
+
+	// Build SET clause from provided fields
+	var setClauses []string
+	var args []any
+
+	if title != nil {
+		args = append(args, *title)
+		setClauses = append(setClauses, fmt.Sprintf("title = $%d", len(args)))
+	}
+
+	if content != nil {
+		args = append(args, *content)
+		setClauses = append(setClauses, fmt.Sprintf("content = $%d", len(args)))
+	}
+
+	setClauses = append(setClauses, "updated_at = NOW()")
+	args = append(args, postID, userID)
+
+	// Update post
+	query := fmt.Sprintf(`
+		UPDATE posts
+		SET %s
+		WHERE post_id = $%d AND created_by = $%d
+		RETURNING 
+			post_id, 
+			topic_id, 
+			title, 
+			content, 
+			created_by, 
+			(SELECT username FROM users WHERE user_id = $%d) AS username,
+			created_at, 
+			updated_at`,
+		strings.Join(setClauses, ", "),
+		len(args)-1,
+		len(args),
+		len(args),
+	)
+
+	var updatedPost Post
+	err = repo.DB.QueryRow(
+		ctx,
+		query,
+		args...,
+	).Scan(
+		&updatedPost.PostID,
+		&updatedPost.TopicID,
+		&updatedPost.Title,
+		&updatedPost.Content,
+		&updatedPost.CreatedBy,
+		&updatedPost.Username,
+		&updatedPost.CreatedAt,
+		&updatedPost.UpdatedAt,
+	)
+
+	if err != nil {
+		// Row was deleted between the ownership check and the update
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("post with ID %d not found", postID)
+		}
+
 		return nil, fmt.Errorf("failed to update post: %w", err)
 	}
 
@@ -687,13 +1805,13 @@ func (repo *Repository) UpdateComment(commentID int, content string, userID int)
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	// Verify that comment exists and was created by the user
+	// Verify that comment exists (soft-deleted comments count as gone) and was created by the user
 	var creatorID int
 
 	checkQuery := `
 		SELECT created_by
 		FROM comments
-		WHERE comment_id = $1`
+		WHERE comment_id = $1 AND deleted_at IS NULL`
 
 	err := repo.DB.QueryRow(
 		ctx,
@@ -717,7 +1835,7 @@ func (repo *Repository) UpdateComment(commentID int, content string, userID int)
 	query := `
 		UPDATE comments
 		SET content = $1, updated_at = NOW()
-		WHERE comment_id = $2 AND created_by = $3
+		WHERE comment_id = $2 AND created_by = $3 AND deleted_at IS NULL
 		RETURNING 
 			comment_id, 
 			post_id, 
@@ -725,7 +1843,8 @@ func (repo *Repository) UpdateComment(commentID int, content string, userID int)
 			created_by, 
 			(SELECT username FROM users WHERE user_id = $3) AS username,
 			created_at, 
-			updated_at`
+			updated_at,
+			quoted_comment_id`
 
 	var updatedComment Comment
 	err = repo.DB.QueryRow(
@@ -742,197 +1861,907 @@ func (repo *Repository) UpdateComment(commentID int, content string, userID int)
 		&updatedComment.Username,
 		&updatedComment.CreatedAt,
 		&updatedComment.UpdatedAt,
+		&updatedComment.QuotedCommentID,
 	)
 
 	if err != nil {
-		return nil, fmt.Errorf("failed to update comment: %w", err)
+		// Row was deleted between the ownership check and the update
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("comment with ID %d not found", commentID)
+		}
+
+		return nil, fmt.Errorf("failed to update comment: %w", err)
+	}
+
+	return &updatedComment, nil
+}
+
+// SetTopicPinned pins or unpins a topic (advancing updated_at, so conditional GETs see the change)
+func (repo *Repository) SetTopicPinned(topicID int, pinned bool) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	query := `
+		UPDATE topics
+		SET pinned = $1, updated_at = NOW()
+		WHERE topic_id = $2`
+
+	commandTag, err := repo.DB.Exec(ctx, query, pinned, topicID)
+	if err != nil {
+		return fmt.Errorf("failed to set topic pinned: %w", err)
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: %d", ErrTopicNotFound, topicID)
+	}
+
+	return nil
+}
+
+// SetPostPinned pins or unpins a post within its topic (advancing updated_at, so conditional GETs see the change)
+func (repo *Repository) SetPostPinned(postID int, pinned bool) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	query := `
+		UPDATE posts
+		SET pinned = $1, updated_at = NOW()
+		WHERE post_id = $2`
+
+	commandTag, err := repo.DB.Exec(ctx, query, pinned, postID)
+	if err != nil {
+		return fmt.Errorf("failed to set post pinned: %w", err)
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return fmt.Errorf("%w with ID: %d", ErrPostNotFound, postID)
+	}
+
+	return nil
+}
+
+// TogglePostLock flips the locked flag of a post (advancing updated_at, so conditional GETs see the change)
+// Only the post's creator or an admin (isAdmin) may lock or unlock it
+func (repo *Repository) TogglePostLock(postID, userID int, isAdmin bool) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	tx, err := repo.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx) // No-op once committed
+
+	// Verify that post exists and the user may lock it
+	var creatorID int
+
+	checkQuery := `
+		SELECT created_by
+		FROM posts
+		WHERE post_id = $1
+		FOR UPDATE`
+
+	err = tx.QueryRow(ctx, checkQuery, postID).Scan(&creatorID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return fmt.Errorf("post with ID %d not found", postID)
+		}
+
+		return fmt.Errorf("failed to verify post ownership: %w", err)
+	}
+
+	if creatorID != userID && !isAdmin {
+		return fmt.Errorf("user %d is not authorized to lock post %d", userID, postID)
+	}
+
+	query := `
+		UPDATE posts
+		SET locked = NOT locked, updated_at = NOW()
+		WHERE post_id = $1`
+
+	_, err = tx.Exec(ctx, query, postID)
+	if err != nil {
+		return fmt.Errorf("failed to toggle post lock: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit post lock: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteComment deletes an existing comment
+// A comment quoted by replies is soft-deleted instead (content replaced with DeletedCommentContent and deleted_at set)
+// so the replies keep their quote
+func (repo *Repository) DeleteComment(commentID, userID int) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Delete and audit atomically
+	tx, err := repo.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx) // No-op once committed
+
+	// Verify that comment exists and was created by the user, and check whether any reply quotes it
+	var creatorID, postID int
+	var hasReplies bool
+
+	checkQuery := `
+		SELECT
+			created_by,
+			post_id,
+			EXISTS (SELECT 1 FROM comments r WHERE r.quoted_comment_id = c.comment_id)
+		FROM comments c
+		WHERE comment_id = $1
+		FOR UPDATE`
+
+	err = tx.QueryRow(
+		ctx,
+		checkQuery,
+		commentID,
+	).Scan(&creatorID, &postID, &hasReplies)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return fmt.Errorf("comment with ID %d not found", commentID)
+		}
+
+		return fmt.Errorf("failed to verify comment ownership: %w", err)
+	}
+
+	if creatorID != userID {
+		return fmt.Errorf("user %d is not authorized to delete comment %d", userID, commentID)
+	}
+
+	var query string
+	if hasReplies {
+		// Keep the row so replies still point at it (deleting it again keeps the first deletion time)
+		query = `
+			UPDATE comments
+			SET content = '` + DeletedCommentContent + `', deleted_at = COALESCE(deleted_at, NOW())
+			WHERE comment_id = $1 AND created_by = $2`
+	} else {
+		// Delete comment (votes on it are deleted via ON DELETE CASCADE)
+		query = `
+			DELETE FROM comments
+			WHERE comment_id = $1 AND created_by = $2`
+	}
+
+	commandTag, err := tx.Exec(
+		ctx,
+		query,
+		commentID,
+		userID,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to delete comment: %w", err)
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return fmt.Errorf("comment with ID %d not found or not owned by user %d", commentID, userID)
+	}
+
+	// Record deletion in audit log
+	err = insertAuditLog(ctx, tx, userID, AuditActionDelete, "comment", commentID, map[string]any{
+		"ownerUserID": creatorID,
+		"postID":      postID,
+		"soft":        hasReplies,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit comment deletion: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteCommentsAsAdmin deletes the given comments regardless of owner, returning how many existed and were deleted
+// Each deleted comment is recorded in the audit log within the same transaction
+func (repo *Repository) DeleteCommentsAsAdmin(commentIDs []int, adminID int) (int, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Delete and audit atomically
+	tx, err := repo.DB.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx) // No-op once committed
+
+	query := `
+		DELETE FROM comments
+		WHERE comment_id = ANY($1)
+		RETURNING comment_id, created_by, post_id`
+
+	rows, err := tx.Query(ctx, query, commentIDs)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete comments: %w", err)
+	}
+
+	// Collect deleted rows first, as the transaction cannot run other queries while rows are open
+	type deletedComment struct {
+		commentID, creatorID, postID int
+	}
+
+	var deleted []deletedComment
+	for rows.Next() {
+		var d deletedComment
+		if err := rows.Scan(&d.commentID, &d.creatorID, &d.postID); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("error scanning deleted comment: %w", err)
+		}
+		deleted = append(deleted, d)
+	}
+	rows.Close()
+
+	if err = rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to delete comments: %w", err)
+	}
+
+	// Record each deletion in audit log
+	for _, d := range deleted {
+		err = insertAuditLog(ctx, tx, adminID, AuditActionDelete, "comment", d.commentID, map[string]any{
+			"ownerUserID": d.creatorID,
+			"postID":      d.postID,
+			"bulk":        true,
+		})
+
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit comment deletion: %w", err)
+	}
+
+	return len(deleted), nil
+}
+
+// DeletePost deletes an existing post and its comments
+func (repo *Repository) DeletePost(postID, userID int) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Delete and audit atomically
+	tx, err := repo.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx) // No-op once committed
+
+	// Verify that post exists and was created by the user
+	var creatorID, topicID int
+	var title string
+
+	checkQuery := `
+		SELECT created_by, topic_id, title
+		FROM posts
+		WHERE post_id = $1
+		FOR UPDATE`
+
+	err = tx.QueryRow(
+		ctx,
+		checkQuery,
+		postID,
+	).Scan(&creatorID, &topicID, &title)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return fmt.Errorf("post with ID %d not found", postID)
+		}
+
+		return fmt.Errorf("failed to verify post ownership: %w", err)
+	}
+
+	if creatorID != userID {
+		return fmt.Errorf("user %d is not authorized to delete post %d", userID, postID)
+	}
+
+	// Delete post (its comments, and votes on the post and those comments, are deleted via ON DELETE CASCADE)
+	query := `
+		DELETE FROM posts
+		WHERE post_id = $1 AND created_by = $2`
+
+	_, err = tx.Exec(
+		ctx,
+		query,
+		postID,
+		userID,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to delete post: %w", err)
+	}
+
+	// Record deletion in audit log
+	err = insertAuditLog(ctx, tx, userID, AuditActionDelete, "post", postID, map[string]any{
+		"ownerUserID": creatorID,
+		"topicID":     topicID,
+		"title":       title,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit post deletion: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteTopic deletes an existing topic, including its posts and their comments
+func (repo *Repository) DeleteTopic(topicID, userID int) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Delete and audit atomically
+	tx, err := repo.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx) // No-op once committed
+
+	// Verify that topic exists and was created by the user
+	var creatorID int
+	var title string
+
+	checkQuery := `
+		SELECT created_by, title
+		FROM topics
+		WHERE topic_id = $1
+		FOR UPDATE`
+
+	err = tx.QueryRow(
+		ctx,
+		checkQuery,
+		topicID,
+	).Scan(&creatorID, &title)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return fmt.Errorf("topic with ID %d not found", topicID)
+		}
+
+		return fmt.Errorf("failed to verify topic ownership: %w", err)
+	}
+
+	if creatorID != userID {
+		return fmt.Errorf("user %d is not authorized to delete topic %d", userID, topicID)
+	}
+
+	// Delete topic (all posts and their comments delete automatically via CASCADE)
+	query := `
+		DELETE FROM topics
+		WHERE topic_id = $1 AND created_by = $2`
+
+	_, err = tx.Exec(
+		ctx,
+		query,
+		topicID,
+		userID,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to delete topic: %w", err)
+	}
+
+	// Record deletion in audit log
+	err = insertAuditLog(ctx, tx, userID, AuditActionDelete, "topic", topicID, map[string]any{
+		"ownerUserID": creatorID,
+		"title":       title,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit topic deletion: %w", err)
+	}
+
+	return nil
+}
+
+// GetUserByID fetches user by their unique user ID
+// Used internally when we need to get user details by ID
+func (repo *Repository) GetUserByID(userID int) (*User, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var user User
+	query := `
+		SELECT user_id, username, created_at, updated_at
+		FROM users
+		WHERE user_id = $1`
+
+	err := repo.DB.QueryRow(ctx, query, userID).Scan(
+		&user.UserID,
+		&user.Username,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("%w with ID: %d", ErrUserNotFound, userID)
+		}
+
+		return nil, fmt.Errorf("failed to fetch user: %w", err)
+	}
+
+	return &user, nil
+}
+
+// GetCurrentUser fetches a user's own profile, including private fields such as the last login time
+func (repo *Repository) GetCurrentUser(userID int) (*User, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var user User
+	query := `
+		SELECT user_id, username, email, created_at, updated_at, last_login_at
+		FROM users
+		WHERE user_id = $1`
+
+	err := repo.DB.QueryRow(ctx, query, userID).Scan(
+		&user.UserID,
+		&user.Username,
+		&user.Email,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+		&user.LastLoginAt,
+	)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("user with ID %d not found", userID)
+		}
+
+		return nil, fmt.Errorf("failed to fetch user: %w", err)
+	}
+
+	return &user, nil
+}
+
+// UpdateLastLogin records the current time as a user's last login
+func (repo *Repository) UpdateLastLogin(userID int) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	query := `UPDATE users SET last_login_at = NOW() WHERE user_id = $1`
+
+	result, err := repo.DB.Exec(ctx, query, userID)
+	if err != nil {
+		return fmt.Errorf("failed to update last login: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("user with ID %d not found", userID)
+	}
+
+	return nil
+}
+
+// likePatternEscaper escapes the LIKE wildcards (and the escape character itself) in user input
+var likePatternEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// SearchUsers fetches a page of users whose username starts with prefix (ignoring case), oldest first
+// An empty prefix matches every user
+func (repo *Repository) SearchUsers(prefix string, limit, offset int) (*PagedResponse[*UserSummary], error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pattern := likePatternEscaper.Replace(strings.ToLower(prefix)) + "%"
+
+	// Count all matching users for pagination metadata
+	var total int
+	countQuery := `
+		SELECT COUNT(*)
+		FROM users
+		WHERE LOWER(username) LIKE $1`
+
+	err := repo.reader().QueryRow(ctx, countQuery, pattern).Scan(&total)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count users: %w", err)
+	}
+
+	// Fetch one extra row to determine whether another page exists
+	query := `
+		SELECT
+			u.user_id,
+			u.username,
+			u.is_admin,
+			u.created_at,
+			u.last_login_at,
+			(SELECT COUNT(*) FROM posts p WHERE p.created_by = u.user_id) AS post_count,
+			(SELECT COUNT(*) FROM comments c WHERE c.created_by = u.user_id) AS comment_count
+		FROM users u
+		WHERE LOWER(u.username) LIKE $1
+		ORDER BY u.created_at ASC, u.user_id ASC
+		LIMIT $2 OFFSET $3`
+
+	rows, err := repo.reader().Query(ctx, query, pattern, limit+1, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query users: %w", err)
+	}
+	defer rows.Close()
+
+	users := []*UserSummary{}
+	for rows.Next() {
+		var user UserSummary
+
+		err := rows.Scan(
+			&user.UserID,
+			&user.Username,
+			&user.IsAdmin,
+			&user.CreatedAt,
+			&user.LastLoginAt,
+			&user.PostCount,
+			&user.CommentCount,
+		)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan user row: %w", err)
+		}
+
+		users = append(users, &user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error encountered during row iteration: %w", err)
+	}
+
+	return newPagedResponse(users, total, limit, offset), nil
+}
+
+// UpdateUsername changes a user's username
+// Returns ErrUsernameTaken (wrapped) if another user already has it
+func (repo *Repository) UpdateUsername(userID int, newUsername string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	query := `
+		UPDATE users
+		SET username = $1, updated_at = NOW()
+		WHERE user_id = $2`
+
+	result, err := repo.DB.Exec(ctx, query, newUsername, userID)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" { // unique_violation error code
+			return fmt.Errorf("%w: %s", ErrUsernameTaken, newUsername)
+		}
+
+		return fmt.Errorf("failed to update username: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("user with ID %d not found", userID)
+	}
+
+	return nil
+}
+
+// GetUserKarma sums the votes received on a user's posts and comments
+// Users without any content (or votes) have zero karma
+func (repo *Repository) GetUserKarma(userID int) (postKarma, commentKarma int, err error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	query := `
+		SELECT
+			COALESCE((
+				SELECT SUM(v.vote_type)
+				FROM votes v
+				JOIN posts p ON v.post_id = p.post_id
+				WHERE p.created_by = $1
+			), 0) AS post_karma,
+			COALESCE((
+				SELECT SUM(v.vote_type)
+				FROM votes v
+				JOIN comments c ON v.comment_id = c.comment_id
+				WHERE c.created_by = $1
+			), 0) AS comment_karma`
+
+	err = repo.DB.QueryRow(ctx, query, userID).Scan(&postKarma, &commentKarma)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to calculate user karma: %w", err)
 	}
 
-	return &updatedComment, nil
+	return postKarma, commentKarma, nil
 }
 
-// DeleteComment deletes an existing comment
-func (repo *Repository) DeleteComment(commentID, userID int) error {
+// GetActiveUsers fetches the users who created the most posts and comments within window, most active first
+// Ties are ordered by username; returns an empty slice if nobody was active
+func (repo *Repository) GetActiveUsers(window time.Duration, limit int) ([]*ActiveUser, error) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	// Verify that comment exists and was created by the user
-	var creatorID int
+	query := `
+		SELECT u.user_id, u.username, a.activity_count
+		FROM (
+			SELECT created_by, COUNT(*) AS activity_count
+			FROM (
+				SELECT created_by FROM posts WHERE created_at > NOW() - ($1 * INTERVAL '1 second')
+				UNION ALL
+				SELECT created_by FROM comments WHERE created_at > NOW() - ($1 * INTERVAL '1 second')
+			) activity
+			GROUP BY created_by
+		) a
+		JOIN users u ON u.user_id = a.created_by
+		ORDER BY a.activity_count DESC, u.username
+		LIMIT $2`
+
+	rows, err := repo.DB.Query(ctx, query, window.Seconds(), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query active users: %w", err)
+	}
+	defer rows.Close()
 
-	checkQuery := `
-		SELECT created_by
-		FROM comments
-		WHERE comment_id = $1`
+	users := []*ActiveUser{}
+	for rows.Next() {
+		var user ActiveUser
 
-	err := repo.DB.QueryRow(
-		ctx,
-		checkQuery,
-		commentID,
-	).Scan(&creatorID)
+		err := rows.Scan(&user.UserID, &user.Username, &user.ActivityCount)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan active user row: %w", err)
+		}
+
+		users = append(users, &user)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating active user rows: %w", err)
+	}
+
+	return users, nil
+}
+
+// GetUsernamesByIDs fetches the usernames of the given users, keyed by user ID
+// IDs with no matching user are left out; returns an empty map if none match
+func (repo *Repository) GetUsernamesByIDs(userIDs []int) (map[int]string, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	query := `
+		SELECT user_id, username
+		FROM users
+		WHERE user_id = ANY($1)`
 
+	rows, err := repo.DB.Query(ctx, query, userIDs)
 	if err != nil {
-		if err == pgx.ErrNoRows {
-			return fmt.Errorf("comment with ID %d not found", commentID)
+		return nil, fmt.Errorf("query usernames by IDs failed: %w", err)
+	}
+	defer rows.Close()
+
+	usernames := make(map[int]string, len(userIDs))
+	for rows.Next() {
+		var userID int
+		var username string
+
+		if err := rows.Scan(&userID, &username); err != nil {
+			return nil, fmt.Errorf("error scanning username row: %w", err)
 		}
 
-		return fmt.Errorf("failed to verify comment ownership: %w", err)
+		usernames[userID] = username
 	}
 
-	if creatorID != userID {
-		return fmt.Errorf("user %d is not authorized to delete comment %d", userID, commentID)
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating username rows: %w", err)
 	}
 
-	// Delete comment
-	query := `
-		DELETE FROM comments
-		WHERE comment_id = $1 AND created_by = $2`
+	return usernames, nil
+}
 
-	commandTag, err := repo.DB.Exec(
-		ctx,
-		query,
-		commentID,
-		userID,
-	)
+// GetTrendingTopics fetches the topics with the most posts and comments created within window, most active first
+// Ties are ordered newest topic first; topics without recent activity are excluded
+func (repo *Repository) GetTrendingTopics(window time.Duration, limit int) ([]*TrendingTopic, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 
+	query := `
+		SELECT t.topic_id, t.title, COALESCE(t.slug, ''), a.activity_count
+		FROM (
+			SELECT topic_id, COUNT(*) AS activity_count
+			FROM (
+				SELECT topic_id FROM posts WHERE created_at > NOW() - ($1 * INTERVAL '1 second')
+				UNION ALL
+				SELECT p.topic_id
+				FROM comments c
+				JOIN posts p ON p.post_id = c.post_id
+				WHERE c.created_at > NOW() - ($1 * INTERVAL '1 second')
+			) activity
+			GROUP BY topic_id
+		) a
+		JOIN topics t ON t.topic_id = a.topic_id
+		ORDER BY a.activity_count DESC, t.topic_id DESC
+		LIMIT $2`
+
+	rows, err := repo.reader().Query(ctx, query, window.Seconds(), limit)
 	if err != nil {
-		return fmt.Errorf("failed to delete comment: %w", err)
+		return nil, fmt.Errorf("failed to query trending topics: %w", err)
 	}
+	defer rows.Close()
 
-	if commandTag.RowsAffected() == 0 {
-		return fmt.Errorf("comment with ID %d not found or not owned by user %d", commentID, userID)
+	topics := []*TrendingTopic{}
+	for rows.Next() {
+		var topic TrendingTopic
+
+		err := rows.Scan(&topic.TopicID, &topic.Title, &topic.Slug, &topic.ActivityCount)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan trending topic row: %w", err)
+		}
+
+		topics = append(topics, &topic)
 	}
 
-	return nil
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating trending topic rows: %w", err)
+	}
+
+	return topics, nil
 }
 
-// DeletePost deletes an existing post and its comments
-func (repo *Repository) DeletePost(postID, userID int) error {
+// IsUserAdmin checks whether a user has the admin role
+// Returns false (without error) for users that do not exist
+func (repo *Repository) IsUserAdmin(userID int) (bool, error) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	// Verify that post exists and was created by the user
-	var creatorID int
+	var isAdmin bool
+	query := `SELECT EXISTS (SELECT 1 FROM users WHERE user_id = $1 AND is_admin)`
 
-	checkQuery := `
-		SELECT created_by
-		FROM posts
-		WHERE post_id = $1`
+	err := repo.DB.QueryRow(ctx, query, userID).Scan(&isAdmin)
+	if err != nil {
+		return false, fmt.Errorf("failed to check admin role: %w", err)
+	}
 
-	err := repo.DB.QueryRow(
-		ctx,
-		checkQuery,
-		postID,
-	).Scan(&creatorID)
+	return isAdmin, nil
+}
+
+// BanUser suspends a user until the given time (permanently if until is nil) and records it in the audit log
+// Banning an already banned user replaces the previous ban
+func (repo *Repository) BanUser(userID int, until *time.Time, adminID int) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 
+	// Ban and audit atomically
+	tx, err := repo.DB.Begin(ctx)
 	if err != nil {
-		if err == pgx.ErrNoRows {
-			return fmt.Errorf("post with ID %d not found", postID)
-		}
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx) // No-op once committed
 
-		return fmt.Errorf("failed to verify post ownership: %w", err)
+	query := `
+		UPDATE users
+		SET banned_until = COALESCE($1, 'infinity'::timestamptz)
+		WHERE user_id = $2`
+
+	result, err := tx.Exec(ctx, query, until, userID)
+	if err != nil {
+		return fmt.Errorf("failed to ban user: %w", err)
 	}
 
-	if creatorID != userID {
-		return fmt.Errorf("user %d is not authorized to delete post %d", userID, postID)
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("user with ID %d not found", userID)
 	}
 
-	// Delete post (comments deleted automatically via CASCADE)
-	query := `
-		DELETE FROM posts
-		WHERE post_id = $1 AND created_by = $2`
+	metadata := map[string]any{"permanent": until == nil}
+	if until != nil {
+		metadata["bannedUntil"] = until.UTC().Format(TimestampFormat)
+	}
 
-	_, err = repo.DB.Exec(
-		ctx,
-		query,
-		postID,
-		userID,
-	)
+	if err := insertAuditLog(ctx, tx, adminID, AuditActionBan, "user", userID, metadata); err != nil {
+		return err
+	}
 
-	if err != nil {
-		return fmt.Errorf("failed to delete post: %w", err)
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit user ban: %w", err)
 	}
 
 	return nil
 }
 
-// DeleteTopic deletes an existing topic, including its posts and their comments
-func (repo *Repository) DeleteTopic(topicID, userID int) error {
+// UnbanUser lifts a user's suspension (if any) and records it in the audit log
+func (repo *Repository) UnbanUser(userID, adminID int) error {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	// Verify that topic exists and was created by the user
-	var creatorID int
+	// Unban and audit atomically
+	tx, err := repo.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx) // No-op once committed
 
-	checkQuery := `
-		SELECT created_by
-		FROM topics
-		WHERE topic_id = $1`
+	result, err := tx.Exec(ctx, `UPDATE users SET banned_until = NULL WHERE user_id = $1`, userID)
+	if err != nil {
+		return fmt.Errorf("failed to unban user: %w", err)
+	}
 
-	err := repo.DB.QueryRow(
-		ctx,
-		checkQuery,
-		topicID,
-	).Scan(&creatorID)
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("user with ID %d not found", userID)
+	}
 
-	if err != nil {
-		if err == pgx.ErrNoRows {
-			return fmt.Errorf("topic with ID %d not found", topicID)
-		}
+	if err := insertAuditLog(ctx, tx, adminID, AuditActionUnban, "user", userID, map[string]any{}); err != nil {
+		return err
+	}
 
-		return fmt.Errorf("failed to verify topic ownership: %w", err)
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit user unban: %w", err)
 	}
 
-	if creatorID != userID {
-		return fmt.Errorf("user %d is not authorized to delete topic %d", userID, topicID)
+	return nil
+}
+
+// IsUserBanned checks whether a user is currently suspended
+// Expired bans are ignored, so they lift without any cleanup
+func (repo *Repository) IsUserBanned(userID int) (bool, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var isBanned bool
+	query := `SELECT EXISTS (SELECT 1 FROM users WHERE user_id = $1 AND banned_until > NOW())`
+
+	err := repo.DB.QueryRow(ctx, query, userID).Scan(&isBanned)
+	if err != nil {
+		return false, fmt.Errorf("failed to check ban: %w", err)
 	}
 
-	// Delete topic (all posts and their comments delete automatically via CASCADE)
-	query := `
-		DELETE FROM topics
-		WHERE topic_id = $1 AND created_by = $2`
+	return isBanned, nil
+}
 
-	_, err = repo.DB.Exec(
-		ctx,
-		query,
-		topicID,
-		userID,
-	)
+// GetUserTokenVersion fetches the user's current token version (ErrUserNotFound if the user does not exist)
+func (repo *Repository) GetUserTokenVersion(userID int) (int, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 
+	var tokenVersion int
+	query := `SELECT token_version FROM users WHERE user_id = $1`
+
+	err := repo.DB.QueryRow(ctx, query, userID).Scan(&tokenVersion)
 	if err != nil {
-		return fmt.Errorf("failed to delete topic: %w", err)
+		if err == pgx.ErrNoRows {
+			return 0, fmt.Errorf("%w with ID: %d", ErrUserNotFound, userID)
+		}
+		return 0, fmt.Errorf("failed to get token version: %w", err)
 	}
 
-	return nil
+	return tokenVersion, nil
 }
 
-// GetUserByID fetches user by their unique user ID
-// Used internally when we need to get user details by ID
-func (repo *Repository) GetUserByID(userID int) (*User, error) {
+// IncrementTokenVersion bumps the user's token version, revoking all tokens issued so far, and returns the new version
+func (repo *Repository) IncrementTokenVersion(userID int) (int, error) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	var user User
+	var tokenVersion int
 	query := `
-		SELECT user_id, username, created_at, updated_at
-		FROM users
-		WHERE user_id = $1`
-
-	err := repo.DB.QueryRow(ctx, query, userID).Scan(
-		&user.UserID,
-		&user.Username,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-	)
+		UPDATE users
+		SET token_version = token_version + 1
+		WHERE user_id = $1
+		RETURNING token_version`
 
+	err := repo.DB.QueryRow(ctx, query, userID).Scan(&tokenVersion)
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			return nil, fmt.Errorf("user with ID %d not found", userID)
+			return 0, fmt.Errorf("%w with ID: %d", ErrUserNotFound, userID)
 		}
-
-		return nil, fmt.Errorf("failed to fetch user: %w", err)
+		return 0, fmt.Errorf("failed to increment token version: %w", err)
 	}
 
-	return &user, nil
+	return tokenVersion, nil
 }
 
 // GetUserPosts fetches all posts created by a specific user
@@ -988,7 +2817,7 @@ func (repo *Repository) GetUserComments(userID int) ([]*Comment, error) {
 	defer cancel()
 
 	query := `
-		SELECT c.comment_id, c.post_id, p.title as post_title, c.content, c.created_by, u.username, c.created_at, c.updated_at
+		SELECT c.comment_id, c.post_id, p.title as post_title, c.content, c.created_by, u.username, c.created_at, c.updated_at, c.quoted_comment_id, c.deleted_at
 		FROM comments c
 		JOIN users u ON c.created_by = u.user_id
 		JOIN posts p ON c.post_id = p.post_id
@@ -1013,6 +2842,8 @@ func (repo *Repository) GetUserComments(userID int) ([]*Comment, error) {
 			&comment.Username,
 			&comment.CreatedAt,
 			&comment.UpdatedAt,
+			&comment.QuotedCommentID,
+			&comment.DeletedAt,
 		)
 
 		if err != nil {
@@ -1073,7 +2904,7 @@ func (repo *Repository) RemovePostVote(userID, postID int) error {
 	return nil
 }
 
-// VoteComment creates/updates a vote on a comment
+// VoteComment creates/updates a vote on a comment (not found if the comment does not exist or was soft-deleted)
 func (repo *Repository) VoteComment(userID, commentID, voteType int) error {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -1083,17 +2914,24 @@ func (repo *Repository) VoteComment(userID, commentID, voteType int) error {
 		return fmt.Errorf("invalid vote type: %d", voteType)
 	}
 
+	// Soft-deleted comments only remain as thread placeholders, so they cannot be voted on
 	query := `
 		INSERT INTO votes (user_id, comment_id, vote_type, created_at, updated_at)
-		VALUES ($1, $2, $3, NOW(), NOW())
+		SELECT $1, comment_id, $3, NOW(), NOW()
+		FROM comments
+		WHERE comment_id = $2 AND deleted_at IS NULL
 		ON CONFLICT (user_id, comment_id) 
 		DO UPDATE SET vote_type = $3, updated_at = NOW()`
 
-	_, err := repo.DB.Exec(ctx, query, userID, commentID, voteType)
+	commandTag, err := repo.DB.Exec(ctx, query, userID, commentID, voteType)
 	if err != nil {
 		return fmt.Errorf("failed to vote on comment: %w", err)
 	}
 
+	if commandTag.RowsAffected() == 0 {
+		return fmt.Errorf("comment with ID %d not found", commentID)
+	}
+
 	return nil
 }
 
@@ -1161,3 +2999,148 @@ func (repo *Repository) GetUserVoteOnComment(userID, commentID int) (*int, error
 
 	return &voteType, nil
 }
+
+// GetVoteCountsForPosts sums the votes cast on each of several posts
+// Every requested post ID is present in the result, with 0 for posts that have no votes (or do not exist)
+func (repo *Repository) GetVoteCountsForPosts(postIDs []int) (map[int]int, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	query := `
+		SELECT post_id, SUM(vote_type)
+		FROM votes
+		WHERE post_id = ANY($1)
+		GROUP BY post_id`
+
+	rows, err := repo.reader().Query(ctx, query, postIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query vote counts: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[int]int, len(postIDs))
+	for _, postID := range postIDs {
+		counts[postID] = 0
+	}
+
+	for rows.Next() {
+		var postID, count int
+		if err := rows.Scan(&postID, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan vote count row: %w", err)
+		}
+
+		counts[postID] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error encountered during row iteration: %w", err)
+	}
+
+	return counts, nil
+}
+
+// GetIdempotencyKey fetches an unexpired idempotency key record for a user, if any
+func (repo *Repository) GetIdempotencyKey(userID int, key string, ttl time.Duration) (*IdempotencyKey, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var record IdempotencyKey
+	query := `
+		SELECT idempotency_key, user_id, resource_type, resource_id, request_hash, created_at
+		FROM idempotency_keys
+		WHERE user_id = $1 AND idempotency_key = $2
+		AND created_at > NOW() - ($3 * INTERVAL '1 second')`
+
+	err := repo.DB.QueryRow(ctx, query, userID, key, ttl.Seconds()).Scan(
+		&record.Key,
+		&record.UserID,
+		&record.ResourceType,
+		&record.ResourceID,
+		&record.RequestHash,
+		&record.CreatedAt,
+	)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil // No (unexpired) key found
+		}
+		return nil, fmt.Errorf("failed to get idempotency key: %w", err)
+	}
+
+	return &record, nil
+}
+
+// ClaimIdempotencyKey records a pending (resource-less) key, reporting whether this call claimed it
+// Expired records, and pending ones older than pendingTimeout (left by a failed request), are replaced;
+// otherwise the key is left untouched, so of several concurrent claims exactly one succeeds
+func (repo *Repository) ClaimIdempotencyKey(record *IdempotencyKey, ttl, pendingTimeout time.Duration) (bool, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	query := `
+		INSERT INTO idempotency_keys (idempotency_key, user_id, resource_type, resource_id, request_hash, created_at)
+		VALUES ($1, $2, $3, NULL, $4, NOW())
+		ON CONFLICT (user_id, idempotency_key)
+		DO UPDATE SET
+			resource_type = EXCLUDED.resource_type,
+			resource_id = NULL,
+			request_hash = EXCLUDED.request_hash,
+			created_at = NOW()
+		WHERE idempotency_keys.created_at <= NOW() - ($5 * INTERVAL '1 second')
+		OR (idempotency_keys.resource_id IS NULL AND idempotency_keys.created_at <= NOW() - ($6 * INTERVAL '1 second'))
+		RETURNING idempotency_key`
+
+	var key string
+	err := repo.DB.QueryRow(
+		ctx,
+		query,
+		record.Key,
+		record.UserID,
+		record.ResourceType,
+		record.RequestHash,
+		ttl.Seconds(),
+		pendingTimeout.Seconds(),
+	).Scan(&key)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return false, nil // Someone else holds the key
+		}
+		return false, fmt.Errorf("failed to claim idempotency key: %w", err)
+	}
+
+	return true, nil
+}
+
+// CompleteIdempotencyKey stores the resource created for a claimed idempotency key
+func (repo *Repository) CompleteIdempotencyKey(userID int, key string, resourceID int) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	query := `
+		UPDATE idempotency_keys
+		SET resource_id = $3
+		WHERE user_id = $1 AND idempotency_key = $2 AND resource_id IS NULL`
+
+	if _, err := repo.DB.Exec(ctx, query, userID, key, resourceID); err != nil {
+		return fmt.Errorf("failed to complete idempotency key: %w", err)
+	}
+
+	return nil
+}
+
+// ReleaseIdempotencyKey removes a claimed key whose resource was not created, so the request can be retried
+func (repo *Repository) ReleaseIdempotencyKey(userID int, key string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	query := `
+		DELETE FROM idempotency_keys
+		WHERE user_id = $1 AND idempotency_key = $2 AND resource_id IS NULL`
+
+	if _, err := repo.DB.Exec(ctx, query, userID, key); err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,102 @@
+package data
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// DefaultSlowQueryThreshold is used if SLOW_QUERY_MS is set but is not a positive number of milliseconds
+const DefaultSlowQueryThreshold = 200 * time.Millisecond
+
+// slowQueryStartKey is the context key under which slowQueryTracer keeps a query's start time
+type slowQueryStartKey struct{}
+
+// slowQueryTracer logs (via slog) every query that takes longer than threshold
+// Queries read through rows end when the rows are closed, so time spent scanning counts towards them
+type slowQueryTracer struct {
+	threshold time.Duration
+	logger    *slog.Logger
+}
+
+// slowQueryThreshold returns the threshold set by SLOW_QUERY_MS, and false if slow query logging is off (unset)
+func slowQueryThreshold() (time.Duration, bool) {
+	value := os.Getenv("SLOW_QUERY_MS")
+	if value == "" {
+		return 0, false
+	}
+
+	ms, err := strconv.Atoi(value)
+	if err != nil || ms <= 0 {
+		slog.Warn("invalid SLOW_QUERY_MS, using default", "value", value, "default", DefaultSlowQueryThreshold)
+		return DefaultSlowQueryThreshold, true
+	}
+
+	return time.Duration(ms) * time.Millisecond, true
+}
+
+// slowQueryTracerFromEnv returns the tracer configured by SLOW_QUERY_MS, or nil if slow query logging is off
+func slowQueryTracerFromEnv() pgx.QueryTracer {
+	threshold, ok := slowQueryThreshold()
+	if !ok {
+		return nil
+	}
+
+	return &slowQueryTracer{threshold: threshold, logger: slog.Default()}
+}
+
+// TraceQueryStart records when the query started
+func (tracer *slowQueryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, _ pgx.TraceQueryStartData) context.Context {
+	return context.WithValue(ctx, slowQueryStartKey{}, time.Now())
+}
+
+// TraceQueryEnd logs the query if it took longer than the threshold
+func (tracer *slowQueryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	start, ok := ctx.Value(slowQueryStartKey{}).(time.Time)
+	if !ok {
+		return
+	}
+
+	elapsed := time.Since(start)
+	if elapsed <= tracer.threshold {
+		return
+	}
+
+	attrs := []any{
+		"operation", repositoryOperation(),
+		"elapsed", elapsed,
+		"threshold", tracer.threshold,
+	}
+	if data.Err != nil {
+		attrs = append(attrs, "error", data.Err)
+	}
+
+	tracer.logger.WarnContext(ctx, "slow query", attrs...)
+}
+
+// repositoryOperation returns the name of the Repository method running the current query (e.g. "GetAllTopics")
+// Only looked up for slow queries, as walking the stack is comparatively expensive; "unknown" outside Repository
+func repositoryOperation() string {
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+
+	for {
+		frame, more := frames.Next()
+
+		if _, method, ok := strings.Cut(frame.Function, "/internal/data.(*Repository)."); ok {
+			// Queries run from closures (e.g. inside transactions) report the enclosing method
+			name, _, _ := strings.Cut(method, ".")
+			return name
+		}
+
+		if !more {
+			return "unknown"
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// maxSlugBaseLength caps the slug generated from a title, leaving room for a numeric suffix
+const maxSlugBaseLength = 200
+
+// topicSlugConstraint is the unique constraint on topics.slug
+const topicSlugConstraint = "topics_slug_key"
+
+// maxSlugAttempts is how many times a slug is regenerated when a concurrent write claims it first
+const maxSlugAttempts = 3
+
+// Slugify converts a title into a URL-friendly slug: lowercase ASCII letters and digits,
+// with every other run of characters replaced by a single hyphen (e.g. "Hello, World!" -> "hello-world")
+// Titles without any letters or digits become "topic"
+func Slugify(title string) string {
+	var b strings.Builder
+	pendingHyphen := false
+
+	for _, r := range strings.ToLower(title) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if pendingHyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			pendingHyphen = false
+			b.WriteRune(r)
+
+			if b.Len() >= maxSlugBaseLength {
+				break
+			}
+			continue
+		}
+
+		pendingHyphen = true
+	}
+
+	if b.Len() == 0 {
+		return "topic"
+	}
+
+	return b.String()
+}
+
+// uniqueTopicSlug returns base, or base with the lowest free numeric suffix ("-2", "-3", ...)
+// if another topic already uses it. topicID is excluded so a topic never collides with itself (0 for new topics)
+func (repo *Repository) uniqueTopicSlug(ctx context.Context, base string, topicID int) (string, error) {
+	// base only contains [a-z0-9-], so it is safe to embed in the pattern
+	query := `
+		SELECT slug
+		FROM topics
+		WHERE (slug = $1 OR slug ~ ('^' || $1 || '-[0-9]+$')) AND topic_id <> $2`
+
+	rows, err := repo.DB.Query(ctx, query, base, topicID)
+	if err != nil {
+		return "", fmt.Errorf("query topic slugs failed: %w", err)
+	}
+	defer rows.Close()
+
+	taken := map[string]bool{}
+	for rows.Next() {
+		var slug string
+		if err := rows.Scan(&slug); err != nil {
+			return "", fmt.Errorf("error scanning topic slug: %w", err)
+		}
+		taken[slug] = true
+	}
+
+	if err = rows.Err(); err != nil {
+		return "", fmt.Errorf("error encountered during row iteration: %w", err)
+	}
+
+	slug := base
+	for n := 2; taken[slug]; n++ {
+		slug = fmt.Sprintf("%s-%d", base, n)
+	}
+
+	return slug, nil
+}
+
+// isTopicSlugConflict reports whether err is a unique violation on topics.slug
+func isTopicSlugConflict(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == topicSlugConstraint
+}
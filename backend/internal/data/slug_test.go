@@ -0,0 +1,33 @@
+// Run `go test -v ./internal/data -run TestSlugify` in /backend
+
+package data
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSlugify(t *testing.T) {
+	cases := []struct {
+		title    string
+		expected string
+	}{
+		{"Hello, World!", "hello-world"},
+		{"  Go 1.25 -- Release Notes  ", "go-1-25-release-notes"},
+		{"already-a-slug", "already-a-slug"},
+		{"Café au lait", "caf-au-lait"},
+		{"!!!", "topic"},
+		{"", "topic"},
+	}
+
+	for _, tc := range cases {
+		if got := Slugify(tc.title); got != tc.expected {
+			t.Errorf("Slugify(%q): expected %q, got %q", tc.title, tc.expected, got)
+		}
+	}
+
+	// Long titles are capped, leaving room for a collision suffix
+	if got := Slugify(strings.Repeat("a", 300)); len(got) != maxSlugBaseLength {
+		t.Errorf("expected slug of length %d, got %d", maxSlugBaseLength, len(got))
+	}
+}
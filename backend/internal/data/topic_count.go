@@ -0,0 +1,35 @@
+package data
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// countTopics returns the number of topics in db for the topics list's pagination metadata, and whether it is estimated
+// COUNT(*) scans the whole table, so once the planner's estimate (pg_class.reltuples, kept up to date by ANALYZE
+// and autovacuum) reaches estimateAbove, the estimate is returned instead; it can lag recent writes, so totals may
+// be off by the rows changed since the table was last analyzed. An estimateAbove of 0 always counts exactly
+func countTopics(ctx context.Context, db *pgxpool.Pool, estimateAbove int) (int, bool, error) {
+	if estimateAbove > 0 {
+		// reltuples is -1 for tables never analyzed, which falls through to the exact count
+		var estimate int
+		err := db.QueryRow(ctx, `SELECT reltuples::bigint FROM pg_class WHERE oid = 'topics'::regclass`).Scan(&estimate)
+		if err != nil {
+			return 0, false, fmt.Errorf("estimate topics count failed: %w", err)
+		}
+
+		if estimate >= estimateAbove {
+			return estimate, true, nil
+		}
+	}
+
+	var total int
+	err := db.QueryRow(ctx, `SELECT COUNT(*) FROM topics`).Scan(&total)
+	if err != nil {
+		return 0, false, fmt.Errorf("count topics failed: %w", err)
+	}
+
+	return total, false, nil
+}
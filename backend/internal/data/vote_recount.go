@@ -0,0 +1,107 @@
+package data
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// RecountVotes recomputes every post's and comment's stored vote_count from the votes table,
+// correcting (and auditing, as adminID) those that drifted; returns the corrections made
+func (repo *Repository) RecountVotes(adminID int) (*VoteRecount, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Recount, fix and audit atomically
+	tx, err := repo.DB.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx) // No-op once committed
+
+	// Hold off concurrent votes, whose triggers would otherwise update counts between the sum and the fix
+	if _, err := tx.Exec(ctx, `LOCK TABLE votes IN SHARE MODE`); err != nil {
+		return nil, fmt.Errorf("failed to lock votes: %w", err)
+	}
+
+	postsFixed, err := fixVoteCounts(ctx, tx, `
+		UPDATE posts p
+		SET vote_count = c.computed, votes_changed_at = NOW()
+		FROM (
+			SELECT p2.post_id AS id, p2.vote_count AS stored, COALESCE(SUM(v.vote_type), 0) AS computed
+			FROM posts p2
+			LEFT JOIN votes v ON v.post_id = p2.post_id
+			GROUP BY p2.post_id
+		) c
+		WHERE p.post_id = c.id AND c.stored <> c.computed
+		RETURNING c.id, c.stored, c.computed`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to recount post votes: %w", err)
+	}
+
+	commentsFixed, err := fixVoteCounts(ctx, tx, `
+		UPDATE comments cm
+		SET vote_count = c.computed
+		FROM (
+			SELECT c2.comment_id AS id, c2.vote_count AS stored, COALESCE(SUM(v.vote_type), 0) AS computed
+			FROM comments c2
+			LEFT JOIN votes v ON v.comment_id = c2.comment_id
+			GROUP BY c2.comment_id
+		) c
+		WHERE cm.comment_id = c.id AND c.stored <> c.computed
+		RETURNING c.id, c.stored, c.computed`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to recount comment votes: %w", err)
+	}
+
+	// Record each correction in audit log
+	targets := []struct {
+		targetType string
+		fixes      []VoteCountFix
+	}{{"post", postsFixed}, {"comment", commentsFixed}}
+
+	for _, target := range targets {
+		for _, fix := range target.fixes {
+			err = insertAuditLog(ctx, tx, adminID, AuditActionRecountVotes, target.targetType, fix.ID, map[string]any{
+				"stored":   fix.Stored,
+				"computed": fix.Computed,
+			})
+
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit vote recount: %w", err)
+	}
+
+	return &VoteRecount{PostsFixed: postsFixed, CommentsFixed: commentsFixed}, nil
+}
+
+// fixVoteCounts runs an UPDATE ... RETURNING id, stored, computed within tx and collects the corrected rows
+func fixVoteCounts(ctx context.Context, tx pgx.Tx, query string) ([]VoteCountFix, error) {
+	rows, err := tx.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	fixes := []VoteCountFix{}
+	for rows.Next() {
+		var fix VoteCountFix
+		if err := rows.Scan(&fix.ID, &fix.Stored, &fix.Computed); err != nil {
+			return nil, fmt.Errorf("failed to scan vote count fix: %w", err)
+		}
+
+		fixes = append(fixes, fix)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error encountered during row iteration: %w", err)
+	}
+
+	return fixes, nil
+}
@@ -0,0 +1,107 @@
+package data
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// CreateWebhook registers a webhook for new posts in a topic
+func (repo *Repository) CreateWebhook(topicID, userID int, url, secret string) (*Webhook, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	query := `
+		INSERT INTO webhooks (user_id, topic_id, url, secret)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, user_id, topic_id, url, secret, created_at`
+
+	var webhook Webhook
+	err := repo.DB.QueryRow(ctx, query, userID, topicID, url, secret).Scan(
+		&webhook.WebhookID,
+		&webhook.UserID,
+		&webhook.TopicID,
+		&webhook.URL,
+		&webhook.Secret,
+		&webhook.CreatedAt,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create webhook: %w", err)
+	}
+
+	return &webhook, nil
+}
+
+// GetWebhooksByTopicID fetches all webhooks registered for a topic
+func (repo *Repository) GetWebhooksByTopicID(topicID int) ([]*Webhook, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	query := `
+		SELECT id, user_id, topic_id, url, secret, created_at
+		FROM webhooks
+		WHERE topic_id = $1
+		ORDER BY id`
+
+	rows, err := repo.DB.Query(ctx, query, topicID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhooks: %w", err)
+	}
+	defer rows.Close()
+
+	webhooks := []*Webhook{}
+	for rows.Next() {
+		var webhook Webhook
+
+		err := rows.Scan(
+			&webhook.WebhookID,
+			&webhook.UserID,
+			&webhook.TopicID,
+			&webhook.URL,
+			&webhook.Secret,
+			&webhook.CreatedAt,
+		)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook row: %w", err)
+		}
+
+		webhooks = append(webhooks, &webhook)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error encountered during row iteration: %w", err)
+	}
+
+	return webhooks, nil
+}
+
+// DeleteWebhook removes a webhook registered by the user
+func (repo *Repository) DeleteWebhook(webhookID, userID int) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Verify that webhook exists and was registered by the user
+	var ownerID int
+	err := repo.DB.QueryRow(ctx, `SELECT user_id FROM webhooks WHERE id = $1`, webhookID).Scan(&ownerID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return fmt.Errorf("webhook with ID %d not found", webhookID)
+		}
+
+		return fmt.Errorf("failed to verify webhook ownership: %w", err)
+	}
+
+	if ownerID != userID {
+		return fmt.Errorf("user %d is not authorized to delete webhook %d", userID, webhookID)
+	}
+
+	_, err = repo.DB.Exec(ctx, `DELETE FROM webhooks WHERE id = $1 AND user_id = $2`, webhookID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,206 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/adzzfarr/gossip-with-go/backend/internal/data"
+)
+
+// MaxBulkDeleteComments is the maximum number of comments that can be deleted in one request
+const MaxBulkDeleteComments = 500
+
+// AdminService handles business logic related to admin-only features via the repository layer
+type AdminService struct {
+	Repo      *data.Repository
+	UserCache *UserExistsCache // Optional (nil checks the database on every IsBanned call); share UserService's ExistsCache
+}
+
+// NewAdminService creates a new instance of AdminService
+func NewAdminService(repo *data.Repository) *AdminService {
+	return &AdminService{Repo: repo}
+}
+
+// IsAdmin checks whether a user has the admin role
+func (adminService *AdminService) IsAdmin(userID int) (bool, error) {
+	// UserID Validation
+	if userID <= 0 {
+		return false, nil
+	}
+
+	// Delegate call to repository layer
+	isAdmin, err := adminService.Repo.IsUserAdmin(userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to check admin role for user ID %d: %w", userID, err)
+	}
+
+	return isAdmin, nil
+}
+
+// IsBanned checks whether a user is currently suspended
+// Cached for UserCache's TTL if a cache is set, so a timed ban may outlast its end by up to the TTL
+func (adminService *AdminService) IsBanned(userID int) (bool, error) {
+	// UserID Validation
+	if userID <= 0 {
+		return false, nil
+	}
+
+	if adminService.UserCache != nil {
+		if isBanned, ok := adminService.UserCache.GetBanned(userID); ok {
+			return isBanned, nil
+		}
+	}
+
+	// Delegate call to repository layer
+	isBanned, err := adminService.Repo.IsUserBanned(userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to check ban for user ID %d: %w", userID, err)
+	}
+
+	if adminService.UserCache != nil {
+		adminService.UserCache.StoreBanned(userID, isBanned)
+	}
+
+	return isBanned, nil
+}
+
+// BanUser suspends a user for duration (0 bans permanently), returning when the ban ends (nil if permanent)
+// The user's existing content is kept. This instance enforces the ban at once; others may take up to their UserCache's TTL
+func (adminService *AdminService) BanUser(userID, adminID int, duration time.Duration) (*time.Time, error) {
+	// Input Validation
+	if userID <= 0 {
+		return nil, fmt.Errorf("invalid user ID: %d", userID)
+	}
+
+	if userID == adminID {
+		return nil, newValidationError("admins cannot ban themselves")
+	}
+
+	if duration < 0 {
+		return nil, newValidationError("duration cannot be negative")
+	}
+
+	var until *time.Time
+	if duration > 0 {
+		end := time.Now().Add(duration)
+		until = &end
+	}
+
+	// Delegate call to repository layer
+	if err := adminService.Repo.BanUser(userID, until, adminID); err != nil {
+		return nil, fmt.Errorf("failed to ban user: %w", err)
+	}
+
+	if adminService.UserCache != nil {
+		adminService.UserCache.Forget(userID)
+	}
+
+	return until, nil
+}
+
+// UnbanUser lifts a user's suspension (at once on this instance; others may take up to their UserCache's TTL)
+func (adminService *AdminService) UnbanUser(userID, adminID int) error {
+	// UserID Validation
+	if userID <= 0 {
+		return fmt.Errorf("invalid user ID: %d", userID)
+	}
+
+	// Delegate call to repository layer
+	if err := adminService.Repo.UnbanUser(userID, adminID); err != nil {
+		return fmt.Errorf("failed to unban user: %w", err)
+	}
+
+	if adminService.UserCache != nil {
+		adminService.UserCache.Forget(userID)
+	}
+
+	return nil
+}
+
+// GetAuditLog retrieves a page of audit log entries, most recent first
+func (adminService *AdminService) GetAuditLog(limit, offset int) (*data.PagedResponse[*data.AuditLogEntry], error) {
+	// Pagination Validation
+	if limit <= 0 {
+		return nil, fmt.Errorf("invalid limit: %d", limit)
+	}
+
+	if offset < 0 {
+		return nil, fmt.Errorf("invalid offset: %d", offset)
+	}
+
+	// Delegate call to repository layer
+	entries, err := adminService.Repo.GetAuditLog(limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audit log: %w", err)
+	}
+
+	return entries, nil
+}
+
+// SearchUsers retrieves a page of users whose username starts with query (ignoring case), oldest first
+// An empty query lists every user
+func (adminService *AdminService) SearchUsers(query string, limit, offset int) (*data.PagedResponse[*data.UserSummary], error) {
+	// Pagination Validation
+	if limit <= 0 {
+		return nil, fmt.Errorf("invalid limit: %d", limit)
+	}
+
+	if offset < 0 {
+		return nil, fmt.Errorf("invalid offset: %d", offset)
+	}
+
+	// Query Validation (no username is longer than maxUsernameLength, so longer prefixes cannot match)
+	query = strings.TrimSpace(stripNullBytes(query))
+	if utf8.RuneCountInString(query) > maxUsernameLength {
+		return nil, newValidationError("search query too long")
+	}
+
+	// Delegate call to repository layer
+	users, err := adminService.Repo.SearchUsers(query, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search users: %w", err)
+	}
+
+	return users, nil
+}
+
+// DeleteComments deletes several comments at once on behalf of an admin, returning how many were deleted
+// IDs of comments that no longer exist are ignored
+func (adminService *AdminService) DeleteComments(commentIDs []int, adminID int) (int, error) {
+	// Validate comment IDs
+	if len(commentIDs) == 0 {
+		return 0, newValidationError("commentIDs cannot be empty")
+	}
+
+	if len(commentIDs) > MaxBulkDeleteComments {
+		return 0, newValidationError("too many comment IDs: maximum is %d", MaxBulkDeleteComments)
+	}
+
+	for _, commentID := range commentIDs {
+		if commentID <= 0 {
+			return 0, newValidationError("invalid comment ID: %d", commentID)
+		}
+	}
+
+	// Delegate call to repository layer
+	deleted, err := adminService.Repo.DeleteCommentsAsAdmin(commentIDs, adminID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete comments: %w", err)
+	}
+
+	return deleted, nil
+}
+
+// RecountVotes recomputes every stored post and comment vote count from the raw votes on behalf of an admin,
+// correcting any that drifted and returning what was changed
+func (adminService *AdminService) RecountVotes(adminID int) (*data.VoteRecount, error) {
+	// Delegate call to repository layer
+	recount, err := adminService.Repo.RecountVotes(adminID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to recount votes: %w", err)
+	}
+
+	return recount, nil
+}
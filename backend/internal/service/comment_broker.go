@@ -0,0 +1,72 @@
+package service
+
+import (
+	"sync"
+
+	"github.com/adzzfarr/gossip-with-go/backend/internal/data"
+)
+
+// commentSubscriberBuffer is how many comments a subscriber may fall behind before new ones are dropped for it
+const commentSubscriberBuffer = 16
+
+// CommentBroker fans newly created comments out to subscribers of their post
+// It is in-process only, so subscribers see comments created through this instance
+type CommentBroker struct {
+	mu          sync.Mutex
+	subscribers map[int]map[chan *data.Comment]struct{} // postID -> subscriber channels
+}
+
+// NewCommentBroker creates a new instance of CommentBroker
+func NewCommentBroker() *CommentBroker {
+	return &CommentBroker{subscribers: make(map[int]map[chan *data.Comment]struct{})}
+}
+
+// Subscribe registers for comments created on postID
+// The returned function unsubscribes and must be called once the subscriber is done
+func (broker *CommentBroker) Subscribe(postID int) (<-chan *data.Comment, func()) {
+	ch := make(chan *data.Comment, commentSubscriberBuffer)
+
+	broker.mu.Lock()
+	if broker.subscribers[postID] == nil {
+		broker.subscribers[postID] = make(map[chan *data.Comment]struct{})
+	}
+	broker.subscribers[postID][ch] = struct{}{}
+	broker.mu.Unlock()
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			broker.mu.Lock()
+			defer broker.mu.Unlock()
+
+			delete(broker.subscribers[postID], ch)
+			if len(broker.subscribers[postID]) == 0 {
+				delete(broker.subscribers, postID)
+			}
+		})
+	}
+
+	return ch, unsubscribe
+}
+
+// Publish sends a comment to every subscriber of its post without blocking
+// Subscribers whose buffer is full miss the comment rather than holding up the publisher
+func (broker *CommentBroker) Publish(comment *data.Comment) {
+	broker.mu.Lock()
+	defer broker.mu.Unlock()
+
+	for ch := range broker.subscribers[comment.PostID] {
+		select {
+		case ch <- comment:
+		default:
+		}
+	}
+}
+
+// subscriberCount returns the number of subscribers to postID
+func (broker *CommentBroker) subscriberCount(postID int) int {
+	broker.mu.Lock()
+	defer broker.mu.Unlock()
+
+	return len(broker.subscribers[postID])
+}
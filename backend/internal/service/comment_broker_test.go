@@ -0,0 +1,51 @@
+// Run `go test -v ./internal/service -run TestCommentBroker` in /backend
+package service
+
+import (
+	"testing"
+
+	"github.com/adzzfarr/gossip-with-go/backend/internal/data"
+)
+
+func TestCommentBroker(t *testing.T) {
+	broker := NewCommentBroker()
+
+	comments, unsubscribe := broker.Subscribe(1)
+	otherComments, otherUnsubscribe := broker.Subscribe(2)
+	defer otherUnsubscribe()
+
+	broker.Publish(&data.Comment{CommentID: 10, PostID: 1})
+
+	// Subscribers receive comments on their post only
+	select {
+	case comment := <-comments:
+		if comment.CommentID != 10 {
+			t.Errorf("expected comment 10, got %d", comment.CommentID)
+		}
+	default:
+		t.Fatalf("expected comment to be delivered to subscriber")
+	}
+
+	select {
+	case comment := <-otherComments:
+		t.Errorf("expected no comment for post 2, got %d", comment.CommentID)
+	default:
+	}
+
+	// Unsubscribing removes the subscriber (and is safe to repeat)
+	unsubscribe()
+	unsubscribe()
+
+	if count := broker.subscriberCount(1); count != 0 {
+		t.Errorf("expected no subscribers after unsubscribe, got %d", count)
+	}
+
+	// Slow subscribers miss comments rather than blocking the publisher
+	for i := 0; i < commentSubscriberBuffer+5; i++ {
+		broker.Publish(&data.Comment{CommentID: i, PostID: 2})
+	}
+
+	if len(otherComments) != commentSubscriberBuffer {
+		t.Errorf("expected %d buffered comments, got %d", commentSubscriberBuffer, len(otherComments))
+	}
+}
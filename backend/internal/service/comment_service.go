@@ -3,29 +3,52 @@ package service
 import (
 	"fmt"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/adzzfarr/gossip-with-go/backend/internal/data"
 )
 
+// DuplicateCommentWindow is how long an identical repeat of a user's latest comment on a post is rejected
+const DuplicateCommentWindow = 60 * time.Second
+
 // CommentService handles business logic related to comments via the repository layer
 type CommentService struct {
-	Repo *data.Repository
+	Repo               *data.Repository
+	Broker             *CommentBroker // Optional; receives newly created comments when set
+	MaxCommentsPerPost int            // Comments a post may hold before CreateComment is refused; 0 means unlimited
+	Limits             ContentLimits  // Maximum comment length; DefaultContentLimits unless replaced
 }
 
 // NewCommentService creates a new instance of CommentService
 func NewCommentService(repo *data.Repository) *CommentService {
-	return &CommentService{Repo: repo}
+	return &CommentService{Repo: repo, Limits: DefaultContentLimits}
 }
 
-// GetCommentsByPostID retrieves all comments for a given post
-func (commentService *CommentService) GetCommentsByPostID(postID int, userID *int) ([]*data.Comment, error) {
+// GetCommentsByPostID retrieves all comments for a given post, ordered by sort (see data.CommentSortNew etc.)
+func (commentService *CommentService) GetCommentsByPostID(postID int, userID *int, sort string) ([]*data.Comment, error) {
 	// Validate post ID
 	if postID <= 0 {
 		return nil, fmt.Errorf("invalid post ID: %d", postID)
 	}
 
+	// Validate sort order
+	if !data.IsValidCommentSort(sort) {
+		return nil, newValidationError("invalid sort: must be one of %s, %s, %s", data.CommentSortNew, data.CommentSortOld, data.CommentSortTop)
+	}
+
+	// Verify post exists, so a missing post is not mistaken for one without comments
+	exists, err := commentService.Repo.PostExists(postID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify post ID %d: %w", postID, err)
+	}
+
+	if !exists {
+		return nil, fmt.Errorf("%w with ID: %d", data.ErrPostNotFound, postID)
+	}
+
 	// Delegate call to repository layer
-	comments, err := commentService.Repo.GetCommentsByPostID(postID, userID)
+	comments, err := commentService.Repo.GetCommentsByPostID(postID, userID, sort)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get comments for post ID %d: %w", postID, err)
 	}
@@ -35,9 +58,81 @@ func (commentService *CommentService) GetCommentsByPostID(postID int, userID *in
 		comments = []*data.Comment{}
 	}
 
+	markCommentOwnership(userID, comments...)
+
 	return comments, nil
 }
 
+// GetCommentsByPostIDPaginated retrieves a page of a post's comments, oldest first, after the given cursor
+// An empty cursor starts from the first comment; pass the returned NextCursor to fetch the following page
+func (commentService *CommentService) GetCommentsByPostIDPaginated(postID int, userID *int, cursor string, limit int) (*data.PagedResponse[*data.Comment], error) {
+	// Validate post ID
+	if postID <= 0 {
+		return nil, fmt.Errorf("invalid post ID: %d", postID)
+	}
+
+	// Pagination Validation
+	if limit <= 0 {
+		return nil, fmt.Errorf("invalid limit: %d", limit)
+	}
+
+	var afterCreatedAt time.Time
+	var afterID int
+	if cursor != "" {
+		var err error
+		afterCreatedAt, afterID, err = data.DecodeCommentCursor(cursor)
+		if err != nil {
+			return nil, newValidationError("invalid cursor")
+		}
+	}
+
+	// Verify post exists, so a missing post is not mistaken for one without comments
+	exists, err := commentService.Repo.PostExists(postID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify post ID %d: %w", postID, err)
+	}
+
+	if !exists {
+		return nil, fmt.Errorf("%w with ID: %d", data.ErrPostNotFound, postID)
+	}
+
+	// Delegate call to repository layer
+	page, err := commentService.Repo.GetCommentsByPostIDPaginated(postID, userID, afterCreatedAt, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get comments for post ID %d: %w", postID, err)
+	}
+
+	markCommentOwnership(userID, page.Items...)
+
+	return page, nil
+}
+
+// CountCommentsByPostID returns the number of comments on a post
+func (commentService *CommentService) CountCommentsByPostID(postID int) (int, error) {
+	// Validate post ID
+	if postID <= 0 {
+		return 0, fmt.Errorf("invalid post ID: %d", postID)
+	}
+
+	// Verify post exists, so a missing post is not reported as having no comments
+	exists, err := commentService.Repo.PostExists(postID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to verify post ID %d: %w", postID, err)
+	}
+
+	if !exists {
+		return 0, fmt.Errorf("%w with ID: %d", data.ErrPostNotFound, postID)
+	}
+
+	// Delegate call to repository layer
+	count, err := commentService.Repo.CountCommentsByPostID(postID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count comments for post ID %d: %w", postID, err)
+	}
+
+	return count, nil
+}
+
 // GetCommentByID retrieves a specific comment by its ID
 func (commentService *CommentService) GetCommentByID(commentID int, userID *int) (*data.Comment, error) {
 	// Validate comment ID
@@ -51,36 +146,152 @@ func (commentService *CommentService) GetCommentByID(commentID int, userID *int)
 		return nil, fmt.Errorf("failed to get comment by ID %d: %w", commentID, err)
 	}
 
+	markCommentOwnership(userID, comment)
+
 	return comment, nil
 }
 
+// PostBelongsToTopic checks whether a post exists under the given topic
+func (commentService *CommentService) PostBelongsToTopic(postID, topicID int) (bool, error) {
+	// Validate IDs
+	if postID <= 0 {
+		return false, fmt.Errorf("invalid post ID: %d", postID)
+	}
+
+	if topicID <= 0 {
+		return false, fmt.Errorf("invalid topic ID: %d", topicID)
+	}
+
+	// Delegate call to repository layer
+	belongs, err := commentService.Repo.PostBelongsToTopic(postID, topicID)
+	if err != nil {
+		return false, fmt.Errorf("failed to verify post ID %d in topic ID %d: %w", postID, topicID, err)
+	}
+
+	return belongs, nil
+}
+
 // CreateComment creates a new comment on a post
-func (commentService *CommentService) CreateComment(postID int, content string, userID int) (*data.Comment, error) {
+// quotedCommentID is optional; if set, it must refer to an existing comment on the same post
+func (commentService *CommentService) CreateComment(postID int, content string, userID int, quotedCommentID *int) (*data.Comment, error) {
+	// Trim surrounding whitespace so blank input is rejected and clean values are stored
+	content = strings.TrimSpace(stripNullBytes(content))
+
 	// Content Validation
-	if strings.TrimSpace(content) == "" {
-		return nil, fmt.Errorf("content cannot be empty")
+	if isBlankContent(content) {
+		return nil, newValidationError("content cannot be empty")
+	}
+	if utf8.RuneCountInString(content) > commentService.Limits.CommentLength {
+		return nil, newValidationError("content exceeds maximum length of %d characters", commentService.Limits.CommentLength)
 	}
-	if len(content) > 2000 {
-		return nil, fmt.Errorf("content exceeds maximum length of 2000 characters")
+
+	// Quoted Comment Validation
+	if quotedCommentID != nil && *quotedCommentID <= 0 {
+		return nil, newValidationError("invalid quoted comment ID: %d", *quotedCommentID)
+	}
+
+	// Reject new comments on locked posts
+	locked, err := commentService.Repo.IsPostLocked(postID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create comment: %w", err)
+	}
+
+	if locked {
+		return nil, ErrPostLocked
+	}
+
+	// Quotes must refer to a comment in the same thread
+	if quotedCommentID != nil {
+		belongs, err := commentService.Repo.CommentBelongsToPost(*quotedCommentID, postID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create comment: %w", err)
+		}
+
+		if !belongs {
+			return nil, newValidationError("quoted comment %d does not exist on post %d", *quotedCommentID, postID)
+		}
+	}
+
+	// Enforce the per-post cap (best effort: concurrent creations may overshoot it slightly)
+	if commentService.MaxCommentsPerPost > 0 {
+		count, err := commentService.Repo.CountCommentsByPostID(postID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create comment: %w", err)
+		}
+
+		if count >= commentService.MaxCommentsPerPost {
+			return nil, fmt.Errorf("%w: post %d already has the maximum of %d comments", ErrLimitReached, postID, commentService.MaxCommentsPerPost)
+		}
+	}
+
+	// Reject repeats of the user's latest comment on this post
+	duplicate, err := commentService.Repo.IsDuplicateComment(postID, userID, content, DuplicateCommentWindow)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create comment: %w", err)
+	}
+
+	if duplicate {
+		return nil, ErrDuplicateComment
 	}
 
 	// Create comment
-	createdComment, err := commentService.Repo.CreateComment(postID, content, userID)
+	createdComment, err := commentService.Repo.CreateComment(postID, content, userID, quotedCommentID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create comment: %w", err)
 	}
 
+	commentService.notifyMentions(createdComment.CommentID, content, userID)
+
+	markCommentOwnership(&userID, createdComment)
+
+	if commentService.Broker != nil {
+		// Ownership is relative to the requesting user, so it is not sent to subscribers
+		published := *createdComment
+		published.IsOwner = false
+		commentService.Broker.Publish(&published)
+	}
+
 	return createdComment, nil
 }
 
+// SubscribeToPost registers for comments created on a post from now on
+// The returned function unsubscribes and must be called once the subscriber is done
+func (commentService *CommentService) SubscribeToPost(postID int) (<-chan *data.Comment, func(), error) {
+	// Validate post ID
+	if postID <= 0 {
+		return nil, nil, fmt.Errorf("invalid post ID: %d", postID)
+	}
+
+	if commentService.Broker == nil {
+		return nil, nil, fmt.Errorf("comment streaming is not enabled")
+	}
+
+	// Verify post exists
+	exists, err := commentService.Repo.PostExists(postID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to verify post ID %d: %w", postID, err)
+	}
+
+	if !exists {
+		return nil, nil, fmt.Errorf("%w with ID: %d", data.ErrPostNotFound, postID)
+	}
+
+	comments, unsubscribe := commentService.Broker.Subscribe(postID)
+
+	return comments, unsubscribe, nil
+}
+
 // UpdateComment updates an existing comment
 func (commentService *CommentService) UpdateComment(commentID int, content string, userID int) (*data.Comment, error) {
+	// Trim surrounding whitespace so blank input is rejected and clean values are stored
+	content = strings.TrimSpace(stripNullBytes(content))
+
 	// Content Validation
-	if strings.TrimSpace(content) == "" {
-		return nil, fmt.Errorf("content cannot be empty")
+	if isBlankContent(content) {
+		return nil, newValidationError("content cannot be empty")
 	}
-	if len(content) > 2000 {
-		return nil, fmt.Errorf("content exceeds maximum length of 2000 characters")
+	if utf8.RuneCountInString(content) > commentService.Limits.CommentLength {
+		return nil, newValidationError("content exceeds maximum length of %d characters", commentService.Limits.CommentLength)
 	}
 
 	// UserID Validation
@@ -94,10 +305,13 @@ func (commentService *CommentService) UpdateComment(commentID int, content strin
 		return nil, fmt.Errorf("failed to update comment: %w", err)
 	}
 
+	markCommentOwnership(&userID, updatedComment)
+
 	return updatedComment, nil
 }
 
 // DeleteComment deletes an existing comment
+// If replies quote it, it is soft-deleted instead so the thread stays intact (see Repository.DeleteComment)
 func (commentService *CommentService) DeleteComment(commentID, userID int) error {
 	// UserID Validation
 	if userID <= 0 {
@@ -0,0 +1,41 @@
+// Run `go test -v ./internal/service -run TestCommentValidation` in /backend
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// Validation runs before any repository call, so no database is required
+func TestCommentValidation(t *testing.T) {
+	commentService := NewCommentService(nil)
+
+	cases := []struct {
+		name    string
+		content string
+	}{
+		{"EmptyContent", ""},
+		{"WhitespaceOnlyContent", "   "},
+		{"ContentExceedingMaxLength", strings.Repeat("a", 2001)},
+		{"NullBytesOnlyContent", "\x00\x00"},
+		{"ZeroWidthSpaceOnlyContent", "\u200B\u200B\u200B"},
+		{"ControlCharactersOnlyContent", "\x01\x02\t\r\n"},
+	}
+
+	for _, tc := range cases {
+		t.Run("Create"+tc.name, func(t *testing.T) {
+			_, err := commentService.CreateComment(1, tc.content, 1, nil)
+			if !errors.Is(err, ErrValidation) {
+				t.Errorf("expected ErrValidation, got %v", err)
+			}
+		})
+
+		t.Run("Update"+tc.name, func(t *testing.T) {
+			_, err := commentService.UpdateComment(1, tc.content, 1)
+			if !errors.Is(err, ErrValidation) {
+				t.Errorf("expected ErrValidation, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package service
+
+import (
+	"strings"
+	"unicode"
+)
+
+// stripNullBytes removes NUL characters, which PostgreSQL rejects in text columns
+func stripNullBytes(content string) string {
+	return strings.ReplaceAll(content, "\x00", "")
+}
+
+// isBlankContent reports whether content has no visible characters
+// i.e. it consists solely of whitespace, control characters, or zero-width (format) characters
+func isBlankContent(content string) bool {
+	for _, r := range content {
+		if !unicode.IsSpace(r) && !unicode.IsControl(r) && !unicode.Is(unicode.Cf, r) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ContentLimits defines the maximum lengths, in characters, of user-written text
+type ContentLimits struct {
+	TitleLength            int `json:"titleLength"` // Topic and post titles
+	TopicDescriptionLength int `json:"topicDescriptionLength"`
+	PostContentLength      int `json:"postContentLength"`
+	CommentLength          int `json:"commentLength"`
+}
+
+// DefaultContentLimits are the limits used unless configured otherwise
+var DefaultContentLimits = ContentLimits{
+	TitleLength:            200,
+	TopicDescriptionLength: 1000,
+	PostContentLength:      5000,
+	CommentLength:          2000,
+}
@@ -0,0 +1,68 @@
+// Run `go test -v ./internal/service -run TestCustomContentLimits` in /backend
+package service
+
+import (
+	"strings"
+	"testing"
+)
+
+// Length checks run before any repository call, so no database is required
+func TestCustomContentLimits(t *testing.T) {
+	limits := ContentLimits{
+		TitleLength:            10,
+		TopicDescriptionLength: 20,
+		PostContentLength:      30,
+		CommentLength:          40,
+	}
+
+	topicService := NewTopicService(nil)
+	topicService.Limits = limits
+
+	postService := NewPostService(nil)
+	postService.Limits = limits
+
+	commentService := NewCommentService(nil)
+	commentService.Limits = limits
+
+	cases := []struct {
+		name    string
+		call    func() error
+		wantMsg string
+	}{
+		{"TopicTitle", func() error {
+			_, err := topicService.CreateTopic(strings.Repeat("a", 11), "Description", 1, false)
+			return err
+		}, "title cannot exceed 10 characters"},
+		{"TopicDescription", func() error {
+			_, err := topicService.UpdateTopicDescription(1, strings.Repeat("a", 21), 1)
+			return err
+		}, "description exceeds maximum length of 20 characters"},
+		{"PostTitle", func() error {
+			_, err := postService.CreatePost(1, strings.Repeat("a", 11), "Content", 1)
+			return err
+		}, "title exceeds maximum length of 10 characters"},
+		{"PostContent", func() error {
+			_, err := postService.UpdatePost(1, "Title", strings.Repeat("a", 31), 1)
+			return err
+		}, "content exceeds maximum length of 30 characters"},
+		{"Comment", func() error {
+			_, err := commentService.CreateComment(1, strings.Repeat("a", 41), 1, nil)
+			return err
+		}, "content exceeds maximum length of 40 characters"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.call()
+			if err == nil || !strings.Contains(err.Error(), tc.wantMsg) {
+				t.Errorf("Expected error containing %q, got %v", tc.wantMsg, err)
+			}
+		})
+	}
+
+	t.Run("DefaultsUnchanged", func(t *testing.T) {
+		if got := NewCommentService(nil).Limits; got != DefaultContentLimits {
+			t.Errorf("Expected default limits %+v, got %+v", DefaultContentLimits, got)
+		}
+	})
+}
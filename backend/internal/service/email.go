@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultEmailTimeout bounds how long a service waits for an email to be handed off
+const DefaultEmailTimeout = 10 * time.Second
+
+// EmailSender delivers plain-text email
+// Services hold one as an optional field, so features stay independent of the provider (and tests can record calls)
+type EmailSender interface {
+	Send(ctx context.Context, to, subject, body string) error
+}
+
+// LogEmailSender writes emails to the log instead of sending them, for local development
+// Bodies may contain secrets such as reset tokens, so it should not be used in production
+type LogEmailSender struct{}
+
+// Send logs the email
+func (LogEmailSender) Send(ctx context.Context, to, subject, body string) error {
+	log.Printf("Email to %s: %s\n%s", to, subject, body)
+	return nil
+}
+
+// SMTPEmailSender sends email through an SMTP server, using PLAIN auth when a username is set
+type SMTPEmailSender struct {
+	Addr string // host:port
+	From string
+	Auth smtp.Auth // nil for servers that need no authentication
+}
+
+// NewSMTPEmailSender creates an SMTPEmailSender for the server at host:port
+func NewSMTPEmailSender(host string, port int, username, password, from string) *SMTPEmailSender {
+	sender := &SMTPEmailSender{
+		Addr: net.JoinHostPort(host, strconv.Itoa(port)),
+		From: from,
+	}
+
+	if username != "" {
+		sender.Auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	return sender
+}
+
+// Send delivers the email; net/smtp cannot be cancelled mid-send, so ctx is only checked beforehand
+func (sender *SMTPEmailSender) Send(ctx context.Context, to, subject, body string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	message, err := buildEmailMessage(sender.From, to, subject, body)
+	if err != nil {
+		return err
+	}
+
+	if err := smtp.SendMail(sender.Addr, sender.Auth, sender.From, []string{to}, message); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+
+	return nil
+}
+
+// buildEmailMessage formats a plain-text RFC 5322 message
+// Header values containing line breaks are rejected, since they could inject extra headers or recipients
+func buildEmailMessage(from, to, subject, body string) ([]byte, error) {
+	for _, value := range []string{from, to, subject} {
+		if strings.ContainsAny(value, "\r\n") {
+			return nil, fmt.Errorf("email header contains a line break: %q", value)
+		}
+	}
+
+	var message strings.Builder
+	message.WriteString("From: " + from + "\r\n")
+	message.WriteString("To: " + to + "\r\n")
+	message.WriteString("Subject: " + subject + "\r\n")
+	message.WriteString("MIME-Version: 1.0\r\n")
+	message.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	message.WriteString("\r\n")
+	message.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+
+	return []byte(message.String()), nil
+}
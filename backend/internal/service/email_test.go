@@ -0,0 +1,41 @@
+// Run `go test -v ./internal/service -run TestBuildEmailMessage` in /backend
+package service
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildEmailMessage(t *testing.T) {
+	// 1. Headers come first, then a blank line and the body with CRLF line endings
+	t.Run("Format", func(t *testing.T) {
+		message, err := buildEmailMessage("no-reply@example.com", "user@example.com", "Hello", "line one\nline two")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		headers, body, found := strings.Cut(string(message), "\r\n\r\n")
+		if !found {
+			t.Fatalf("Expected a blank line between headers and body, got %q", message)
+		}
+
+		for _, header := range []string{"From: no-reply@example.com", "To: user@example.com", "Subject: Hello"} {
+			if !strings.Contains(headers, header+"\r\n") && !strings.HasSuffix(headers, header) {
+				t.Errorf("Expected header %q, got %q", header, headers)
+			}
+		}
+
+		if body != "line one\r\nline two" {
+			t.Errorf("Expected CRLF body, got %q", body)
+		}
+	})
+
+	// 2. Line breaks in header values are refused, so they cannot inject headers
+	t.Run("HeaderInjection", func(t *testing.T) {
+		for _, subject := range []string{"Hi\r\nBcc: victim@example.com", "Hi\nBcc: victim@example.com"} {
+			if _, err := buildEmailMessage("no-reply@example.com", "user@example.com", subject, "body"); err == nil {
+				t.Errorf("Expected an error for subject %q", subject)
+			}
+		}
+	})
+}
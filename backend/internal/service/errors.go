@@ -0,0 +1,87 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrValidation matches (via errors.Is) every input validation error returned by the service layer
+// Handlers use it to map validation failures to 400 without depending on message text
+var ErrValidation = errors.New("validation failed")
+
+// ErrDuplicateComment is returned when a user repeats their latest comment on a post within DuplicateCommentWindow
+var ErrDuplicateComment = errors.New("duplicate comment")
+
+// ErrPostLocked is returned when commenting on a post that has been locked
+var ErrPostLocked = errors.New("post is locked")
+
+// ErrDuplicateTopicTitle is returned when creating a topic whose title matches an existing one, ignoring case
+var ErrDuplicateTopicTitle = errors.New("a topic with this title already exists")
+
+// ErrLoginLocked is returned when a username has failed to log in too many times in a row from the same client
+var ErrLoginLocked = errors.New("too many failed login attempts")
+
+// ErrDailyTopicLimit is returned when a user has already created TopicService.MaxTopicsPerDay topics in the past day
+var ErrDailyTopicLimit = errors.New("daily topic limit reached")
+
+// ErrLimitReached is returned when a topic or post already holds the configured maximum number of posts or comments
+var ErrLimitReached = errors.New("limit reached")
+
+// validationError is an input validation error whose message is safe to return to clients
+type validationError struct {
+	message string
+}
+
+func (e *validationError) Error() string {
+	return e.message
+}
+
+// Is reports whether target is ErrValidation
+func (e *validationError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// newValidationError creates a validation error with a formatted message
+func newValidationError(format string, args ...any) error {
+	return &validationError{message: fmt.Sprintf(format, args...)}
+}
+
+// FieldError is a validation failure of a single input field
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationErrors lists every input field that failed validation, so clients can fix them all at once
+// Like the errors from newValidationError, it matches ErrValidation
+type ValidationErrors []FieldError
+
+// Error joins the field messages, so a single failure reads the same as a plain validation error
+func (errs ValidationErrors) Error() string {
+	messages := make([]string, len(errs))
+	for i, fieldErr := range errs {
+		messages[i] = fieldErr.Message
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+// Is reports whether target is ErrValidation
+func (errs ValidationErrors) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// add records a failure of field with a formatted message
+func (errs *ValidationErrors) add(field, format string, args ...any) {
+	*errs = append(*errs, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// err returns errs as an error, or nil if no field failed
+func (errs ValidationErrors) err() error {
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return errs
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/adzzfarr/gossip-with-go/backend/internal/data"
+)
+
+// IdempotencyKeyTTL is how long an idempotency key is remembered after first use
+const IdempotencyKeyTTL = 24 * time.Hour
+
+// IdempotencyPendingTimeout is how long a claimed key may wait for its resource before the claim is considered
+// abandoned (e.g. the server stopped mid-request) and another request may take it over
+const IdempotencyPendingTimeout = time.Minute
+
+// ErrIdempotencyKeyReused is returned when a key is replayed with a different request
+var ErrIdempotencyKeyReused = errors.New("idempotency key has already been used with a different request")
+
+// ErrIdempotencyKeyInProgress is returned when a key is replayed while the request that first used it is still running
+var ErrIdempotencyKeyInProgress = errors.New("a request with this idempotency key is still in progress")
+
+// IdempotencyService handles business logic related to Idempotency-Key headers via the repository layer
+type IdempotencyService struct {
+	Repo *data.Repository
+}
+
+// NewIdempotencyService creates a new instance of IdempotencyService
+func NewIdempotencyService(repo *data.Repository) *IdempotencyService {
+	return &IdempotencyService{Repo: repo}
+}
+
+// HashRequest returns a stable SHA-256 fingerprint of a request payload
+func (idempotencyService *IdempotencyService) HashRequest(payload any) (string, error) {
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode request for hashing: %w", err)
+	}
+
+	sum := sha256.Sum256(encoded)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Claim reserves key for a new request, to be followed by Complete once the resource exists (or Release if it was not created)
+// Returns claimed=true if the caller should create the resource, or the ID of the resource previously created with the key;
+// ErrIdempotencyKeyInProgress if another request holding the key has not finished, and ErrIdempotencyKeyReused if the
+// key was used for a different request
+func (idempotencyService *IdempotencyService) Claim(userID int, key, resourceType, requestHash string) (int, bool, error) {
+	// Validate input
+	if userID <= 0 {
+		return 0, false, fmt.Errorf("invalid user ID: %d", userID)
+	}
+
+	if key == "" || len(key) > 255 {
+		return 0, false, fmt.Errorf("invalid idempotency key")
+	}
+
+	// Delegate calls to repository layer
+	claimed, err := idempotencyService.Repo.ClaimIdempotencyKey(
+		&data.IdempotencyKey{
+			Key:          key,
+			UserID:       userID,
+			ResourceType: resourceType,
+			RequestHash:  requestHash,
+		},
+		IdempotencyKeyTTL,
+		IdempotencyPendingTimeout,
+	)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to claim idempotency key: %w", err)
+	}
+
+	if claimed {
+		return 0, true, nil
+	}
+
+	record, err := idempotencyService.Repo.GetIdempotencyKey(userID, key, IdempotencyKeyTTL)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to look up idempotency key: %w", err)
+	}
+
+	// Released between the claim and the lookup: the other request failed, so this one may be retried
+	if record == nil {
+		return 0, false, ErrIdempotencyKeyInProgress
+	}
+
+	// Same key must always describe the same request
+	if record.ResourceType != resourceType || record.RequestHash != requestHash {
+		return 0, false, ErrIdempotencyKeyReused
+	}
+
+	if record.ResourceID == nil {
+		return 0, false, ErrIdempotencyKeyInProgress
+	}
+
+	return *record.ResourceID, false, nil
+}
+
+// Complete records the resource created for a claimed idempotency key
+func (idempotencyService *IdempotencyService) Complete(userID int, key string, resourceID int) error {
+	if err := idempotencyService.Repo.CompleteIdempotencyKey(userID, key, resourceID); err != nil {
+		return fmt.Errorf("failed to save idempotency key: %w", err)
+	}
+
+	return nil
+}
+
+// Release frees a claimed idempotency key whose resource was not created, so the client can retry with it
+func (idempotencyService *IdempotencyService) Release(userID int, key string) error {
+	if err := idempotencyService.Repo.ReleaseIdempotencyKey(userID, key); err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+
+	return nil
+}
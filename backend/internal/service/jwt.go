@@ -23,21 +23,28 @@ func NewJWTService(secretKey string, tokenDuration time.Duration) *JWTService {
 
 // JWTClaims struct
 type JWTClaims struct {
-	UserID   int    `json:"userID"`
-	Username string `json:"username"`
+	UserID       int    `json:"userID"`
+	Username     string `json:"username"`
+	TokenVersion int    `json:"tokenVersion"` // User's token version at issue (tokens from before it was bumped are revoked)
 	jwt.RegisteredClaims
 }
 
-// GenerateToken creates a JWT token for a user
-func (jwtService *JWTService) GenerateToken(userID int, username string) (string, error) {
+// TokenExpiry returns when a token issued at now expires
+func (jwtService *JWTService) TokenExpiry(now time.Time) time.Time {
+	return now.Add(jwtService.TokenDuration)
+}
+
+// GenerateToken creates a JWT token for a user, returning it with its expiry (as stored in the token, to the second)
+func (jwtService *JWTService) GenerateToken(userID int, username string, tokenVersion int) (string, time.Time, error) {
 	now := time.Now()
 
 	claims := &JWTClaims{
-		UserID:   userID,   // Private (custom) claim
-		Username: username, // Private (custom) claim
+		UserID:       userID,       // Private (custom) claim
+		Username:     username,     // Private (custom) claim
+		TokenVersion: tokenVersion, // Private (custom) claim
 		RegisteredClaims: jwt.RegisteredClaims{ // Standard claims
 			IssuedAt:  jwt.NewNumericDate(now),
-			ExpiresAt: jwt.NewNumericDate(now.Add(jwtService.TokenDuration)),
+			ExpiresAt: jwt.NewNumericDate(jwtService.TokenExpiry(now)),
 		},
 	}
 
@@ -48,10 +55,10 @@ func (jwtService *JWTService) GenerateToken(userID int, username string) (string
 	signedToken, err := token.SignedString([]byte(jwtService.SecretKey))
 
 	if err != nil {
-		return "", fmt.Errorf("failed to sign JWT token: %w", err)
+		return "", time.Time{}, fmt.Errorf("failed to sign JWT token: %w", err)
 	}
 
-	return signedToken, nil
+	return signedToken, claims.ExpiresAt.Time, nil
 }
 
 // ValidateToken verifies JWT token, returns claims if valid
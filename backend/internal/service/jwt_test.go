@@ -0,0 +1,38 @@
+// Run `go test -v ./internal/service -run TestTokenExpiry` in /backend
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTokenExpiry(t *testing.T) {
+	jwtService := NewJWTService("test-secret-key", 90*time.Minute)
+
+	// 1. Expiry is the configured duration after issue
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	if got, want := jwtService.TokenExpiry(now), now.Add(90*time.Minute); !got.Equal(want) {
+		t.Errorf("Expected expiry %v, got %v", want, got)
+	}
+
+	// 2. The expiry returned with a token is the one stored in it
+	before := time.Now()
+	token, expiresAt, err := jwtService.GenerateToken(1, "alice", 0)
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+
+	claims, err := jwtService.ValidateToken(token)
+	if err != nil {
+		t.Fatalf("Failed to validate token: %v", err)
+	}
+
+	if !expiresAt.Equal(claims.ExpiresAt.Time) {
+		t.Errorf("Expected returned expiry %v to match the token's %v", expiresAt, claims.ExpiresAt.Time)
+	}
+
+	// Tokens store whole seconds, so the expiry may be up to a second before the exact TTL
+	if earliest := jwtService.TokenExpiry(before).Add(-time.Second); expiresAt.Before(earliest) {
+		t.Errorf("Expected expiry no earlier than %v, got %v", earliest, expiresAt)
+	}
+}
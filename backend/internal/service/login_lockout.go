@@ -0,0 +1,118 @@
+package service
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Login lockout defaults
+const (
+	DefaultMaxFailedLogins      = 5
+	DefaultLoginLockoutDuration = 15 * time.Minute
+)
+
+// loginLockoutSweepThreshold is how many tracked keys trigger removal of stale entries
+const loginLockoutSweepThreshold = 10000
+
+// LoginLockout counts consecutive failed logins per username and client IP
+// Once MaxFailures is reached, the key is locked for Duration. Unknown usernames are counted the same way,
+// so a lockout does not reveal whether an account exists.
+// It is in-process only, so each instance keeps its own counts
+type LoginLockout struct {
+	MaxFailures int
+	Duration    time.Duration
+	now         func() time.Time // Replaced in tests
+	mu          sync.Mutex
+	entries     map[string]*loginLockoutEntry
+}
+
+// loginLockoutEntry tracks the failed logins for one key
+type loginLockoutEntry struct {
+	failures    int
+	lastFailure time.Time
+	lockedUntil time.Time // Zero unless locked
+}
+
+// NewLoginLockout creates a new instance of LoginLockout
+func NewLoginLockout(maxFailures int, duration time.Duration) *LoginLockout {
+	return &LoginLockout{
+		MaxFailures: maxFailures,
+		Duration:    duration,
+		now:         time.Now,
+		entries:     make(map[string]*loginLockoutEntry),
+	}
+}
+
+// loginLockoutKey identifies a username as tried from one client
+func loginLockoutKey(username, clientIP string) string {
+	return username + "\x00" + clientIP
+}
+
+// loginLockoutUserKey identifies an account as tried from one client, whichever identifier was used
+// The leading NUL keeps it apart from every username key
+func loginLockoutUserKey(userID int, clientIP string) string {
+	return loginLockoutKey(fmt.Sprintf("\x00user:%d", userID), clientIP)
+}
+
+// Locked reports whether key is locked out
+// An expired lockout is cleared, so the next attempt starts a fresh count
+func (lockout *LoginLockout) Locked(key string) bool {
+	lockout.mu.Lock()
+	defer lockout.mu.Unlock()
+
+	entry, ok := lockout.entries[key]
+	if !ok || entry.lockedUntil.IsZero() {
+		return false
+	}
+
+	if lockout.now().Before(entry.lockedUntil) {
+		return true
+	}
+
+	delete(lockout.entries, key)
+	return false
+}
+
+// RecordFailure counts a failed login for key, locking it once MaxFailures is reached
+// Failures further apart than Duration are not consecutive, so the count starts again
+func (lockout *LoginLockout) RecordFailure(key string) {
+	lockout.mu.Lock()
+	defer lockout.mu.Unlock()
+
+	now := lockout.now()
+
+	if len(lockout.entries) >= loginLockoutSweepThreshold {
+		lockout.sweep(now)
+	}
+
+	entry, ok := lockout.entries[key]
+	if !ok || now.Sub(entry.lastFailure) > lockout.Duration {
+		entry = &loginLockoutEntry{}
+		lockout.entries[key] = entry
+	}
+
+	entry.failures++
+	entry.lastFailure = now
+
+	if entry.failures >= lockout.MaxFailures {
+		entry.lockedUntil = now.Add(lockout.Duration)
+	}
+}
+
+// Reset clears the failed logins for key after a successful login
+func (lockout *LoginLockout) Reset(key string) {
+	lockout.mu.Lock()
+	defer lockout.mu.Unlock()
+
+	delete(lockout.entries, key)
+}
+
+// sweep removes entries that are neither locked nor recent enough to count (caller holds mu)
+func (lockout *LoginLockout) sweep(now time.Time) {
+	for key, entry := range lockout.entries {
+		if now.After(entry.lockedUntil) && now.Sub(entry.lastFailure) > lockout.Duration {
+			delete(lockout.entries, key)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+// Run `go test -v ./internal/service -run TestLoginLockout` in /backend
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoginLockout(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	newLockout := func() *LoginLockout {
+		lockout := NewLoginLockout(3, 15*time.Minute)
+		lockout.now = func() time.Time { return now }
+		return lockout
+	}
+
+	key := loginLockoutKey("alice", "192.0.2.1")
+
+	// 1. Reaching the failure limit locks the key, but only from that client
+	t.Run("Trigger", func(t *testing.T) {
+		lockout := newLockout()
+
+		for i := 0; i < 2; i++ {
+			lockout.RecordFailure(key)
+		}
+		if lockout.Locked(key) {
+			t.Fatalf("expected key to be unlocked before reaching the limit")
+		}
+
+		lockout.RecordFailure(key)
+		if !lockout.Locked(key) {
+			t.Fatalf("expected key to be locked after 3 failures")
+		}
+
+		if lockout.Locked(loginLockoutKey("alice", "192.0.2.2")) {
+			t.Errorf("expected other clients to be unaffected")
+		}
+	})
+
+	// 2. The lockout lifts once the cooldown has passed
+	t.Run("Cooldown", func(t *testing.T) {
+		lockout := newLockout()
+		start := now
+		defer func() { now = start }()
+
+		for i := 0; i < 3; i++ {
+			lockout.RecordFailure(key)
+		}
+
+		now = start.Add(14 * time.Minute)
+		if !lockout.Locked(key) {
+			t.Fatalf("expected key to still be locked during cooldown")
+		}
+
+		now = start.Add(15*time.Minute + time.Second)
+		if lockout.Locked(key) {
+			t.Fatalf("expected key to be unlocked after cooldown")
+		}
+
+		// The count starts again after a lockout expires
+		lockout.RecordFailure(key)
+		if lockout.Locked(key) {
+			t.Errorf("expected a single failure after cooldown not to lock the key")
+		}
+	})
+
+	// 3. A successful login resets the count
+	t.Run("Reset", func(t *testing.T) {
+		lockout := newLockout()
+
+		for i := 0; i < 2; i++ {
+			lockout.RecordFailure(key)
+		}
+		lockout.Reset(key)
+		lockout.RecordFailure(key)
+
+		if lockout.Locked(key) {
+			t.Errorf("expected failures before a successful login not to count")
+		}
+	})
+
+	// 4. Account keys never coincide with username keys
+	t.Run("AccountKeys", func(t *testing.T) {
+		if loginLockoutUserKey(1, "192.0.2.1") == loginLockoutKey("user:1", "192.0.2.1") {
+			t.Errorf("expected account and username keys to differ")
+		}
+
+		if normalizeLoginIdentifier(" Alice@Example.COM") != normalizeLoginIdentifier("alice@example.com") {
+			t.Errorf("expected email spellings to normalize to the same identifier")
+		}
+	})
+
+	// 5. Failures further apart than the cooldown are not consecutive
+	t.Run("StaleFailures", func(t *testing.T) {
+		lockout := newLockout()
+		start := now
+		defer func() { now = start }()
+
+		for i := 0; i < 2; i++ {
+			lockout.RecordFailure(key)
+		}
+
+		now = start.Add(time.Hour)
+		lockout.RecordFailure(key)
+
+		if lockout.Locked(key) {
+			t.Errorf("expected stale failures not to count towards a lockout")
+		}
+	})
+}
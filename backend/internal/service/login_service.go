@@ -1,7 +1,10 @@
 package service
 
 import (
+	"errors"
 	"fmt"
+	"log"
+	"strings"
 
 	"github.com/adzzfarr/gossip-with-go/backend/internal/data"
 	"golang.org/x/crypto/bcrypt"
@@ -9,7 +12,8 @@ import (
 
 // LoginService handles business logic related to login via the repository layer
 type LoginService struct {
-	Repo *data.Repository
+	Repo    *data.Repository
+	Lockout *LoginLockout // Optional (nil disables lockout)
 }
 
 // NewLoginService creates a new instance of LoginService
@@ -17,21 +21,33 @@ func NewLoginService(repo *data.Repository) *LoginService {
 	return &LoginService{Repo: repo}
 }
 
-// Login authenticates a user with given username and password
-func (loginService *LoginService) Login(username, password string) (*data.User, error) {
+// Login authenticates a user with given identifier (username or email) and password
+// Returns ErrLoginLocked if the account (or, if none matches, the identifier) has failed too many times in a row from clientIP
+func (loginService *LoginService) Login(identifier, password, clientIP string) (*data.User, error) {
 	// Validate input
-	if username == "" || password == "" {
+	if identifier == "" || password == "" {
 		return nil, fmt.Errorf("username and password cannot be empty")
 	}
 
 	// Delegate call to repository layer
-	user, err := loginService.Repo.GetUserByUsername(username)
+	user, err := lookupUserByIdentifier(loginService.Repo, identifier)
 
-	if err != nil {
+	if err != nil && !errors.Is(err, data.ErrUserNotFound) {
 		return nil, fmt.Errorf("failed to retrieve user: %w", err)
 	}
 
+	// Failures count against the account, so its username and every spelling of its email share one count
+	lockoutKey := loginLockoutKey(normalizeLoginIdentifier(identifier), clientIP)
+	if user != nil {
+		lockoutKey = loginLockoutUserKey(user.UserID, clientIP)
+	}
+
+	if loginService.Lockout != nil && loginService.Lockout.Locked(lockoutKey) {
+		return nil, ErrLoginLocked
+	}
+
 	if user == nil {
+		loginService.recordFailure(lockoutKey)
 		return nil, fmt.Errorf("user not found")
 	}
 
@@ -39,8 +55,46 @@ func (loginService *LoginService) Login(username, password string) (*data.User,
 	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password))
 
 	if err != nil {
+		loginService.recordFailure(lockoutKey)
 		return nil, fmt.Errorf("invalid password")
 	}
 
+	if loginService.Lockout != nil {
+		loginService.Lockout.Reset(lockoutKey)
+	}
+
+	// Record the login; failing to do so should not stop the user logging in
+	if err := loginService.Repo.UpdateLastLogin(user.UserID); err != nil {
+		log.Printf("Failed to record last login for user %d: %v", user.UserID, err)
+	}
+
 	return user, nil
 }
+
+// lookupUserByIdentifier finds the user an identifier refers to: the (case-insensitive) email if it contains an @, else the username
+// Identifiers with an @ never match usernames, so nobody can claim another person's email as their username to intercept it
+func lookupUserByIdentifier(repo *data.Repository, identifier string) (*data.User, error) {
+	identifier = normalizeLoginIdentifier(identifier)
+
+	if strings.Contains(identifier, "@") {
+		return repo.GetUserByEmail(identifier)
+	}
+
+	return repo.GetUserByUsername(identifier)
+}
+
+// normalizeLoginIdentifier returns identifier as it is looked up: emails trimmed and lowercased, usernames unchanged
+func normalizeLoginIdentifier(identifier string) string {
+	if strings.Contains(identifier, "@") {
+		return strings.ToLower(strings.TrimSpace(identifier))
+	}
+
+	return identifier
+}
+
+// recordFailure counts a failed login towards the lockout, if enabled
+func (loginService *LoginService) recordFailure(lockoutKey string) {
+	if loginService.Lockout != nil {
+		loginService.Lockout.RecordFailure(lockoutKey)
+	}
+}
@@ -59,7 +59,7 @@ func TestLoginService(t *testing.T) {
 
 	// 1. Successful login
 	t.Run("SuccessfulLogin", func(t *testing.T) {
-		user, err := loginService.Login(testUsername, testPassword)
+		user, err := loginService.Login(testUsername, testPassword, "127.0.0.1")
 
 		if err != nil {
 			t.Fatalf("Expected successful login, got error: %v", err)
@@ -76,7 +76,7 @@ func TestLoginService(t *testing.T) {
 
 	// 2. Wrong password
 	t.Run("WrongPassword", func(t *testing.T) {
-		user, err := loginService.Login(testUsername, "wrongpassword")
+		user, err := loginService.Login(testUsername, "wrongpassword", "127.0.0.1")
 
 		if err == nil {
 			t.Fatal("Expected error for wrong password, got nil")
@@ -93,7 +93,7 @@ func TestLoginService(t *testing.T) {
 
 	// 3. Non-existent user
 	t.Run("NonExistentUser", func(t *testing.T) {
-		user, err := loginService.Login("nonexistentuser", testPassword)
+		user, err := loginService.Login("nonexistentuser", testPassword, "127.0.0.1")
 
 		if err == nil {
 			t.Fatal("Expected error for non-existent user, got nil")
@@ -110,7 +110,7 @@ func TestLoginService(t *testing.T) {
 
 	// 4. Empty Username
 	t.Run("EmptyUsername", func(t *testing.T) {
-		user, err := loginService.Login("", testPassword)
+		user, err := loginService.Login("", testPassword, "127.0.0.1")
 
 		if err == nil {
 			t.Fatal("Expected error for empty username, got nil")
@@ -127,7 +127,7 @@ func TestLoginService(t *testing.T) {
 
 	// 5. Empty Password
 	t.Run("EmptyPassword", func(t *testing.T) {
-		user, err := loginService.Login(testUsername, "")
+		user, err := loginService.Login(testUsername, "", "127.0.0.1")
 
 		if err == nil {
 			t.Fatal("Expected error for empty password, got nil")
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"errors"
+	"log"
+	"regexp"
+
+	"github.com/adzzfarr/gossip-with-go/backend/internal/data"
+)
+
+// maxMentionsPerComment caps how many distinct usernames in one comment are looked up and notified
+const maxMentionsPerComment = 20
+
+// mentionPattern matches @username tokens in comment content
+var mentionPattern = regexp.MustCompile(`@([a-zA-Z0-9_]{3,30})`)
+
+// extractMentions returns the distinct usernames mentioned in content, in order of first appearance
+func extractMentions(content string) []string {
+	var usernames []string
+	seen := make(map[string]bool)
+
+	for _, match := range mentionPattern.FindAllStringSubmatch(content, -1) {
+		username := match[1]
+		if seen[username] {
+			continue
+		}
+
+		seen[username] = true
+		usernames = append(usernames, username)
+
+		if len(usernames) == maxMentionsPerComment {
+			break
+		}
+	}
+
+	return usernames
+}
+
+// notifyMentions notifies the users mentioned in a newly created comment
+// Unknown usernames and the author mentioning themselves are skipped
+// Failures are logged rather than returned, since the comment has already been created
+func (commentService *CommentService) notifyMentions(commentID int, content string, authorID int) {
+	usernames := extractMentions(content)
+	if len(usernames) == 0 {
+		return
+	}
+
+	var userIDs []int
+	for _, username := range usernames {
+		user, err := commentService.Repo.GetUserByUsername(username)
+		if err != nil {
+			if errors.Is(err, data.ErrUserNotFound) {
+				continue
+			}
+
+			log.Printf("Failed to resolve mention @%s in comment %d: %v", username, commentID, err)
+			return
+		}
+
+		if user.UserID != authorID {
+			userIDs = append(userIDs, user.UserID)
+		}
+	}
+
+	if len(userIDs) == 0 {
+		return
+	}
+
+	if _, err := commentService.Repo.CreateMentions(commentID, authorID, userIDs); err != nil {
+		log.Printf("Failed to record mentions in comment %d: %v", commentID, err)
+	}
+}
@@ -0,0 +1,44 @@
+// Run `go test -v ./internal/service -run TestExtractMentions` in /backend
+package service
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExtractMentions(t *testing.T) {
+	cases := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{"NoMentions", "Nothing to see here", nil},
+		{"SingleMention", "Thanks @alice!", []string{"alice"}},
+		{"RepeatedMention", "@alice @bob @alice", []string{"alice", "bob"}},
+		{"TooShort", "hi @al", nil},
+		{"LongUsernameTruncated", "@" + strings.Repeat("a", 35), []string{strings.Repeat("a", 30)}},
+		{"Underscores", "cc @some_user_1.", []string{"some_user_1"}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := extractMentions(tc.content)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("extractMentions(%q) = %v, want %v", tc.content, got, tc.want)
+			}
+		})
+	}
+
+	t.Run("CappedPerComment", func(t *testing.T) {
+		var content strings.Builder
+		for i := 0; i < maxMentionsPerComment+5; i++ {
+			fmt.Fprintf(&content, "@user_%d ", i)
+		}
+
+		if got := extractMentions(content.String()); len(got) != maxMentionsPerComment {
+			t.Errorf("Expected %d mentions, got %d", maxMentionsPerComment, len(got))
+		}
+	})
+}
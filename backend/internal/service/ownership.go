@@ -0,0 +1,19 @@
+package service
+
+import "github.com/adzzfarr/gossip-with-go/backend/internal/data"
+
+// markPostOwnership sets IsOwner on posts created by the requesting user
+// userID is nil for anonymous requests, in which case no post is owned
+func markPostOwnership(userID *int, posts ...*data.Post) {
+	for _, post := range posts {
+		post.IsOwner = userID != nil && post.CreatedBy == *userID
+	}
+}
+
+// markCommentOwnership sets IsOwner on comments created by the requesting user
+// userID is nil for anonymous requests, in which case no comment is owned
+func markCommentOwnership(userID *int, comments ...*data.Comment) {
+	for _, comment := range comments {
+		comment.IsOwner = userID != nil && comment.CreatedBy == *userID
+	}
+}
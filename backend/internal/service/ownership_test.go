@@ -0,0 +1,40 @@
+// Run `go test -v ./internal/service -run TestMarkOwnership` in /backend
+package service
+
+import (
+	"testing"
+
+	"github.com/adzzfarr/gossip-with-go/backend/internal/data"
+)
+
+func TestMarkOwnership(t *testing.T) {
+	ownerID, otherID := 1, 2
+
+	cases := []struct {
+		name     string
+		userID   *int
+		expected bool
+	}{
+		{"Owner", &ownerID, true},
+		{"NonOwner", &otherID, false},
+		{"Anonymous", nil, false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			post := &data.Post{CreatedBy: ownerID}
+			comment := &data.Comment{CreatedBy: ownerID}
+
+			markPostOwnership(tc.userID, post)
+			markCommentOwnership(tc.userID, comment)
+
+			if post.IsOwner != tc.expected {
+				t.Errorf("expected post isOwner %v, got %v", tc.expected, post.IsOwner)
+			}
+
+			if comment.IsOwner != tc.expected {
+				t.Errorf("expected comment isOwner %v, got %v", tc.expected, comment.IsOwner)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package service
+
+import (
+	_ "embed"
+	"strings"
+)
+
+//go:embed common_passwords.txt
+var commonPasswords string
+
+// PasswordBlocklist is a set of passwords that are rejected regardless of the password policy
+// A nil blocklist rejects nothing
+type PasswordBlocklist map[string]struct{}
+
+// NewPasswordBlocklist creates a blocklist from the given passwords (matched case-insensitively)
+func NewPasswordBlocklist(passwords []string) PasswordBlocklist {
+	blocklist := make(PasswordBlocklist, len(passwords))
+	for _, password := range passwords {
+		blocklist[strings.ToLower(password)] = struct{}{}
+	}
+
+	return blocklist
+}
+
+// DefaultPasswordBlocklist creates a blocklist of the most common passwords, embedded from common_passwords.txt
+func DefaultPasswordBlocklist() PasswordBlocklist {
+	var passwords []string
+	for _, line := range strings.Split(commonPasswords, "\n") {
+		line = strings.TrimSpace(line)
+
+		// Skip blank lines and comments
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		passwords = append(passwords, line)
+	}
+
+	return NewPasswordBlocklist(passwords)
+}
+
+// Contains reports whether the password is on the blocklist, ignoring case
+func (blocklist PasswordBlocklist) Contains(password string) bool {
+	_, found := blocklist[strings.ToLower(password)]
+	return found
+}
@@ -0,0 +1,46 @@
+// Run `go test -v ./internal/service -run TestPasswordBlocklist` in /backend
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestPasswordBlocklist(t *testing.T) {
+	// 1. Injected blocklist is matched case-insensitively during registration
+	t.Run("RegisterUserRejectsBlocklisted", func(t *testing.T) {
+		userService := NewUserService(nil, DefaultPasswordPolicy, NewPasswordBlocklist([]string{"Forum2024"}))
+
+		_, err := userService.RegisterUser("blocklist_user", "fORUM2024", "")
+		if err == nil || !strings.Contains(err.Error(), "this password is too common") {
+			t.Errorf("expected 'this password is too common' error, got %v", err)
+		}
+
+		if !errors.Is(err, ErrValidation) {
+			t.Errorf("expected a validation error, got %v", err)
+		}
+	})
+
+	// 2. Embedded default list covers passwords that pass the complexity rules
+	t.Run("DefaultListContainsPassword1", func(t *testing.T) {
+		blocklist := DefaultPasswordBlocklist()
+
+		if !blocklist.Contains("Password1") {
+			t.Error("expected default blocklist to contain 'Password1'")
+		}
+
+		if blocklist.Contains("correct-Horse-battery-7") {
+			t.Error("expected uncommon password not to be blocklisted")
+		}
+	})
+
+	// 3. A nil blocklist rejects nothing
+	t.Run("NilBlocklist", func(t *testing.T) {
+		var blocklist PasswordBlocklist
+
+		if blocklist.Contains("password1") {
+			t.Error("expected nil blocklist to reject nothing")
+		}
+	})
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// symbolRegex matches any character that is not a letter, digit or whitespace
+var symbolRegex = regexp.MustCompile(`[^\p{L}\p{N}\s]`)
+
+// PasswordPolicy defines the strength rules new passwords must satisfy
+type PasswordPolicy struct {
+	MinLength     int  `json:"minLength"`
+	RequireLower  bool `json:"requireLower"`
+	RequireUpper  bool `json:"requireUpper"`
+	RequireDigit  bool `json:"requireDigit"`
+	RequireSymbol bool `json:"requireSymbol"`
+}
+
+// DefaultPasswordPolicy requires at least 8 characters with a lowercase letter, an uppercase letter and a digit
+var DefaultPasswordPolicy = PasswordPolicy{
+	MinLength:    8,
+	RequireLower: true,
+	RequireUpper: true,
+	RequireDigit: true,
+}
+
+// Validate checks a password against the policy, returning the first rule it breaks
+func (policy PasswordPolicy) Validate(password string) error {
+	if len(password) < policy.MinLength {
+		return fmt.Errorf("password must be at least %d characters", policy.MinLength)
+	}
+
+	if policy.RequireLower && !lowercaseRegex.MatchString(password) {
+		return fmt.Errorf("password must contain at least one lowercase letter")
+	}
+
+	if policy.RequireUpper && !uppercaseRegex.MatchString(password) {
+		return fmt.Errorf("password must contain at least one uppercase letter")
+	}
+
+	if policy.RequireDigit && !digitRegex.MatchString(password) {
+		return fmt.Errorf("password must contain at least one digit")
+	}
+
+	if policy.RequireSymbol && !symbolRegex.MatchString(password) {
+		return fmt.Errorf("password must contain at least one symbol")
+	}
+
+	return nil
+}
@@ -0,0 +1,54 @@
+// Run `go test -v ./internal/service -run TestPasswordPolicy` in /backend
+package service
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPasswordPolicy(t *testing.T) {
+	symbolPolicy := DefaultPasswordPolicy
+	symbolPolicy.RequireSymbol = true
+	symbolPolicy.MinLength = 10
+
+	cases := []struct {
+		name     string
+		policy   PasswordPolicy
+		password string
+		errorMsg string // Empty if the password should be accepted
+	}{
+		{"DefaultAccepts", DefaultPasswordPolicy, "Password1", ""},
+		{"DefaultTooShort", DefaultPasswordPolicy, "Pass1", "at least 8 characters"},
+		{"DefaultMissingUpper", DefaultPasswordPolicy, "password1", "uppercase letter"},
+		{"SymbolPolicyMissingSymbol", symbolPolicy, "Password123", "at least one symbol"},
+		{"SymbolPolicyTooShort", symbolPolicy, "Passw0rd!", "at least 10 characters"},
+		{"SymbolPolicyAccepts", symbolPolicy, "Passw0rd!23", ""},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.policy.Validate(tc.password)
+
+			if tc.errorMsg == "" {
+				if err != nil {
+					t.Errorf("expected password to be accepted, got %v", err)
+				}
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), tc.errorMsg) {
+				t.Errorf("expected error containing '%s', got %v", tc.errorMsg, err)
+			}
+		})
+	}
+
+	// Registration enforces the injected policy before touching the repository
+	t.Run("RegisterUserUsesPolicy", func(t *testing.T) {
+		userService := NewUserService(nil, symbolPolicy, nil)
+
+		_, err := userService.RegisterUser("policy_user", "Password123", "")
+		if err == nil || !strings.Contains(err.Error(), "at least one symbol") {
+			t.Errorf("expected symbol requirement error, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/adzzfarr/gossip-with-go/backend/internal/data"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// DefaultResetTokenTTL is how long a password reset token stays valid unless configured otherwise
+const DefaultResetTokenTTL = time.Hour
+
+// hashResetToken returns the hex SHA-256 of a reset token, as stored in password_resets
+// Tokens are long and random, so a fast unsalted hash is enough to make a leaked table useless
+func hashResetToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// passwordResetEmail is the body of the email carrying a reset token (token, then minutes it is valid for)
+const passwordResetEmail = `Someone asked to reset the password for your account.
+
+Reset token: %s
+
+It can be used once within %d minutes. If this wasn't you, you can ignore this email.
+`
+
+// RequestPasswordReset emails a single-use reset token to the user with the given username or email
+// Unknown identifiers, and accounts without an email (or with email disabled), are silently ignored
+// so callers cannot tell which accounts exist
+func (service *UserService) RequestPasswordReset(identifier string) error {
+	if service.Email == nil {
+		return nil
+	}
+
+	user, err := lookupUserByIdentifier(service.Repo, identifier)
+	if err != nil {
+		if errors.Is(err, data.ErrUserNotFound) {
+			return nil
+		}
+
+		return fmt.Errorf("failed to look up user: %w", err)
+	}
+
+	if user.Email == nil {
+		return nil
+	}
+
+	// 32 random bytes, URL-safe so the token can be embedded in a reset link
+	tokenBytes := make([]byte, 32)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return fmt.Errorf("failed to generate reset token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(tokenBytes)
+
+	ttl := service.ResetTokenTTL
+	if ttl <= 0 {
+		ttl = DefaultResetTokenTTL
+	}
+
+	// Delegate call to repository layer
+	if err := service.Repo.CreatePasswordReset(user.UserID, hashResetToken(token), ttl); err != nil {
+		return fmt.Errorf("failed to request password reset: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultEmailTimeout)
+	defer cancel()
+
+	// Failing to deliver is only logged; reporting it would reveal that the account exists
+	err = service.Email.Send(ctx, *user.Email, "Reset your password", fmt.Sprintf(passwordResetEmail, token, int(ttl.Minutes())))
+	if err != nil {
+		log.Printf("Failed to send password reset email to user %d: %v", user.UserID, err)
+	}
+
+	return nil
+}
+
+// ResetPassword sets a new password (subject to the same rules as registration) using a reset token
+// Returns data.ErrInvalidResetToken (wrapped) if the token is unknown, expired or already used
+func (service *UserService) ResetPassword(token, newPassword string) error {
+	// Input Validation
+	if token == "" {
+		return fmt.Errorf("failed to reset password: %w", data.ErrInvalidResetToken)
+	}
+
+	if err := service.validatePassword(newPassword); err != nil {
+		return newValidationError("%s", err.Error())
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	// Delegate call to repository layer
+	if err := service.Repo.ResetPassword(hashResetToken(token), string(hashedPassword)); err != nil {
+		return fmt.Errorf("failed to reset password: %w", err)
+	}
+
+	return nil
+}
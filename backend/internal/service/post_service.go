@@ -3,41 +3,96 @@ package service
 import (
 	"fmt"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/adzzfarr/gossip-with-go/backend/internal/data"
 )
 
+// MaxBulkCreatePosts is the maximum number of posts that can be imported in one request
+const MaxBulkCreatePosts = 1000
+
 // PostService handles business logic related to posts via the repository layer
 type PostService struct {
-	Repo *data.Repository
+	Repo             *data.Repository
+	Webhooks         *WebhookService // Optional; notified of newly created posts when set
+	MaxPostsPerTopic int             // Posts a topic may hold before CreatePost is refused; 0 means unlimited
+	Limits           ContentLimits   // Maximum title and content lengths; DefaultContentLimits unless replaced
 }
 
 // NewPostService creates a new instance of PostService
 func NewPostService(repo *data.Repository) *PostService {
-	return &PostService{Repo: repo}
+	return &PostService{Repo: repo, Limits: DefaultContentLimits}
 }
 
-// GetPostsByTopicID retrieves all posts for a given topic ID using the repository layer
-func (service *PostService) GetPostsByTopicID(topicID int, userID *int) ([]*data.Post, error) {
+// GetPostsByTopicID retrieves a page of posts for a given topic ID, ordered by sort (see data.PostSortNew etc.)
+func (service *PostService) GetPostsByTopicID(topicID int, userID *int, sort string, limit, offset int) (*data.PagedResponse[*data.Post], error) {
 	// TopicID Validation
 	if topicID <= 0 {
 		return nil, fmt.Errorf("invalid topic ID: %d", topicID)
 	}
 
+	// Pagination Validation
+	if limit <= 0 {
+		return nil, fmt.Errorf("invalid limit: %d", limit)
+	}
+
+	if offset < 0 {
+		return nil, fmt.Errorf("invalid offset: %d", offset)
+	}
+
+	// Validate sort order
+	if !data.IsValidPostSort(sort) {
+		return nil, newValidationError("invalid sort: must be one of %s, %s", data.PostSortNew, data.PostSortControversial)
+	}
+
+	// Verify topic exists, so a missing topic is not mistaken for an empty one
+	exists, err := service.Repo.TopicExists(topicID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify topic ID %d: %w", topicID, err)
+	}
+
+	if !exists {
+		return nil, fmt.Errorf("%w: %d", data.ErrTopicNotFound, topicID)
+	}
+
 	// Delegate call to repository layer
-	posts, err := service.Repo.GetPostsByTopicID(topicID, userID)
+	posts, err := service.Repo.GetPostsByTopicID(topicID, userID, sort, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get posts for topic ID %d: %w", topicID, err)
 	}
 
-	if posts == nil {
+	if posts.Items == nil {
 		// Return empty slice
-		posts = []*data.Post{}
+		posts.Items = []*data.Post{}
 	}
 
+	markPostOwnership(userID, posts.Items...)
+
 	return posts, nil
 }
 
+// ForEachPostInTopic streams a topic's posts, oldest first, to fn (e.g. for exports)
+// Returns a not found error before calling fn if the topic does not exist
+func (postService *PostService) ForEachPostInTopic(topicID int, fn func(*data.Post) error) error {
+	// TopicID Validation
+	if topicID <= 0 {
+		return fmt.Errorf("invalid topic ID: %d", topicID)
+	}
+
+	// Verify topic exists, so a missing topic is not mistaken for an empty one
+	exists, err := postService.Repo.TopicExists(topicID)
+	if err != nil {
+		return fmt.Errorf("failed to verify topic ID %d: %w", topicID, err)
+	}
+
+	if !exists {
+		return fmt.Errorf("%w: %d", data.ErrTopicNotFound, topicID)
+	}
+
+	// Delegate call to repository layer
+	return postService.Repo.ForEachPostInTopic(topicID, fn)
+}
+
 // GetPostByID retrieves a specific post by its ID
 func (postService *PostService) GetPostByID(postID int, userID *int) (*data.Post, error) {
 	// PostID Validation
@@ -51,6 +106,26 @@ func (postService *PostService) GetPostByID(postID int, userID *int) (*data.Post
 		return nil, fmt.Errorf("failed to get post by ID %d: %w", postID, err)
 	}
 
+	markPostOwnership(userID, post)
+
+	return post, nil
+}
+
+// GetPostWithTopic retrieves a specific post by its ID together with its topic
+func (postService *PostService) GetPostWithTopic(postID int, userID *int) (*data.Post, error) {
+	// PostID Validation
+	if postID <= 0 {
+		return nil, fmt.Errorf("invalid post ID: %d", postID)
+	}
+
+	// Delegate call to repository layer
+	post, err := postService.Repo.GetPostWithTopic(postID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get post with topic by ID %d: %w", postID, err)
+	}
+
+	markPostOwnership(userID, post)
+
 	return post, nil
 }
 
@@ -61,20 +136,27 @@ func (postService *PostService) CreatePost(topicID int, title, content string, u
 		return nil, fmt.Errorf("invalid topic ID: %d", topicID)
 	}
 
-	// Title Validation
+	// Trim surrounding whitespace so blank input is rejected and clean values are stored
+	title = strings.TrimSpace(title)
+	content = strings.TrimSpace(stripNullBytes(content))
+
+	// Field Validation (every failing field is reported)
+	var validationErrs ValidationErrors
+
 	if title == "" {
-		return nil, fmt.Errorf("title cannot be empty")
-	}
-	if len(title) > 200 {
-		return nil, fmt.Errorf("title exceeds maximum length of 200 characters")
+		validationErrs.add("title", "title cannot be empty")
+	} else if utf8.RuneCountInString(title) > postService.Limits.TitleLength {
+		validationErrs.add("title", "title exceeds maximum length of %d characters", postService.Limits.TitleLength)
 	}
 
-	// Content Validation
-	if content == "" {
-		return nil, fmt.Errorf("content cannot be empty")
+	if isBlankContent(content) {
+		validationErrs.add("content", "content cannot be empty")
+	} else if utf8.RuneCountInString(content) > postService.Limits.PostContentLength {
+		validationErrs.add("content", "content exceeds maximum length of %d characters", postService.Limits.PostContentLength)
 	}
-	if len(content) > 5000 {
-		return nil, fmt.Errorf("content exceeds maximum length of 5000 characters")
+
+	if err := validationErrs.err(); err != nil {
+		return nil, err
 	}
 
 	// UserID Validation
@@ -82,33 +164,121 @@ func (postService *PostService) CreatePost(topicID int, title, content string, u
 		return nil, fmt.Errorf("invalid user ID: %d", userID)
 	}
 
+	// Enforce the per-topic cap (best effort: concurrent creations may overshoot it slightly)
+	if postService.MaxPostsPerTopic > 0 {
+		count, err := postService.Repo.CountPostsInTopic(topicID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create post: %w", err)
+		}
+
+		if count >= postService.MaxPostsPerTopic {
+			return nil, fmt.Errorf("%w: topic %d already has the maximum of %d posts", ErrLimitReached, topicID, postService.MaxPostsPerTopic)
+		}
+	}
+
 	// Delegate call to repository layer
 	post, err := postService.Repo.CreatePost(topicID, title, content, userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create post: %w", err)
 	}
 
+	markPostOwnership(&userID, post)
+
+	if postService.Webhooks != nil {
+		postService.Webhooks.NotifyPostCreated(post)
+	}
+
 	return post, nil
 }
 
+// CreatePostsBatch validates and creates many posts at once on behalf of an admin, e.g. when importing content
+// Posts without an author are attributed to adminID. Any invalid post rejects the whole batch, with errors naming
+// each failing post's index (e.g. "posts[3].title"). Imports skip the per-topic cap and webhooks
+func (postService *PostService) CreatePostsBatch(posts []data.PostInput, adminID int) ([]*data.Post, error) {
+	// Batch Size Validation
+	if len(posts) == 0 {
+		return nil, newValidationError("posts cannot be empty")
+	}
+
+	if len(posts) > MaxBulkCreatePosts {
+		return nil, newValidationError("too many posts: maximum is %d", MaxBulkCreatePosts)
+	}
+
+	// Field Validation (every failing field of every post is reported)
+	var validationErrs ValidationErrors
+
+	inputs := make([]data.PostInput, len(posts))
+	for i, post := range posts {
+		// Trim surrounding whitespace so blank input is rejected and clean values are stored
+		post.Title = strings.TrimSpace(post.Title)
+		post.Content = strings.TrimSpace(stripNullBytes(post.Content))
+
+		if post.CreatedBy == 0 {
+			post.CreatedBy = adminID
+		}
+
+		field := fmt.Sprintf("posts[%d]", i)
+
+		if post.TopicID <= 0 {
+			validationErrs.add(field+".topicID", "%s: invalid topic ID: %d", field, post.TopicID)
+		}
+
+		if post.CreatedBy < 0 {
+			validationErrs.add(field+".createdBy", "%s: invalid user ID: %d", field, post.CreatedBy)
+		}
+
+		if post.Title == "" {
+			validationErrs.add(field+".title", "%s: title cannot be empty", field)
+		} else if utf8.RuneCountInString(post.Title) > postService.Limits.TitleLength {
+			validationErrs.add(field+".title", "%s: title exceeds maximum length of %d characters", field, postService.Limits.TitleLength)
+		}
+
+		if isBlankContent(post.Content) {
+			validationErrs.add(field+".content", "%s: content cannot be empty", field)
+		} else if utf8.RuneCountInString(post.Content) > postService.Limits.PostContentLength {
+			validationErrs.add(field+".content", "%s: content exceeds maximum length of %d characters", field, postService.Limits.PostContentLength)
+		}
+
+		inputs[i] = post
+	}
+
+	if err := validationErrs.err(); err != nil {
+		return nil, err
+	}
+
+	// Delegate call to repository layer
+	created, err := postService.Repo.CreatePostsBatch(inputs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create posts: %w", err)
+	}
+
+	markPostOwnership(&adminID, created...)
+
+	return created, nil
+}
+
 // UpdatePost updates an existing post
 func (postService *PostService) UpdatePost(postID int, title, content string, userID int) (*data.Post, error) {
+	// Trim surrounding whitespace so blank input is rejected and clean values are stored
+	title = strings.TrimSpace(title)
+	content = strings.TrimSpace(stripNullBytes(content))
+
 	// Title Validation
-	if strings.TrimSpace(title) == "" {
+	if title == "" {
 		return nil, fmt.Errorf("title cannot be empty")
 	}
 
-	if len(title) > 200 {
-		return nil, fmt.Errorf("title exceeds maximum length of 200 characters")
+	if utf8.RuneCountInString(title) > postService.Limits.TitleLength {
+		return nil, fmt.Errorf("title exceeds maximum length of %d characters", postService.Limits.TitleLength)
 	}
 
 	// Content Validation
-	if strings.TrimSpace(content) == "" {
+	if isBlankContent(content) {
 		return nil, fmt.Errorf("content cannot be empty")
 	}
 
-	if len(content) > 5000 {
-		return nil, fmt.Errorf("content exceeds maximum length of 5000 characters")
+	if utf8.RuneCountInString(content) > postService.Limits.PostContentLength {
+		return nil, fmt.Errorf("content exceeds maximum length of %d characters", postService.Limits.PostContentLength)
 	}
 
 	// UserID Validation
@@ -123,6 +293,64 @@ func (postService *PostService) UpdatePost(postID int, title, content string, us
 		return nil, fmt.Errorf("failed to update post: %w", err)
 	}
 
+	markPostOwnership(&userID, updatedPost)
+
+	return updatedPost, nil
+}
+
+// PatchPost updates only the provided fields of an existing post
+func (postService *PostService) PatchPost(postID int, title, content *string, userID int) (*data.Post, error) {
+	if title == nil && content == nil {
+		return nil, fmt.Errorf("no fields to update")
+	}
+
+	// Trim surrounding whitespace so blank input is rejected and clean values are stored
+	if title != nil {
+		trimmed := strings.TrimSpace(*title)
+		title = &trimmed
+	}
+
+	if content != nil {
+		trimmed := strings.TrimSpace(stripNullBytes(*content))
+		content = &trimmed
+	}
+
+	// Title Validation
+	if title != nil {
+		if *title == "" {
+			return nil, fmt.Errorf("title cannot be empty")
+		}
+
+		if utf8.RuneCountInString(*title) > postService.Limits.TitleLength {
+			return nil, fmt.Errorf("title exceeds maximum length of %d characters", postService.Limits.TitleLength)
+		}
+	}
+
+	// Content Validation
+	if content != nil {
+		if isBlankContent(*content) {
+			return nil, fmt.Errorf("content cannot be empty")
+		}
+
+		if utf8.RuneCountInString(*content) > postService.Limits.PostContentLength {
+			return nil, fmt.Errorf("content exceeds maximum length of %d characters", postService.Limits.PostContentLength)
+		}
+	}
+
+	// UserID Validation
+	if userID <= 0 {
+		return nil, fmt.Errorf("invalid user ID: %d", userID)
+	}
+
+	// Delegate call to repository layer
+	updatedPost, err := postService.Repo.PatchPost(postID, title, content, userID)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to update post: %w", err)
+	}
+
+	markPostOwnership(&userID, updatedPost)
+
 	return updatedPost, nil
 }
 
@@ -146,3 +374,46 @@ func (postService *PostService) DeletePost(postID, userID int) error {
 
 	return nil
 }
+
+// SetPostPinned pins or unpins a post and returns the updated post
+// Callers are responsible for restricting this to admins
+func (postService *PostService) SetPostPinned(postID int, pinned bool) (*data.Post, error) {
+	// Validate post ID
+	if postID <= 0 {
+		return nil, fmt.Errorf("invalid post ID: %d", postID)
+	}
+
+	// Delegate call to repository layer
+	err := postService.Repo.SetPostPinned(postID, pinned)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set pinned for post ID %d: %w", postID, err)
+	}
+
+	return postService.GetPostByID(postID, nil)
+}
+
+// TogglePostLock locks or unlocks a post against new comments and returns the updated post
+// Only the post's creator or an admin may do so
+func (postService *PostService) TogglePostLock(postID, userID int) (*data.Post, error) {
+	// Validate input
+	if postID <= 0 {
+		return nil, fmt.Errorf("invalid post ID: %d", postID)
+	}
+
+	if userID <= 0 {
+		return nil, fmt.Errorf("invalid user ID: %d", userID)
+	}
+
+	isAdmin, err := postService.Repo.IsUserAdmin(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check admin role for user ID %d: %w", userID, err)
+	}
+
+	// Delegate call to repository layer
+	err = postService.Repo.TogglePostLock(postID, userID, isAdmin)
+	if err != nil {
+		return nil, fmt.Errorf("failed to toggle lock for post ID %d: %w", postID, err)
+	}
+
+	return postService.GetPostByID(postID, &userID)
+}
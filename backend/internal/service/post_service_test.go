@@ -0,0 +1,43 @@
+// Run `go test -v ./internal/service -run TestPostContentValidation` in /backend
+package service
+
+import (
+	"strings"
+	"testing"
+)
+
+// Validation runs before any repository call, so no database is required
+func TestPostContentValidation(t *testing.T) {
+	postService := NewPostService(nil)
+
+	cases := []struct {
+		name    string
+		content string
+	}{
+		{"NullBytesOnlyContent", "\x00\x00"},
+		{"ZeroWidthSpaceOnlyContent", "\u200B\u200B\u200B"},
+		{"ZeroWidthAndWhitespaceContent", " \u200C\uFEFF \n"},
+	}
+
+	for _, tc := range cases {
+		t.Run("Create"+tc.name, func(t *testing.T) {
+			_, err := postService.CreatePost(1, "Title", tc.content, 1)
+			if err == nil || !strings.Contains(err.Error(), "content cannot be empty") {
+				t.Errorf("expected 'content cannot be empty' error, got %v", err)
+			}
+		})
+
+		t.Run("Update"+tc.name, func(t *testing.T) {
+			_, err := postService.UpdatePost(1, "Title", tc.content, 1)
+			if err == nil || !strings.Contains(err.Error(), "content cannot be empty") {
+				t.Errorf("expected 'content cannot be empty' error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestStripNullBytes(t *testing.T) {
+	if got := stripNullBytes("hel\x00lo\x00"); got != "hello" {
+		t.Errorf("expected null bytes to be stripped, got %q", got)
+	}
+}
@@ -0,0 +1,28 @@
+package service
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Bounds on the 'q' search query parameter, counted in characters after trimming
+const (
+	SearchQueryMinLength = 2   // Shorter queries would match nearly everything
+	SearchQueryMaxLength = 256 // Longer queries only add load on the full-text engine
+)
+
+// NormalizeSearchQuery trims a search query and checks it against the length bounds
+// Search handlers should call this before querying and map its validation errors to 400
+func NormalizeSearchQuery(query string) (string, error) {
+	query = strings.TrimSpace(stripNullBytes(query))
+
+	length := utf8.RuneCountInString(query)
+	if length > SearchQueryMaxLength {
+		return "", newValidationError("search query too long")
+	}
+	if length < SearchQueryMinLength {
+		return "", newValidationError("search query too short")
+	}
+
+	return query, nil
+}
@@ -0,0 +1,54 @@
+// Run `go test -v ./internal/service -run TestNormalizeSearchQuery` in /backend
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeSearchQuery(t *testing.T) {
+	invalid := []struct {
+		name    string
+		query   string
+		message string
+	}{
+		{"Empty", "", "search query too short"},
+		{"SingleCharacter", "a", "search query too short"},
+		{"ShortAfterTrim", "   a \t", "search query too short"},
+		{"TooLong", strings.Repeat("a", SearchQueryMaxLength+1), "search query too long"},
+	}
+
+	for _, tc := range invalid {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NormalizeSearchQuery(tc.query)
+			if !errors.Is(err, ErrValidation) || err.Error() != tc.message {
+				t.Errorf("expected %q validation error, got %v", tc.message, err)
+			}
+		})
+	}
+
+	valid := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{"MinimumLength", "go", "go"},
+		{"TrimmedWhitespace", "  gossip  ", "gossip"},
+		{"MaximumLength", strings.Repeat("a", SearchQueryMaxLength), strings.Repeat("a", SearchQueryMaxLength)},
+		{"MaximumLengthAfterTrim", " " + strings.Repeat("a", SearchQueryMaxLength) + " ", strings.Repeat("a", SearchQueryMaxLength)},
+		{"MultiByteCharacters", strings.Repeat("é", SearchQueryMaxLength), strings.Repeat("é", SearchQueryMaxLength)},
+	}
+
+	for _, tc := range valid {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := NormalizeSearchQuery(tc.query)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
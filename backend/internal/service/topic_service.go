@@ -3,23 +3,170 @@ package service
 import (
 	"fmt"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/adzzfarr/gossip-with-go/backend/internal/data"
 )
 
+// MaxBatchTopicIDs is the maximum number of topics that can be fetched in one batch request
+const MaxBatchTopicIDs = 100
+
+// Trending topics are ranked by posts and comments created within the trending window; at most TrendingTopicsLimit are listed
+const (
+	DefaultTrendingWindow = 48 * time.Hour
+	TrendingTopicsLimit   = 10
+)
+
+// DefaultCountEstimateThreshold is the planner's row estimate from which the topics list reports an estimated total
+// Below it COUNT(*) is cheap enough to always be exact
+const DefaultCountEstimateThreshold = 100000
+
+// By default a user may create DefaultMaxTopicsPerDay topics in any trailing TopicLimitWindow
+const (
+	DefaultMaxTopicsPerDay = 10
+	TopicLimitWindow       = 24 * time.Hour
+)
+
 // TopicService handles business logic related to Topics via the repository layer
 type TopicService struct {
-	Repo *data.Repository
+	Repo                   *data.Repository
+	Limits                 ContentLimits // Maximum title and description lengths; DefaultContentLimits unless replaced
+	TrendingWindow         time.Duration // How far back activity counts towards trending; DefaultTrendingWindow unless replaced
+	Cache                  *TopicsCache  // Optional (nil disables caching of the topics list)
+	CountEstimateThreshold int           // Topics list totals are estimated from this many rows unless exactly requested (0 always counts exactly)
+	MaxTopicsPerDay        int           // Topics a user may create within TopicLimitWindow before CreateTopic is refused; 0 means unlimited
 }
 
 // NewTopicService creates a new instance of TopicService
 func NewTopicService(repo *data.Repository) *TopicService {
-	return &TopicService{Repo: repo}
+	return &TopicService{
+		Repo:                   repo,
+		Limits:                 DefaultContentLimits,
+		TrendingWindow:         DefaultTrendingWindow,
+		CountEstimateThreshold: DefaultCountEstimateThreshold,
+	}
+}
+
+// GetAllTopics retrieves a page of topics
+// exactCount chooses between an exact total (true) and the planner's estimate (false); if nil, the total is exact
+// unless the table has reached CountEstimateThreshold rows. Only pages with the default (nil) are cached
+func (topicService *TopicService) GetAllTopics(limit, offset int, exactCount *bool) (*data.PagedResponse[*data.Topic], error) {
+	// Pagination Validation
+	if limit <= 0 {
+		return nil, fmt.Errorf("invalid limit: %d", limit)
+	}
+
+	if offset < 0 {
+		return nil, fmt.Errorf("invalid offset: %d", offset)
+	}
+
+	estimateAbove := topicService.countEstimateAbove(exactCount)
+
+	if topicService.Cache == nil || exactCount != nil {
+		return topicService.Repo.GetAllTopics(limit, offset, estimateAbove)
+	}
+
+	// Cached pages are loaded from the primary: a lagging replica could otherwise refill the cache, right after a
+	// write invalidated it, with a page that misses the write, and keep serving it for the whole TTL
+	return topicService.Cache.GetOrLoad(limit, offset, func() (*data.PagedResponse[*data.Topic], error) {
+		return topicService.Repo.GetAllTopicsFromPrimary(limit, offset, estimateAbove)
+	})
+}
+
+// GetAllTopicsWithLatestPost retrieves a page of topics, each with its most recent post embedded
+// exactCount is as in GetAllTopics; not cached, as new posts change it without invalidating the topics cache
+func (topicService *TopicService) GetAllTopicsWithLatestPost(limit, offset int, exactCount *bool) (*data.PagedResponse[*data.Topic], error) {
+	// Pagination Validation
+	if limit <= 0 {
+		return nil, fmt.Errorf("invalid limit: %d", limit)
+	}
+
+	if offset < 0 {
+		return nil, fmt.Errorf("invalid offset: %d", offset)
+	}
+
+	// Delegate call to repository layer
+	return topicService.Repo.GetAllTopicsWithLatestPost(limit, offset, topicService.countEstimateAbove(exactCount))
+}
+
+// countEstimateAbove returns the row estimate from which the repository estimates the topics total
+// An explicit exact count never estimates, and an explicit estimate is used whenever the planner has one
+func (topicService *TopicService) countEstimateAbove(exactCount *bool) int {
+	switch {
+	case exactCount == nil:
+		return topicService.CountEstimateThreshold
+	case *exactCount:
+		return 0
+	default:
+		return 1
+	}
 }
 
-// GetAllTopics retrieves all topics
-func (topicService *TopicService) GetAllTopics() ([]*data.Topic, error) {
-	return topicService.Repo.GetAllTopics()
+// ForEachTopic streams every topic, ordered as in GetAllTopics, to fn (never cached)
+func (topicService *TopicService) ForEachTopic(fn func(*data.Topic) error) error {
+	// Delegate call to repository layer
+	return topicService.Repo.ForEachTopic(fn)
+}
+
+// invalidateTopicsCache drops cached topic list pages after a topic is written, if caching is enabled
+func (topicService *TopicService) invalidateTopicsCache() {
+	if topicService.Cache != nil {
+		topicService.Cache.Invalidate()
+	}
+}
+
+// GetTopicsByUser retrieves a page of the topics created by a user
+func (topicService *TopicService) GetTopicsByUser(userID, limit, offset int) (*data.PagedResponse[*data.Topic], error) {
+	// Validate user ID
+	if userID <= 0 {
+		return nil, fmt.Errorf("invalid user ID: %d", userID)
+	}
+
+	// Pagination Validation
+	if limit <= 0 {
+		return nil, fmt.Errorf("invalid limit: %d", limit)
+	}
+
+	if offset < 0 {
+		return nil, fmt.Errorf("invalid offset: %d", offset)
+	}
+
+	return topicService.Repo.GetTopicsByUser(userID, limit, offset)
+}
+
+// GetTopicsByIDs retrieves several topics at once, in the order the IDs were given
+// IDs that do not match any topic are skipped
+func (topicService *TopicService) GetTopicsByIDs(topicIDs []int) ([]*data.Topic, error) {
+	// Validate topic IDs
+	if len(topicIDs) > MaxBatchTopicIDs {
+		return nil, fmt.Errorf("too many topic IDs: maximum is %d", MaxBatchTopicIDs)
+	}
+
+	for _, topicID := range topicIDs {
+		if topicID <= 0 {
+			return nil, fmt.Errorf("invalid topic ID: %d", topicID)
+		}
+	}
+
+	// Delegate call to repository layer
+	topics, err := topicService.Repo.GetTopicsByIDs(topicIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get topics by IDs: %w", err)
+	}
+
+	return topics, nil
+}
+
+// GetTrendingTopics retrieves the topics with the most posts and comments within TrendingWindow
+func (topicService *TopicService) GetTrendingTopics() ([]*data.TrendingTopic, error) {
+	// Delegate call to repository layer
+	topics, err := topicService.Repo.GetTrendingTopics(topicService.TrendingWindow, TrendingTopicsLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trending topics: %w", err)
+	}
+
+	return topics, nil
 }
 
 // GetTopicByID retrieves a specific topic by its ID
@@ -38,24 +185,82 @@ func (topicService *TopicService) GetTopicByID(topicID int) (*data.Topic, error)
 	return topic, nil
 }
 
-// CreateTopic creates a new topic
-func (topicService *TopicService) CreateTopic(title, description string, userID int) (*data.Topic, error) {
-	// Title Validation
-	if title == "" {
-		return nil, fmt.Errorf("title cannot be empty")
+// GetTopicBySlug retrieves a specific topic by its slug
+func (topicService *TopicService) GetTopicBySlug(slug string) (*data.Topic, error) {
+	// Validate slug (only ever generated by data.Slugify, so anything else cannot match)
+	if slug == "" || slug != data.Slugify(slug) {
+		return nil, fmt.Errorf("invalid slug: %q", slug)
 	}
 
-	if len(title) > 200 {
-		return nil, fmt.Errorf("title cannot exceed 200 characters")
+	// Delegate call to repository layer
+	topic, err := topicService.Repo.GetTopicBySlug(slug)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get topic by slug %q: %w", slug, err)
+	}
+
+	return topic, nil
+}
+
+// GetTopicWithPosts retrieves a topic together with the first page of its posts
+func (topicService *TopicService) GetTopicWithPosts(topicID int, userID *int, limit int) (*data.TopicWithPosts, error) {
+	// Validate input
+	if topicID <= 0 {
+		return nil, fmt.Errorf("invalid topic ID: %d", topicID)
+	}
+
+	if limit <= 0 {
+		return nil, fmt.Errorf("invalid limit: %d", limit)
+	}
+
+	// Delegate calls to repository layer
+	topic, err := topicService.Repo.GetTopicByID(topicID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get topic by ID %d: %w", topicID, err)
+	}
+
+	posts, err := topicService.Repo.GetPostsByTopicID(topicID, userID, data.PostSortNew, limit, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get posts for topic ID %d: %w", topicID, err)
+	}
+
+	if posts.Items == nil {
+		// Return empty slice
+		posts.Items = []*data.Post{}
+	}
+
+	markPostOwnership(userID, posts.Items...)
+
+	return &data.TopicWithPosts{
+		Topic:   topic,
+		Posts:   posts.Items,
+		HasMore: posts.HasMore,
+	}, nil
+}
+
+// CreateTopic creates a new topic
+// Unless allowDuplicate is set, titles matching an existing topic's (ignoring case) are refused with ErrDuplicateTopicTitle
+func (topicService *TopicService) CreateTopic(title, description string, userID int, allowDuplicate bool) (*data.Topic, error) {
+	// Trim surrounding whitespace so blank input is rejected and clean values are stored
+	title = strings.TrimSpace(title)
+	description = strings.TrimSpace(description)
+
+	// Field Validation (every failing field is reported)
+	var validationErrs ValidationErrors
+
+	if title == "" {
+		validationErrs.add("title", "title cannot be empty")
+	} else if utf8.RuneCountInString(title) > topicService.Limits.TitleLength {
+		validationErrs.add("title", "title cannot exceed %d characters", topicService.Limits.TitleLength)
 	}
 
-	// Description Validation
 	if description == "" {
-		return nil, fmt.Errorf("description cannot be empty")
+		validationErrs.add("description", "description cannot be empty")
+	} else if utf8.RuneCountInString(description) > topicService.Limits.TopicDescriptionLength {
+		validationErrs.add("description", "description cannot exceed %d characters", topicService.Limits.TopicDescriptionLength)
 	}
 
-	if len(description) > 1000 {
-		return nil, fmt.Errorf("description cannot exceed 1000 characters")
+	if err := validationErrs.err(); err != nil {
+		return nil, err
 	}
 
 	// UserID Validation
@@ -63,6 +268,30 @@ func (topicService *TopicService) CreateTopic(title, description string, userID
 		return nil, fmt.Errorf("invalid user ID: %d", userID)
 	}
 
+	// Enforce the per-user daily cap (best effort: concurrent creations may overshoot it slightly)
+	if topicService.MaxTopicsPerDay > 0 {
+		count, err := topicService.Repo.CountUserTopicsWithin(userID, TopicLimitWindow)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create topic: %w", err)
+		}
+
+		if count >= topicService.MaxTopicsPerDay {
+			return nil, ErrDailyTopicLimit
+		}
+	}
+
+	// Duplicate Title Check
+	if !allowDuplicate {
+		exists, err := topicService.Repo.TopicTitleExists(title)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create topic: %w", err)
+		}
+
+		if exists {
+			return nil, ErrDuplicateTopicTitle
+		}
+	}
+
 	// Delegate call to repository layer
 	topic, err := topicService.Repo.CreateTopic(title, description, userID)
 
@@ -70,27 +299,32 @@ func (topicService *TopicService) CreateTopic(title, description string, userID
 		return nil, fmt.Errorf("failed to create topic: %w", err)
 	}
 
+	topicService.invalidateTopicsCache()
 	return topic, nil
 }
 
 // UpdateTopic updates an existing topic
 func (topicService *TopicService) UpdateTopic(topicID int, title, description string, userID int) (*data.Topic, error) {
+	// Trim surrounding whitespace so blank input is rejected and clean values are stored
+	title = strings.TrimSpace(title)
+	description = strings.TrimSpace(description)
+
 	// Title Validation
-	if strings.TrimSpace(title) == "" {
+	if title == "" {
 		return nil, fmt.Errorf("title cannot be empty")
 	}
 
-	if len(title) > 200 {
-		return nil, fmt.Errorf("title exceeds maximum length of 200 characters")
+	if utf8.RuneCountInString(title) > topicService.Limits.TitleLength {
+		return nil, fmt.Errorf("title exceeds maximum length of %d characters", topicService.Limits.TitleLength)
 	}
 
 	// Description Validation
-	if strings.TrimSpace(description) == "" {
+	if description == "" {
 		return nil, fmt.Errorf("description cannot be empty")
 	}
 
-	if len(description) > 1000 {
-		return nil, fmt.Errorf("description exceeds maximum length of 1000 characters")
+	if utf8.RuneCountInString(description) > topicService.Limits.TopicDescriptionLength {
+		return nil, fmt.Errorf("description exceeds maximum length of %d characters", topicService.Limits.TopicDescriptionLength)
 	}
 
 	// UserID Validation
@@ -110,6 +344,96 @@ func (topicService *TopicService) UpdateTopic(topicID int, title, description st
 		return nil, fmt.Errorf("failed to update topic: %w", err)
 	}
 
+	topicService.invalidateTopicsCache()
+	return updatedTopic, nil
+}
+
+// PatchTopic updates only the provided fields of an existing topic
+func (topicService *TopicService) PatchTopic(topicID int, title, description *string, userID int) (*data.Topic, error) {
+	if title == nil && description == nil {
+		return nil, fmt.Errorf("no fields to update")
+	}
+
+	// Trim surrounding whitespace so blank input is rejected and clean values are stored
+	if title != nil {
+		trimmed := strings.TrimSpace(*title)
+		title = &trimmed
+	}
+
+	if description != nil {
+		trimmed := strings.TrimSpace(*description)
+		description = &trimmed
+	}
+
+	// Title Validation
+	if title != nil {
+		if *title == "" {
+			return nil, fmt.Errorf("title cannot be empty")
+		}
+
+		if utf8.RuneCountInString(*title) > topicService.Limits.TitleLength {
+			return nil, fmt.Errorf("title exceeds maximum length of %d characters", topicService.Limits.TitleLength)
+		}
+	}
+
+	// Description Validation
+	if description != nil {
+		if *description == "" {
+			return nil, fmt.Errorf("description cannot be empty")
+		}
+
+		if utf8.RuneCountInString(*description) > topicService.Limits.TopicDescriptionLength {
+			return nil, fmt.Errorf("description exceeds maximum length of %d characters", topicService.Limits.TopicDescriptionLength)
+		}
+	}
+
+	// UserID Validation
+	if userID <= 0 {
+		return nil, fmt.Errorf("invalid user ID: %d", userID)
+	}
+
+	// Delegate call to repository layer
+	updatedTopic, err := topicService.Repo.PatchTopic(
+		topicID,
+		title,
+		description,
+		userID,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to update topic: %w", err)
+	}
+
+	topicService.invalidateTopicsCache()
+	return updatedTopic, nil
+}
+
+// UpdateTopicDescription updates only the description of an existing topic
+func (topicService *TopicService) UpdateTopicDescription(topicID int, description string, userID int) (*data.Topic, error) {
+	// Trim surrounding whitespace so blank input is rejected and clean values are stored
+	description = strings.TrimSpace(description)
+
+	// Description Validation
+	if description == "" {
+		return nil, fmt.Errorf("description cannot be empty")
+	}
+
+	if utf8.RuneCountInString(description) > topicService.Limits.TopicDescriptionLength {
+		return nil, fmt.Errorf("description exceeds maximum length of %d characters", topicService.Limits.TopicDescriptionLength)
+	}
+
+	// UserID Validation
+	if userID <= 0 {
+		return nil, fmt.Errorf("invalid user ID: %d", userID)
+	}
+
+	// Delegate call to repository layer
+	updatedTopic, err := topicService.Repo.UpdateTopicDescription(topicID, description, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update topic description: %w", err)
+	}
+
+	topicService.invalidateTopicsCache()
 	return updatedTopic, nil
 }
 
@@ -131,5 +455,24 @@ func (topicService *TopicService) DeleteTopic(topicID, userID int) error {
 		return fmt.Errorf("failed to delete topic: %w", err)
 	}
 
+	topicService.invalidateTopicsCache()
 	return nil
 }
+
+// SetTopicPinned pins or unpins a topic and returns the updated topic
+// Callers are responsible for restricting this to admins
+func (topicService *TopicService) SetTopicPinned(topicID int, pinned bool) (*data.Topic, error) {
+	// Validate topic ID
+	if topicID <= 0 {
+		return nil, fmt.Errorf("invalid topic ID: %d", topicID)
+	}
+
+	// Delegate call to repository layer
+	err := topicService.Repo.SetTopicPinned(topicID, pinned)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set pinned for topic ID %d: %w", topicID, err)
+	}
+
+	topicService.invalidateTopicsCache()
+	return topicService.GetTopicByID(topicID)
+}
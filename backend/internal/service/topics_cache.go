@@ -0,0 +1,101 @@
+package service
+
+import (
+	"sync"
+	"time"
+
+	"github.com/adzzfarr/gossip-with-go/backend/internal/data"
+)
+
+// Topics list cache defaults
+const (
+	DefaultTopicsCacheTTL     = 30 * time.Second
+	DefaultTopicsCacheEntries = 100 // Distinct (limit, offset) pages kept at once
+)
+
+// topicsPageKey identifies a cached page of the topics list
+type topicsPageKey struct {
+	limit, offset int
+}
+
+// topicsCacheEntry is a cached page and when it stops being served
+type topicsCacheEntry struct {
+	page      *data.PagedResponse[*data.Topic]
+	expiresAt time.Time
+}
+
+// TopicsCache holds recently fetched pages of the topics list for a short TTL
+// Cached pages are shared between callers, so they must not be modified
+type TopicsCache struct {
+	TTL        time.Duration
+	MaxEntries int
+	now        func() time.Time // Replaced in tests
+
+	mu         sync.Mutex
+	entries    map[topicsPageKey]topicsCacheEntry
+	generation uint64 // Incremented by Invalidate, so loads started before it are not stored
+}
+
+// NewTopicsCache creates an empty TopicsCache
+func NewTopicsCache(ttl time.Duration, maxEntries int) *TopicsCache {
+	return &TopicsCache{
+		TTL:        ttl,
+		MaxEntries: maxEntries,
+		now:        time.Now,
+		entries:    make(map[topicsPageKey]topicsCacheEntry),
+	}
+}
+
+// GetOrLoad returns the cached page for limit and offset, calling load (without holding the lock) on a miss or expiry
+// Errors from load are returned and not cached
+func (cache *TopicsCache) GetOrLoad(limit, offset int, load func() (*data.PagedResponse[*data.Topic], error)) (*data.PagedResponse[*data.Topic], error) {
+	key := topicsPageKey{limit: limit, offset: offset}
+
+	cache.mu.Lock()
+	entry, ok := cache.entries[key]
+	generation := cache.generation
+	cache.mu.Unlock()
+
+	if ok && cache.now().Before(entry.expiresAt) {
+		return entry.page, nil
+	}
+
+	page, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	// A write since the load started may not be reflected in the page, so it is served once but not kept
+	if generation != cache.generation {
+		return page, nil
+	}
+
+	now := cache.now()
+	if _, exists := cache.entries[key]; !exists && len(cache.entries) >= cache.MaxEntries {
+		for k, e := range cache.entries {
+			if !now.Before(e.expiresAt) {
+				delete(cache.entries, k)
+			}
+		}
+
+		// Still full of live pages; skip caching this one rather than evicting them
+		if len(cache.entries) >= cache.MaxEntries {
+			return page, nil
+		}
+	}
+
+	cache.entries[key] = topicsCacheEntry{page: page, expiresAt: now.Add(cache.TTL)}
+	return page, nil
+}
+
+// Invalidate drops every cached page, so the next read of each goes to the database
+func (cache *TopicsCache) Invalidate() {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	cache.entries = make(map[topicsPageKey]topicsCacheEntry)
+	cache.generation++
+}
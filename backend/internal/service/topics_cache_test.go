@@ -0,0 +1,107 @@
+// Run `go test -v ./internal/service -run TestTopicsCache` in /backend
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/adzzfarr/gossip-with-go/backend/internal/data"
+)
+
+func TestTopicsCache(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	cache := NewTopicsCache(30*time.Second, 2)
+	cache.now = func() time.Time { return now }
+
+	// Loader that counts database hits
+	loads := 0
+	load := func() (*data.PagedResponse[*data.Topic], error) {
+		loads++
+		return &data.PagedResponse[*data.Topic]{Total: loads}, nil
+	}
+
+	// 1. Two quick calls load once
+	t.Run("Hit", func(t *testing.T) {
+		first, _ := cache.GetOrLoad(10, 0, load)
+		second, _ := cache.GetOrLoad(10, 0, load)
+
+		if loads != 1 {
+			t.Errorf("Expected 1 load, got %d", loads)
+		}
+
+		if first != second {
+			t.Error("Expected the cached page to be returned")
+		}
+	})
+
+	// 2. Invalidation (as on a create) forces a reload
+	t.Run("Invalidate", func(t *testing.T) {
+		cache.Invalidate()
+
+		page, _ := cache.GetOrLoad(10, 0, load)
+		if loads != 2 || page.Total != 2 {
+			t.Errorf("Expected a fresh load after invalidation, got %d loads", loads)
+		}
+	})
+
+	// 3. Expired pages are reloaded
+	t.Run("Expiry", func(t *testing.T) {
+		now = now.Add(30 * time.Second)
+
+		cache.GetOrLoad(10, 0, load)
+		if loads != 3 {
+			t.Errorf("Expected a reload after the TTL, got %d loads", loads)
+		}
+	})
+
+	// 4. Pages are cached per limit and offset, up to MaxEntries
+	t.Run("Bounded", func(t *testing.T) {
+		cache.GetOrLoad(10, 10, load) // Second entry; the cache is now full
+		cache.GetOrLoad(10, 20, load) // Not cached
+		cache.GetOrLoad(10, 20, load)
+
+		if loads != 6 {
+			t.Errorf("Expected 6 loads, got %d", loads)
+		}
+
+		if len(cache.entries) != 2 {
+			t.Errorf("Expected 2 cached pages, got %d", len(cache.entries))
+		}
+	})
+
+	// 5. Errors are returned and not cached
+	t.Run("Error", func(t *testing.T) {
+		failing := func() (*data.PagedResponse[*data.Topic], error) {
+			loads++
+			return nil, errors.New("database unavailable")
+		}
+
+		if _, err := cache.GetOrLoad(5, 0, failing); err == nil {
+			t.Fatal("Expected an error, got nil")
+		}
+
+		cache.GetOrLoad(5, 0, load)
+		if loads != 8 {
+			t.Errorf("Expected the failed load not to be cached, got %d loads", loads)
+		}
+	})
+
+	// 6. A load that races with an invalidation is served but not kept
+	t.Run("InvalidatedDuringLoad", func(t *testing.T) {
+		cache.Invalidate()
+
+		racing := func() (*data.PagedResponse[*data.Topic], error) {
+			page, err := load()
+			cache.Invalidate() // A write lands while the page is being read
+			return page, err
+		}
+
+		cache.GetOrLoad(10, 0, racing)
+		cache.GetOrLoad(10, 0, load)
+		if loads != 10 {
+			t.Errorf("Expected the raced page not to be cached, got %d loads", loads)
+		}
+	})
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultUserExistsCacheTTL is how long a user confirmed to exist is trusted without checking again
+const DefaultUserExistsCacheTTL = 30 * time.Second
+
+// userExistsCacheSweepThreshold is how many cached users trigger removal of expired entries
+const userExistsCacheSweepThreshold = 10000
+
+// UserExistsCache remembers users recently confirmed to exist, with their token version and ban status, so protected
+// requests need not query for them every time. Only existing users are cached, so a deleted user's token is refused at
+// most TTL after the deletion. It is in-process only, so each instance keeps its own entries
+type UserExistsCache struct {
+	TTL     time.Duration
+	now     func() time.Time // Replaced in tests
+	mu      sync.Mutex
+	entries map[int]userExistsEntry
+}
+
+// userExistsEntry is a cached user's token version, ban status and when they must be checked again
+type userExistsEntry struct {
+	tokenVersion int
+	banChecked   bool // Whether banned is known (ban status is stored separately, after the token version)
+	banned       bool
+	expiresAt    time.Time
+}
+
+// NewUserExistsCache creates an empty UserExistsCache
+func NewUserExistsCache(ttl time.Duration) *UserExistsCache {
+	return &UserExistsCache{
+		TTL:     ttl,
+		now:     time.Now,
+		entries: make(map[int]userExistsEntry),
+	}
+}
+
+// Get returns userID's token version if the user was confirmed to exist within the last TTL
+func (cache *UserExistsCache) Get(userID int) (int, bool) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	entry, ok := cache.entries[userID]
+	if !ok {
+		return 0, false
+	}
+
+	if cache.now().Before(entry.expiresAt) {
+		return entry.tokenVersion, true
+	}
+
+	delete(cache.entries, userID)
+	return 0, false
+}
+
+// Store records that userID exists with the given token version, trusting it for TTL
+func (cache *UserExistsCache) Store(userID, tokenVersion int) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	now := cache.now()
+
+	if len(cache.entries) >= userExistsCacheSweepThreshold {
+		cache.sweep(now)
+	}
+
+	cache.entries[userID] = userExistsEntry{tokenVersion: tokenVersion, expiresAt: now.Add(cache.TTL)}
+}
+
+// GetBanned returns whether userID is banned, if their ban status was stored within the entry's TTL
+func (cache *UserExistsCache) GetBanned(userID int) (bool, bool) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	entry, ok := cache.entries[userID]
+	if !ok || !entry.banChecked || !cache.now().Before(entry.expiresAt) {
+		return false, false
+	}
+
+	return entry.banned, true
+}
+
+// StoreBanned records userID's ban status alongside their token version until the entry expires
+// Users without a live entry are left uncached, as only users confirmed to exist are cached
+func (cache *UserExistsCache) StoreBanned(userID int, banned bool) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	entry, ok := cache.entries[userID]
+	if !ok || !cache.now().Before(entry.expiresAt) {
+		return
+	}
+
+	entry.banChecked = true
+	entry.banned = banned
+	cache.entries[userID] = entry
+}
+
+// Forget removes userID's entry, e.g. after a ban or unban, so their next request is checked against the database
+func (cache *UserExistsCache) Forget(userID int) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	delete(cache.entries, userID)
+}
+
+// sweep removes expired entries (caller holds mu)
+func (cache *UserExistsCache) sweep(now time.Time) {
+	for userID, entry := range cache.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(cache.entries, userID)
+		}
+	}
+}
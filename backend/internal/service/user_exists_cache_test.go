@@ -0,0 +1,85 @@
+// Run `go test -v ./internal/service -run TestUserExistsCache` in /backend
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUserExistsCache(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	cache := NewUserExistsCache(30 * time.Second)
+	cache.now = func() time.Time { return now }
+
+	// 1. Unknown users are not trusted
+	if _, ok := cache.Get(1); ok {
+		t.Fatal("Expected an unseen user not to be cached")
+	}
+
+	// 2. Stored users are trusted until the TTL passes
+	cache.Store(1, 0)
+
+	now = now.Add(29 * time.Second)
+	if _, ok := cache.Get(1); !ok {
+		t.Error("Expected the user to be cached within the TTL")
+	}
+
+	now = now.Add(time.Second)
+	if _, ok := cache.Get(1); ok {
+		t.Error("Expected the user to need checking again once the TTL passed")
+	}
+
+	// 3. Storing again replaces the token version (e.g. after revoking sessions)
+	cache.Store(1, 0)
+	cache.Store(1, 2)
+
+	if tokenVersion, ok := cache.Get(1); !ok || tokenVersion != 2 {
+		t.Errorf("Expected token version 2, got %d (cached: %v)", tokenVersion, ok)
+	}
+
+	// 4. Ban status is cached alongside the token version, and only for users already cached
+	if _, ok := cache.GetBanned(1); ok {
+		t.Error("Expected ban status to be unknown until stored")
+	}
+
+	cache.StoreBanned(1, true)
+	if banned, ok := cache.GetBanned(1); !ok || !banned {
+		t.Errorf("Expected the user to be cached as banned, got %v (cached: %v)", banned, ok)
+	}
+
+	cache.StoreBanned(99, true)
+	if _, ok := cache.GetBanned(99); ok {
+		t.Error("Expected ban status not to be cached for users not confirmed to exist")
+	}
+
+	// 5. Forgetting a user (e.g. after a ban or unban) drops both, so they are checked again
+	cache.Forget(1)
+	if _, ok := cache.GetBanned(1); ok {
+		t.Error("Expected ban status to be forgotten")
+	}
+	if _, ok := cache.Get(1); ok {
+		t.Error("Expected the user to be forgotten")
+	}
+
+	// 6. Ban status expires with the entry
+	cache.Store(1, 0)
+	cache.StoreBanned(1, false)
+
+	now = now.Add(30 * time.Second)
+	if _, ok := cache.GetBanned(1); ok {
+		t.Error("Expected ban status to need checking again once the TTL passed")
+	}
+
+	// 7. Expired entries are swept once the cache grows large
+	for userID := 1; userID <= userExistsCacheSweepThreshold; userID++ {
+		cache.Store(userID, 0)
+	}
+
+	now = now.Add(time.Minute)
+	cache.Store(userExistsCacheSweepThreshold+1, 0)
+
+	if got := len(cache.entries); got != 1 {
+		t.Errorf("Expected expired entries to be swept, %d remain", got)
+	}
+}
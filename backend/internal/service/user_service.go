@@ -2,9 +2,12 @@
 package service
 
 import (
+	"errors"
 	"fmt"
 	"regexp"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/adzzfarr/gossip-with-go/backend/internal/data"
 
@@ -21,49 +24,109 @@ var (
 
 	// (?=.*\d) requires at least one digit
 	digitRegex = regexp.MustCompile(`\d`)
+
+	// Something@something.something, with no whitespace and a single @ (deliverability is not checked)
+	emailRegex = regexp.MustCompile(`^[^\s@]+@[^\s@]+\.[^\s@]+$`)
 )
 
+// maxUsernameLength matches the users.username column
+const maxUsernameLength = 50
+
+// normalizeUsername trims surrounding whitespace from a username and checks that it is non-empty and fits the users table
+// Used by both registration and renaming so the two accept the same names
+// An @ is refused, as identifiers containing one are looked up by email (see lookupUserByIdentifier)
+func normalizeUsername(username string) (string, error) {
+	username = strings.TrimSpace(username)
+
+	if username == "" {
+		return "", newValidationError("username cannot be empty")
+	}
+
+	if strings.Contains(username, "@") {
+		return "", newValidationError("username cannot contain @")
+	}
+
+	if utf8.RuneCountInString(username) > maxUsernameLength {
+		return "", newValidationError("username exceeds maximum length of %d characters", maxUsernameLength)
+	}
+
+	return username, nil
+}
+
+// maxEmailLength matches the users.email column
+const maxEmailLength = 254
+
+// normalizeEmail trims and lowercases an email and checks its basic shape
+// An empty email is allowed (email is optional) and returned as ""
+func normalizeEmail(email string) (string, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+
+	if email == "" {
+		return "", nil
+	}
+
+	if utf8.RuneCountInString(email) > maxEmailLength {
+		return "", newValidationError("email exceeds maximum length of %d characters", maxEmailLength)
+	}
+
+	if !emailRegex.MatchString(email) {
+		return "", newValidationError("invalid email address")
+	}
+
+	return email, nil
+}
+
 // UserService handles business logic related to Users (*** including hashing of passwords ***) via the repository layer
 type UserService struct {
-	Repo *data.Repository
+	Repo              *data.Repository
+	PasswordPolicy    PasswordPolicy
+	PasswordBlocklist PasswordBlocklist // Optional (nil disables the check)
+	ResetTokenTTL     time.Duration     // How long password reset tokens stay valid (0 uses DefaultResetTokenTTL)
+	Email             EmailSender       // Optional (nil disables outgoing email, including password resets)
+	ExistsCache       *UserExistsCache  // Optional (nil checks the database on every CurrentTokenVersion call)
 }
 
 // NewUserService creates a new instance of UserService
-func NewUserService(repo *data.Repository) *UserService {
-	return &UserService{Repo: repo}
+func NewUserService(repo *data.Repository, passwordPolicy PasswordPolicy, passwordBlocklist PasswordBlocklist) *UserService {
+	return &UserService{
+		Repo:              repo,
+		PasswordPolicy:    passwordPolicy,
+		PasswordBlocklist: passwordBlocklist,
+	}
 }
 
 // RegisterUser handles password hashing and delegation to the Repository
-func (service *UserService) RegisterUser(username, password string) (*data.User, error) {
+// email is optional (empty for none); returns ErrValidation for invalid input, and ErrUsernameTaken or ErrEmailTaken
+// (wrapped) if another user already has the username or email
+func (service *UserService) RegisterUser(username, password, email string) (*data.User, error) {
 	// Input Validation
-	if len(password) < 8 {
-		return nil, fmt.Errorf("password must be at least 8 characters")
+	username, err := normalizeUsername(username)
+	if err != nil {
+		return nil, err
 	}
 
-	if !lowercaseRegex.MatchString(password) {
-		return nil, fmt.Errorf("password must contain at least one lowercase letter")
-	}
-	if !uppercaseRegex.MatchString(password) {
-		return nil, fmt.Errorf("password must contain at least one uppercase letter")
+	email, err = normalizeEmail(email)
+	if err != nil {
+		return nil, err
 	}
-	if !digitRegex.MatchString(password) {
-		return nil, fmt.Errorf("password must contain at least one digit")
+
+	if err := service.validatePassword(password); err != nil {
+		return nil, newValidationError("%s", err.Error())
 	}
 
 	// Check if username already exists
 	existingUser, err := service.Repo.GetUserByUsername(username)
 
 	if err != nil {
-		// Check if "user not found" error (i.e. username is available)
-		if err.Error() != fmt.Sprintf("user not found: %s", username) {
-			// Some other database error occurred
+		// Anything other than "user not found" (i.e. username is available) is a database error
+		if !errors.Is(err, data.ErrUserNotFound) {
 			return nil, fmt.Errorf("error checking existing user: %w", err)
 		}
 
 		// Username not found means username is available => proceed
 	} else if existingUser != nil {
 		// User was found means username is taken
-		return nil, fmt.Errorf("username '%s' is already taken", username)
+		return nil, fmt.Errorf("%w: %s", data.ErrUsernameTaken, username)
 	}
 
 	// Hash Password using bcrypt
@@ -78,13 +141,23 @@ func (service *UserService) RegisterUser(username, password string) (*data.User,
 		PasswordHash: string(hashedPassword),
 	}
 
+	if email != "" {
+		user.Email = &email
+	}
+
 	// Delegate to the repository layer
 	if _, err := service.Repo.CreateUser(user); err != nil {
-		if pgErr, ok := err.(*pgconn.PgError); ok {
+		// A concurrent registration may claim the username after the check above
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
 			if pgErr.Code == "23505" { // unique_violation error code
 				// unique constraint violation means username is taken
 				if strings.Contains(pgErr.ConstraintName, "username") {
-					return nil, fmt.Errorf("username is already taken")
+					return nil, fmt.Errorf("%w: %s", data.ErrUsernameTaken, username)
+				}
+
+				if strings.Contains(pgErr.ConstraintName, "email") {
+					return nil, fmt.Errorf("%w: %s", data.ErrEmailTaken, email)
 				}
 			}
 		}
@@ -98,6 +171,19 @@ func (service *UserService) RegisterUser(username, password string) (*data.User,
 	return user, nil
 }
 
+// validatePassword checks a new password against the password policy and blocklist
+func (service *UserService) validatePassword(password string) error {
+	if err := service.PasswordPolicy.Validate(password); err != nil {
+		return err
+	}
+
+	if service.PasswordBlocklist.Contains(password) {
+		return fmt.Errorf("this password is too common")
+	}
+
+	return nil
+}
+
 // GetUserByID retrieves a user by their ID
 func (service *UserService) GetUserByID(userID int) (*data.User, error) {
 	// UserID Validation
@@ -114,6 +200,58 @@ func (service *UserService) GetUserByID(userID int) (*data.User, error) {
 	return user, nil
 }
 
+// GetCurrentUser retrieves the authenticated user's own profile, including their last login time
+func (service *UserService) GetCurrentUser(userID int) (*data.User, error) {
+	// UserID Validation
+	if userID <= 0 {
+		return nil, fmt.Errorf("invalid user ID: %d", userID)
+	}
+
+	// Delegate call to repository layer
+	user, err := service.Repo.GetCurrentUser(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user by ID %d: %w", userID, err)
+	}
+
+	return user, nil
+}
+
+// ChangeUsername renames a user, applying the same username rules as registration
+// Tokens issued before the change still carry the old username, so clients should log in again afterwards
+func (service *UserService) ChangeUsername(userID int, username string) (*data.User, error) {
+	// Input Validation
+	if userID <= 0 {
+		return nil, fmt.Errorf("invalid user ID: %d", userID)
+	}
+
+	username, err := normalizeUsername(username)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check availability up front; the unique constraint still catches concurrent renames
+	existingUser, err := service.Repo.GetUserByUsername(username)
+	if err != nil {
+		if !errors.Is(err, data.ErrUserNotFound) {
+			return nil, fmt.Errorf("error checking existing user: %w", err)
+		}
+	} else if existingUser.UserID != userID {
+		return nil, fmt.Errorf("%w: %s", data.ErrUsernameTaken, username)
+	}
+
+	// Delegate calls to repository layer
+	if err := service.Repo.UpdateUsername(userID, username); err != nil {
+		return nil, fmt.Errorf("failed to change username: %w", err)
+	}
+
+	user, err := service.Repo.GetUserByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user by ID %d: %w", userID, err)
+	}
+
+	return user, nil
+}
+
 // GetUserPosts retrieves all posts created by a specific user
 func (service *UserService) GetUserPosts(userID int) ([]*data.Post, error) {
 	// UserID Validation
@@ -145,3 +283,150 @@ func (service *UserService) GetUserComments(userID int) ([]*data.Comment, error)
 
 	return comments, nil
 }
+
+// GetUserKarma retrieves a user's aggregate vote score across their posts and comments
+func (service *UserService) GetUserKarma(userID int) (*data.UserKarma, error) {
+	// UserID Validation
+	if userID <= 0 {
+		return nil, fmt.Errorf("invalid user ID: %d", userID)
+	}
+
+	// Verify user exists, so a missing user is not mistaken for one without karma
+	if _, err := service.Repo.GetUserByID(userID); err != nil {
+		return nil, fmt.Errorf("failed to get user by ID %d: %w", userID, err)
+	}
+
+	// Delegate call to repository layer
+	postKarma, commentKarma, err := service.Repo.GetUserKarma(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get karma for user ID %d: %w", userID, err)
+	}
+
+	return &data.UserKarma{
+		PostKarma:    postKarma,
+		CommentKarma: commentKarma,
+		Total:        postKarma + commentKarma,
+	}, nil
+}
+
+// GetUserProfileByUsername retrieves a user's public profile, including karma, by username
+// The username is normalized as at registration, so surrounding whitespace is ignored
+func (service *UserService) GetUserProfileByUsername(username string) (*data.UserProfile, error) {
+	// Username Validation
+	username, err := normalizeUsername(username)
+	if err != nil {
+		return nil, err
+	}
+
+	// Delegate calls to repository layer
+	user, err := service.Repo.GetUserByUsername(username)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user by username %q: %w", username, err)
+	}
+
+	postKarma, commentKarma, err := service.Repo.GetUserKarma(user.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get karma for user ID %d: %w", user.UserID, err)
+	}
+
+	return &data.UserProfile{
+		UserID:    user.UserID,
+		Username:  user.Username,
+		CreatedAt: user.CreatedAt,
+		Karma: data.UserKarma{
+			PostKarma:    postKarma,
+			CommentKarma: commentKarma,
+			Total:        postKarma + commentKarma,
+		},
+	}, nil
+}
+
+// Active users are those who created posts or comments within ActiveUsersWindow; at most ActiveUsersLimit are listed
+const (
+	ActiveUsersWindow = 24 * time.Hour
+	ActiveUsersLimit  = 20
+)
+
+// GetActiveUsers retrieves the most active users of the last ActiveUsersWindow, by number of posts and comments
+func (service *UserService) GetActiveUsers() ([]*data.ActiveUser, error) {
+	// Delegate call to repository layer
+	users, err := service.Repo.GetActiveUsers(ActiveUsersWindow, ActiveUsersLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active users: %w", err)
+	}
+
+	return users, nil
+}
+
+// MaxBatchUserIDs is the maximum number of users whose usernames can be resolved in one request
+const MaxBatchUserIDs = 200
+
+// GetUsernamesByIDs resolves user IDs to usernames, keyed by user ID; unknown IDs are left out
+func (service *UserService) GetUsernamesByIDs(userIDs []int) (map[int]string, error) {
+	// Validate user IDs
+	if len(userIDs) > MaxBatchUserIDs {
+		return nil, fmt.Errorf("too many user IDs: maximum is %d", MaxBatchUserIDs)
+	}
+
+	for _, userID := range userIDs {
+		if userID <= 0 {
+			return nil, fmt.Errorf("invalid user ID: %d", userID)
+		}
+	}
+
+	// Delegate call to repository layer
+	usernames, err := service.Repo.GetUsernamesByIDs(userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get usernames by IDs: %w", err)
+	}
+
+	return usernames, nil
+}
+
+// CurrentTokenVersion returns the user's token version, e.g. to refuse tokens issued before RevokeSessions
+// or to since-deleted accounts (data.ErrUserNotFound). Cached for ExistsCache's TTL if a cache is set
+func (service *UserService) CurrentTokenVersion(userID int) (int, error) {
+	// UserID Validation
+	if userID <= 0 {
+		return 0, fmt.Errorf("%w with ID: %d", data.ErrUserNotFound, userID)
+	}
+
+	if service.ExistsCache != nil {
+		if tokenVersion, ok := service.ExistsCache.Get(userID); ok {
+			return tokenVersion, nil
+		}
+	}
+
+	// Delegate call to repository layer
+	tokenVersion, err := service.Repo.GetUserTokenVersion(userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to check user ID %d: %w", userID, err)
+	}
+
+	if service.ExistsCache != nil {
+		service.ExistsCache.Store(userID, tokenVersion)
+	}
+
+	return tokenVersion, nil
+}
+
+// RevokeSessions invalidates every token issued to the user so far ("log out everywhere")
+// This instance refuses them at once; others may accept them for up to their ExistsCache's TTL
+func (service *UserService) RevokeSessions(userID int) error {
+	// UserID Validation
+	if userID <= 0 {
+		return fmt.Errorf("invalid user ID: %d", userID)
+	}
+
+	// Delegate call to repository layer
+	tokenVersion, err := service.Repo.IncrementTokenVersion(userID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke sessions of user ID %d: %w", userID, err)
+	}
+
+	if service.ExistsCache != nil {
+		service.ExistsCache.Store(userID, tokenVersion)
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+// Run `go test -v ./internal/service -run TestValidationErrors` in /backend
+package service
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Field checks run before any repository call, so no database is required
+func TestValidationErrors(t *testing.T) {
+	limits := ContentLimits{
+		TitleLength:            10,
+		TopicDescriptionLength: 20,
+		PostContentLength:      30,
+		CommentLength:          40,
+	}
+
+	topicService := NewTopicService(nil)
+	topicService.Limits = limits
+
+	postService := NewPostService(nil)
+	postService.Limits = limits
+
+	cases := []struct {
+		name       string
+		call       func() error
+		wantFields []string
+	}{
+		{"TopicBothFields", func() error {
+			_, err := topicService.CreateTopic("  ", strings.Repeat("a", 21), 1, false)
+			return err
+		}, []string{"title", "description"}},
+		{"TopicOneField", func() error {
+			_, err := topicService.CreateTopic(strings.Repeat("a", 11), "Description", 1, false)
+			return err
+		}, []string{"title"}},
+		{"PostBothFields", func() error {
+			_, err := postService.CreatePost(1, "", strings.Repeat("a", 31), 1)
+			return err
+		}, []string{"title", "content"}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.call()
+
+			var validationErrs ValidationErrors
+			if !errors.As(err, &validationErrs) {
+				t.Fatalf("Expected ValidationErrors, got %v", err)
+			}
+
+			if !errors.Is(err, ErrValidation) {
+				t.Error("Expected ValidationErrors to match ErrValidation")
+			}
+
+			var fields []string
+			for _, fieldErr := range validationErrs {
+				fields = append(fields, fieldErr.Field)
+			}
+
+			if !reflect.DeepEqual(fields, tc.wantFields) {
+				t.Errorf("Expected failed fields %v, got %v", tc.wantFields, fields)
+			}
+		})
+	}
+
+	t.Run("ErrorJoinsMessages", func(t *testing.T) {
+		errs := ValidationErrors{
+			{Field: "title", Message: "title cannot be empty"},
+			{Field: "content", Message: "content cannot be empty"},
+		}
+
+		if got, want := errs.Error(), "title cannot be empty; content cannot be empty"; got != want {
+			t.Errorf("Expected %q, got %q", want, got)
+		}
+	})
+}
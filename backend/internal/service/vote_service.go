@@ -6,6 +6,9 @@ import (
 	"github.com/adzzfarr/gossip-with-go/backend/internal/data"
 )
 
+// MaxBatchVoteCountPostIDs is the maximum number of posts whose vote counts can be fetched in one request
+const MaxBatchVoteCountPostIDs = 200
+
 type VoteService struct {
 	Repo *data.Repository
 }
@@ -16,6 +19,32 @@ func NewVoteService(repo *data.Repository) *VoteService {
 	}
 }
 
+// GetVoteCountsForPosts retrieves the current vote count of each given post, keyed by post ID
+func (voteService *VoteService) GetVoteCountsForPosts(postIDs []int) (map[int]int, error) {
+	// Validate post IDs
+	if len(postIDs) == 0 {
+		return nil, newValidationError("at least one post ID is required")
+	}
+
+	if len(postIDs) > MaxBatchVoteCountPostIDs {
+		return nil, newValidationError("too many post IDs: maximum is %d", MaxBatchVoteCountPostIDs)
+	}
+
+	for _, postID := range postIDs {
+		if postID <= 0 {
+			return nil, newValidationError("invalid post ID: %d", postID)
+		}
+	}
+
+	// Delegate call to repository layer
+	counts, err := voteService.Repo.GetVoteCountsForPosts(postIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get vote counts: %w", err)
+	}
+
+	return counts, nil
+}
+
 // VoteOnPost allows a user to vote on a post
 func (voteService *VoteService) VoteOnPost(userID, postID, voteType int) error {
 	// Validate voteType
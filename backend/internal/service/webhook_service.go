@@ -0,0 +1,325 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+	"unicode/utf8"
+
+	"github.com/adzzfarr/gossip-with-go/backend/internal/data"
+)
+
+// WebhookSignatureHeader carries the hex HMAC-SHA256 of the delivery body, keyed by the webhook's secret
+const WebhookSignatureHeader = "X-Signature"
+
+// WebhookEventPostCreated is the event sent when a post is created in a webhook's topic
+const WebhookEventPostCreated = "post.created"
+
+// Webhook delivery defaults
+const (
+	DefaultWebhookQueueSize = 100
+	DefaultWebhookWorkers   = 4
+	DefaultWebhookTimeout   = 5 * time.Second
+	webhookResolveTimeout   = 2 * time.Second
+)
+
+// errWebhookPrivateTarget is returned when a webhook would connect to an address on the server's own network
+var errWebhookPrivateTarget = errors.New("webhook target is not a public address")
+
+// isPublicIP reports whether ip may be a webhook target, i.e. it is not loopback, private, link-local,
+// unspecified or multicast (IPv4-mapped IPv6 addresses are judged by their IPv4 address)
+func isPublicIP(ip net.IP) bool {
+	if ip4 := ip.To4(); ip4 != nil && ip4[0] == 0 {
+		return false // 0.0.0.0/8 ("this network")
+	}
+
+	return !(ip.IsLoopback() ||
+		ip.IsPrivate() ||
+		ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast() ||
+		ip.IsUnspecified())
+}
+
+// webhookDialControl refuses connections to non-public addresses
+// It runs after DNS resolution, so a host re-resolving to an internal address (DNS rebinding) is still refused
+func webhookDialControl(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", errWebhookPrivateTarget, address)
+	}
+
+	if ip := net.ParseIP(host); ip == nil || !isPublicIP(ip) {
+		return fmt.Errorf("%w: %s", errWebhookPrivateTarget, address)
+	}
+
+	return nil
+}
+
+// NewWebhookHTTPClient creates the client webhooks are delivered with: it only connects to public addresses
+// and does not follow redirects, so neither a registered URL nor its responses can reach the internal network
+func NewWebhookHTTPClient(timeout time.Duration) *http.Client {
+	return newWebhookHTTPClient(timeout, webhookDialControl)
+}
+
+// newWebhookHTTPClient creates a webhook client whose connections are checked by control (nil allows all, for tests)
+func newWebhookHTTPClient(timeout time.Duration, control func(network, address string, conn syscall.RawConn) error) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil // A proxy would be dialed instead of the target, bypassing control
+	transport.DialContext = (&net.Dialer{Timeout: timeout, Control: control}).DialContext
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse // Redirects count as non-2xx failures rather than being followed
+		},
+	}
+}
+
+// WebhookPayload is the JSON body POSTed to webhooks
+type WebhookPayload struct {
+	Event string     `json:"event"`
+	Post  *data.Post `json:"post"`
+}
+
+// webhookDelivery is a single queued POST to a webhook
+type webhookDelivery struct {
+	URL    string
+	Secret string
+	Body   []byte
+}
+
+// WebhookDispatcher delivers webhook requests in the background
+// Deliveries are queued in a bounded buffer and dropped (with a log) when it is full, so callers never block
+type WebhookDispatcher struct {
+	Client *http.Client
+	queue  chan webhookDelivery
+	wg     sync.WaitGroup
+}
+
+// NewWebhookDispatcher creates a WebhookDispatcher and starts its worker goroutines
+func NewWebhookDispatcher(queueSize, workers int, client *http.Client) *WebhookDispatcher {
+	dispatcher := &WebhookDispatcher{
+		Client: client,
+		queue:  make(chan webhookDelivery, queueSize),
+	}
+
+	for i := 0; i < workers; i++ {
+		dispatcher.wg.Add(1)
+		go dispatcher.work()
+	}
+
+	return dispatcher
+}
+
+// Close stops accepting deliveries and waits for queued ones to finish
+func (dispatcher *WebhookDispatcher) Close() {
+	close(dispatcher.queue)
+	dispatcher.wg.Wait()
+}
+
+// enqueue queues a delivery without blocking, reporting whether it was accepted
+func (dispatcher *WebhookDispatcher) enqueue(delivery webhookDelivery) bool {
+	select {
+	case dispatcher.queue <- delivery:
+		return true
+	default:
+		return false
+	}
+}
+
+func (dispatcher *WebhookDispatcher) work() {
+	defer dispatcher.wg.Done()
+
+	for delivery := range dispatcher.queue {
+		if err := dispatcher.deliver(delivery); err != nil {
+			log.Printf("Webhook delivery to %s failed: %v", delivery.URL, err)
+		}
+	}
+}
+
+// deliver POSTs a signed delivery; non-2xx responses count as failures
+func (dispatcher *WebhookDispatcher) deliver(delivery webhookDelivery) error {
+	req, err := http.NewRequest(http.MethodPost, delivery.URL, bytes.NewReader(delivery.Body))
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookSignatureHeader, SignWebhookBody(delivery.Secret, delivery.Body))
+
+	resp, err := dispatcher.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// SignWebhookBody returns the hex HMAC-SHA256 of body keyed by secret, as sent in WebhookSignatureHeader
+func SignWebhookBody(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// WebhookService handles business logic related to webhooks via the repository layer
+type WebhookService struct {
+	Repo                *data.Repository
+	Dispatcher          *WebhookDispatcher
+	AllowPrivateTargets bool // Accept URLs resolving to loopback or private addresses (tests and local development only)
+}
+
+// NewWebhookService creates a new instance of WebhookService
+func NewWebhookService(repo *data.Repository, dispatcher *WebhookDispatcher) *WebhookService {
+	return &WebhookService{Repo: repo, Dispatcher: dispatcher}
+}
+
+// CreateWebhook registers a webhook to be notified of new posts in a topic
+func (webhookService *WebhookService) CreateWebhook(topicID, userID int, webhookURL, secret string) (*data.Webhook, error) {
+	// Validate input
+	if topicID <= 0 {
+		return nil, fmt.Errorf("invalid topic ID: %d", topicID)
+	}
+
+	if userID <= 0 {
+		return nil, fmt.Errorf("invalid user ID: %d", userID)
+	}
+
+	webhookURL = strings.TrimSpace(webhookURL)
+	parsed, err := url.Parse(webhookURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, newValidationError("url must be an absolute http or https URL")
+	}
+	if len(webhookURL) > 2000 {
+		return nil, newValidationError("url exceeds maximum length of 2000 characters")
+	}
+
+	if utf8.RuneCountInString(secret) < 16 {
+		return nil, newValidationError("secret must be at least 16 characters")
+	}
+	if len(secret) > 255 {
+		return nil, newValidationError("secret exceeds maximum length of 255 characters")
+	}
+
+	// Refuse targets on the server's own network (checked again on every delivery, as DNS may change)
+	if !webhookService.AllowPrivateTargets {
+		if err := checkWebhookHost(parsed.Hostname()); err != nil {
+			return nil, err
+		}
+	}
+
+	// Verify topic exists
+	exists, err := webhookService.Repo.TopicExists(topicID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify topic ID %d: %w", topicID, err)
+	}
+
+	if !exists {
+		return nil, fmt.Errorf("%w: %d", data.ErrTopicNotFound, topicID)
+	}
+
+	// Delegate call to repository layer
+	webhook, err := webhookService.Repo.CreateWebhook(topicID, userID, webhookURL, secret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create webhook: %w", err)
+	}
+
+	return webhook, nil
+}
+
+// checkWebhookHost returns a validation error unless every address host resolves to is public
+func checkWebhookHost(host string) error {
+	if ip := net.ParseIP(host); ip != nil {
+		if !isPublicIP(ip) {
+			return newValidationError("url must not point to a private or local address")
+		}
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), webhookResolveTimeout)
+	defer cancel()
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil || len(addrs) == 0 {
+		return newValidationError("url host could not be resolved")
+	}
+
+	for _, addr := range addrs {
+		if !isPublicIP(addr.IP) {
+			return newValidationError("url must not point to a private or local address")
+		}
+	}
+
+	return nil
+}
+
+// DeleteWebhook removes a webhook registered by the user
+func (webhookService *WebhookService) DeleteWebhook(webhookID, userID int) error {
+	// Validate input
+	if webhookID <= 0 {
+		return fmt.Errorf("invalid webhook ID: %d", webhookID)
+	}
+
+	if userID <= 0 {
+		return fmt.Errorf("invalid user ID: %d", userID)
+	}
+
+	// Delegate call to repository layer
+	if err := webhookService.Repo.DeleteWebhook(webhookID, userID); err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+
+	return nil
+}
+
+// NotifyPostCreated queues a delivery to every webhook registered for the post's topic
+// Failures are logged rather than returned, since the post has already been created
+func (webhookService *WebhookService) NotifyPostCreated(post *data.Post) {
+	webhooks, err := webhookService.Repo.GetWebhooksByTopicID(post.TopicID)
+	if err != nil {
+		log.Printf("Failed to load webhooks for topic %d: %v", post.TopicID, err)
+		return
+	}
+
+	if len(webhooks) == 0 {
+		return
+	}
+
+	// Ownership is relative to the requesting user, so it is not sent to third parties
+	payloadPost := *post
+	payloadPost.IsOwner = false
+
+	body, err := json.Marshal(WebhookPayload{Event: WebhookEventPostCreated, Post: &payloadPost})
+	if err != nil {
+		log.Printf("Failed to encode webhook payload for post %d: %v", post.PostID, err)
+		return
+	}
+
+	for _, webhook := range webhooks {
+		delivery := webhookDelivery{URL: webhook.URL, Secret: webhook.Secret, Body: body}
+		if !webhookService.Dispatcher.enqueue(delivery) {
+			log.Printf("Webhook queue full, dropping delivery of post %d to webhook %d", post.PostID, webhook.WebhookID)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+// Run `go test -v ./internal/service -run TestWebhook` in /backend
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWebhookDispatcher(t *testing.T) {
+	type received struct {
+		body      []byte
+		signature string
+	}
+	deliveries := make(chan received, 1)
+
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		deliveries <- received{body: body, signature: r.Header.Get(WebhookSignatureHeader)}
+	}))
+	defer target.Close()
+
+	dispatcher := NewWebhookDispatcher(1, 1, target.Client())
+	defer dispatcher.Close()
+
+	secret := "test-webhook-secret"
+	body := []byte(`{"event":"post.created"}`)
+
+	if !dispatcher.enqueue(webhookDelivery{URL: target.URL, Secret: secret, Body: body}) {
+		t.Fatalf("expected delivery to be queued")
+	}
+
+	select {
+	case got := <-deliveries:
+		if string(got.body) != string(body) {
+			t.Errorf("expected body %s, got %s", body, got.body)
+		}
+
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(body)
+		if expected := hex.EncodeToString(mac.Sum(nil)); got.signature != expected {
+			t.Errorf("expected signature %s, got %s", expected, got.signature)
+		}
+
+	case <-time.After(5 * time.Second):
+		t.Fatalf("webhook was not delivered")
+	}
+}
+
+func TestWebhookDispatcherQueueFull(t *testing.T) {
+	// No workers, so nothing drains the queue
+	dispatcher := NewWebhookDispatcher(1, 0, http.DefaultClient)
+
+	delivery := webhookDelivery{URL: "http://example.invalid", Secret: "secret", Body: []byte("{}")}
+	if !dispatcher.enqueue(delivery) {
+		t.Fatalf("expected first delivery to be queued")
+	}
+
+	if dispatcher.enqueue(delivery) {
+		t.Errorf("expected delivery to be dropped when the queue is full")
+	}
+}
+
+// Validation runs before any repository call, so no database is required
+func TestWebhookValidation(t *testing.T) {
+	webhookService := NewWebhookService(nil, nil)
+
+	cases := []struct {
+		name   string
+		url    string
+		secret string
+	}{
+		{"RelativeURL", "/hooks/new-post", "0123456789abcdef"},
+		{"UnsupportedScheme", "ftp://example.com/hook", "0123456789abcdef"},
+		{"MissingHost", "https://", "0123456789abcdef"},
+		{"ShortSecret", "https://example.com/hook", "too-short"},
+		{"LongSecret", "https://example.com/hook", strings.Repeat("s", 256)},
+		{"LoopbackTarget", "http://127.0.0.1/", "0123456789abcdef"},
+		{"PrivateTarget", "http://10.0.0.1/hook", "0123456789abcdef"},
+		{"MetadataTarget", "http://169.254.169.254/latest/meta-data/", "0123456789abcdef"},
+		{"IPv6LoopbackTarget", "http://[::1]:8080/hook", "0123456789abcdef"},
+		{"IPv6UniqueLocalTarget", "http://[fd00::1]/hook", "0123456789abcdef"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := webhookService.CreateWebhook(1, 1, tc.url, tc.secret)
+			if !errors.Is(err, ErrValidation) {
+				t.Errorf("expected ErrValidation, got %v", err)
+			}
+		})
+	}
+}
+
+func TestWebhookPublicAddresses(t *testing.T) {
+	cases := []struct {
+		ip     string
+		public bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"0.0.0.0", false},
+		{"224.0.0.1", false},
+		{"::1", false},
+		{"fc00::1", false},
+		{"fe80::1", false},
+		{"::ffff:127.0.0.1", false},
+	}
+
+	for _, tc := range cases {
+		if got := isPublicIP(net.ParseIP(tc.ip)); got != tc.public {
+			t.Errorf("isPublicIP(%s) = %v, expected %v", tc.ip, got, tc.public)
+		}
+	}
+}
+
+func TestWebhookClientRefusesPrivateTargets(t *testing.T) {
+	hits := make(chan string, 2)
+
+	internal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits <- "internal"
+	}))
+	defer internal.Close()
+
+	// Looks public, but redirects to an internal address
+	redirecting := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits <- "redirecting"
+		http.Redirect(w, r, internal.URL, http.StatusTemporaryRedirect)
+	}))
+	defer redirecting.Close()
+
+	delivery := webhookDelivery{Secret: "secret", Body: []byte("{}")}
+
+	// 1. Connections to loopback are refused at dial time, whatever the URL resolved to when registered
+	dispatcher := &WebhookDispatcher{Client: NewWebhookHTTPClient(time.Second)}
+	delivery.URL = internal.URL
+	if err := dispatcher.deliver(delivery); !errors.Is(err, errWebhookPrivateTarget) {
+		t.Errorf("expected delivery to loopback to be refused, got %v", err)
+	}
+
+	// 2. Redirects are not followed (dial checks disabled so the loopback test servers are reachable)
+	dispatcher = &WebhookDispatcher{Client: newWebhookHTTPClient(time.Second, nil)}
+	delivery.URL = redirecting.URL
+	if err := dispatcher.deliver(delivery); err == nil {
+		t.Error("expected a redirect to count as a failed delivery")
+	}
+
+	close(hits)
+	for hit := range hits {
+		if hit == "internal" {
+			t.Error("expected the redirect to the internal address not to be followed")
+		}
+	}
+}
@@ -0,0 +1,13 @@
+-- Idempotency keys for safely retrying resource creation
+CREATE TABLE idempotency_keys (
+    idempotency_key VARCHAR(255) NOT NULL,
+    user_id INT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    resource_type VARCHAR(20) NOT NULL, -- 'post' or 'comment'
+    resource_id INT NOT NULL,
+    request_hash CHAR(64) NOT NULL, -- SHA-256 of the original request
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (user_id, idempotency_key) -- Keys are scoped per user
+);
+
+CREATE INDEX idx_idempotency_keys_created_at ON idempotency_keys(created_at);
@@ -0,0 +1,2 @@
+-- Supports looking up a user's most recent comment on a post (duplicate-comment guard)
+CREATE INDEX idx_comments_post_user_created_at ON comments(post_id, created_by, created_at DESC);
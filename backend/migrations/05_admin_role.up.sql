@@ -0,0 +1,2 @@
+-- Admin role for moderation endpoints (grant with: UPDATE users SET is_admin = TRUE WHERE username = '...')
+ALTER TABLE users ADD COLUMN is_admin BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,13 @@
+-- Audit trail of irreversible actions (e.g. content deletions)
+CREATE TABLE audit_log (
+    id SERIAL PRIMARY KEY,
+    actor_user_id INT REFERENCES users(user_id) ON DELETE SET NULL, -- Kept (as NULL) if the actor is deleted
+    action VARCHAR(50) NOT NULL, -- e.g. 'delete'
+    target_type VARCHAR(20) NOT NULL, -- 'topic', 'post' or 'comment'
+    target_id INT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    metadata JSONB NOT NULL DEFAULT '{}'
+);
+
+CREATE INDEX idx_audit_log_created_at ON audit_log(created_at DESC);
+CREATE INDEX idx_audit_log_target ON audit_log(target_type, target_id);
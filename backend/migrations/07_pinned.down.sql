@@ -0,0 +1,2 @@
+ALTER TABLE posts DROP COLUMN IF EXISTS pinned;
+ALTER TABLE topics DROP COLUMN IF EXISTS pinned;
@@ -0,0 +1,3 @@
+-- Pinned topics and posts are listed ahead of others
+ALTER TABLE topics ADD COLUMN pinned BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE posts ADD COLUMN pinned BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,2 @@
+-- Locked posts accept no new comments
+ALTER TABLE posts ADD COLUMN locked BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,11 @@
+-- Webhooks notified (via signed HTTP POST) when a post is created in a topic
+CREATE TABLE webhooks (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE, -- Owner who registered the webhook
+    topic_id INT NOT NULL REFERENCES topics(topic_id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    secret VARCHAR(255) NOT NULL, -- HMAC key for the X-Signature header
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_webhooks_topic_id ON webhooks(topic_id);
@@ -0,0 +1,31 @@
+-- Human-readable topic URLs; unique, and NULL only for topics inserted outside the API
+ALTER TABLE topics ADD COLUMN slug VARCHAR(220);
+
+-- Backfill existing topics, suffixing repeats with their ID
+UPDATE topics SET slug = COALESCE(NULLIF(TRIM(BOTH '-' FROM REGEXP_REPLACE(LOWER(LEFT(title, 200)), '[^a-z0-9]+', '-', 'g')), ''), 'topic');
+
+-- A suffixed slug can equal another topic's own slug (e.g. a second "News" with ID 2 and a topic titled "News 2"), so count up until it is free
+DO $$
+DECLARE
+    duplicate RECORD;
+    candidate TEXT;
+    attempt INT;
+BEGIN
+    FOR duplicate IN
+        SELECT t.topic_id, t.slug FROM topics t
+        WHERE EXISTS (SELECT 1 FROM topics o WHERE o.slug = t.slug AND o.topic_id < t.topic_id)
+        ORDER BY t.topic_id
+    LOOP
+        candidate := duplicate.slug || '-' || duplicate.topic_id;
+        attempt := 1;
+
+        WHILE EXISTS (SELECT 1 FROM topics WHERE slug = candidate) LOOP
+            attempt := attempt + 1;
+            candidate := duplicate.slug || '-' || duplicate.topic_id || '-' || attempt;
+        END LOOP;
+
+        UPDATE topics SET slug = candidate WHERE topic_id = duplicate.topic_id;
+    END LOOP;
+END $$;
+
+ALTER TABLE topics ADD CONSTRAINT topics_slug_key UNIQUE (slug);
@@ -0,0 +1,2 @@
+-- Comment quoted by a reply (always on the same post); cleared if the quoted comment is deleted
+ALTER TABLE comments ADD COLUMN quoted_comment_id INT REFERENCES comments(comment_id) ON DELETE SET NULL;
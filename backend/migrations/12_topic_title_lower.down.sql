@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_topics_lower_title;
@@ -0,0 +1,2 @@
+-- Supports the case-insensitive duplicate topic title check
+CREATE INDEX idx_topics_lower_title ON topics (LOWER(title));
@@ -0,0 +1,2 @@
+-- Time of the most recent successful login (NULL until the user next logs in)
+ALTER TABLE users ADD COLUMN last_login_at TIMESTAMPTZ;
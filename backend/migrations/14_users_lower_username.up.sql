@@ -0,0 +1,2 @@
+-- Supports case-insensitive username prefix search (LOWER(username) LIKE 'prefix%')
+CREATE INDEX idx_users_lower_username ON users (LOWER(username) text_pattern_ops);
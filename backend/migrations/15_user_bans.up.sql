@@ -0,0 +1,2 @@
+-- End of a user's suspension ('infinity' for a permanent ban, NULL if not banned)
+ALTER TABLE users ADD COLUMN banned_until TIMESTAMPTZ;
@@ -0,0 +1,19 @@
+-- Users mentioned (as @username) in a comment; recorded once per comment and user
+CREATE TABLE comment_mentions (
+    comment_id INT NOT NULL REFERENCES comments(comment_id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    PRIMARY KEY (comment_id, user_id)
+);
+
+-- Notifications for a user, such as being mentioned in a comment
+CREATE TABLE notifications (
+    notification_id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE, -- Recipient
+    type VARCHAR(20) NOT NULL,
+    actor_id INT REFERENCES users(user_id) ON DELETE SET NULL, -- User who caused the notification
+    comment_id INT REFERENCES comments(comment_id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    read_at TIMESTAMP
+);
+
+CREATE INDEX idx_notifications_user_id ON notifications(user_id, created_at DESC);
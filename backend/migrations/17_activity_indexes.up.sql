@@ -0,0 +1,3 @@
+-- Recent posts and comments are looked up by creation time when listing active users
+CREATE INDEX idx_posts_created_at ON posts(created_at);
+CREATE INDEX idx_comments_created_at ON comments(created_at);
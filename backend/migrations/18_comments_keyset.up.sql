@@ -0,0 +1,2 @@
+-- Cursor pagination walks a post's comments in (created_at, comment_id) order
+CREATE INDEX idx_comments_post_created_at_id ON comments(post_id, created_at, comment_id);
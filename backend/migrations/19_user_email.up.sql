@@ -0,0 +1,2 @@
+-- Optional email for login and account recovery; stored lowercased, unique when set
+ALTER TABLE users ADD COLUMN email VARCHAR(254) UNIQUE;
@@ -0,0 +1,3 @@
+-- A topic's posts are listed pinned first, then newest first; matching that order lets a page
+-- be read straight from the index instead of sorting every post in the topic
+CREATE INDEX idx_posts_topic_pinned_created_at ON posts(topic_id, pinned DESC, created_at DESC);
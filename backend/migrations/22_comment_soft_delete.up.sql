@@ -0,0 +1,2 @@
+-- Set when a comment quoted by replies is deleted: its content is replaced instead, so the replies keep their quote
+ALTER TABLE comments ADD COLUMN deleted_at TIMESTAMPTZ;
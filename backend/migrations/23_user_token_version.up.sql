@@ -0,0 +1,2 @@
+-- Bumped to revoke every token issued to the user so far ("log out everywhere"); tokens carry the version they were issued with
+ALTER TABLE users ADD COLUMN token_version INT NOT NULL DEFAULT 0;
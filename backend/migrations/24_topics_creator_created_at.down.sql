@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_topics_created_by_created_at;
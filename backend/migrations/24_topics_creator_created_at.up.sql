@@ -0,0 +1,2 @@
+-- Supports counting the topics a user created in the past day (daily topic limit)
+CREATE INDEX idx_topics_created_by_created_at ON topics(created_by, created_at DESC);
@@ -0,0 +1,2 @@
+DELETE FROM idempotency_keys WHERE resource_id IS NULL;
+ALTER TABLE idempotency_keys ALTER COLUMN resource_id SET NOT NULL;
@@ -0,0 +1,2 @@
+-- Keys are claimed (resource_id NULL) before the resource is created, so concurrent retries cannot both create it
+ALTER TABLE idempotency_keys ALTER COLUMN resource_id DROP NOT NULL;
@@ -0,0 +1,41 @@
+CREATE OR REPLACE FUNCTION update_vote_count()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        IF NEW.post_id IS NOT NULL THEN
+            UPDATE posts
+            SET vote_count = vote_count + NEW.vote_type
+            WHERE post_id = NEW.post_id;
+        ELSIF NEW.comment_id IS NOT NULL THEN
+            UPDATE comments
+            SET vote_count = vote_count + NEW.vote_type
+            WHERE comment_id = NEW.comment_id;
+        END IF;
+        RETURN NEW;
+    ELSIF TG_OP = 'UPDATE' THEN
+        IF NEW.post_id IS NOT NULL THEN
+            UPDATE posts
+            SET vote_count = vote_count + (NEW.vote_type - OLD.vote_type)
+            WHERE post_id = NEW.post_id;
+        ELSIF NEW.comment_id IS NOT NULL THEN
+            UPDATE comments
+            SET vote_count = vote_count + (NEW.vote_type - OLD.vote_type)
+            WHERE comment_id = NEW.comment_id;
+        END IF;
+        RETURN NEW;
+    ELSIF TG_OP = 'DELETE' THEN
+        IF OLD.post_id IS NOT NULL THEN
+            UPDATE posts
+            SET vote_count = vote_count - OLD.vote_type
+            WHERE post_id = OLD.post_id;
+        ELSIF OLD.comment_id IS NOT NULL THEN
+            UPDATE comments
+            SET vote_count = vote_count - OLD.vote_type
+            WHERE comment_id = OLD.comment_id;
+        END IF;
+        RETURN OLD;
+    END IF;
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE posts DROP COLUMN IF EXISTS votes_changed_at;
@@ -0,0 +1,42 @@
+-- When a vote on the post was last cast, changed or removed; updated_at only tracks edits, so Last-Modified needs both
+ALTER TABLE posts ADD COLUMN votes_changed_at TIMESTAMP;
+
+CREATE OR REPLACE FUNCTION update_vote_count()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        IF NEW.post_id IS NOT NULL THEN
+            UPDATE posts
+            SET vote_count = vote_count + NEW.vote_type, votes_changed_at = NOW()
+            WHERE post_id = NEW.post_id;
+        ELSIF NEW.comment_id IS NOT NULL THEN
+            UPDATE comments
+            SET vote_count = vote_count + NEW.vote_type
+            WHERE comment_id = NEW.comment_id;
+        END IF;
+        RETURN NEW;
+    ELSIF TG_OP = 'UPDATE' THEN
+        IF NEW.post_id IS NOT NULL THEN
+            UPDATE posts
+            SET vote_count = vote_count + (NEW.vote_type - OLD.vote_type), votes_changed_at = NOW()
+            WHERE post_id = NEW.post_id;
+        ELSIF NEW.comment_id IS NOT NULL THEN
+            UPDATE comments
+            SET vote_count = vote_count + (NEW.vote_type - OLD.vote_type)
+            WHERE comment_id = NEW.comment_id;
+        END IF;
+        RETURN NEW;
+    ELSIF TG_OP = 'DELETE' THEN
+        IF OLD.post_id IS NOT NULL THEN
+            UPDATE posts
+            SET vote_count = vote_count - OLD.vote_type, votes_changed_at = NOW()
+            WHERE post_id = OLD.post_id;
+        ELSIF OLD.comment_id IS NOT NULL THEN
+            UPDATE comments
+            SET vote_count = vote_count - OLD.vote_type
+            WHERE comment_id = OLD.comment_id;
+        END IF;
+        RETURN OLD;
+    END IF;
+END;
+$$ LANGUAGE plpgsql;
@@ -1,10 +1,13 @@
 // Redux slice for topics state management
 import { createAsyncThunk, createSlice } from '@reduxjs/toolkit';
-import type { Post } from '../types';
+import type { PagedResponse, Post } from '../types';
 import { apiClient } from '../api/client';
 
 interface PostsState {
     posts: Post[];
+    hasMore: boolean;
+    nextCursor: string | null; // Offset of the topic's next page of posts, from the last PagedResponse
+    loadingMore: boolean;
     currentPost: Post | null;
     loading: boolean;
     error: string | null;
@@ -14,6 +17,9 @@ interface PostsState {
 
 const initialState: PostsState = {
     posts: [],
+    hasMore: false,
+    nextCursor: null,
+    loadingMore: false,
     currentPost: null,
     loading: false,
     error: null,
@@ -21,12 +27,12 @@ const initialState: PostsState = {
     submitError: null,
 }
 
-// Fetch posts by topic
+// Fetch the first page of posts by topic
 export const fetchPostsByTopic = createAsyncThunk(
     'posts/fetchPostsByTopic',
     async (topicID: number, { rejectWithValue }) => {
         try {
-            const response = await apiClient.get<Post[]>(`/topics/${topicID}/posts`);
+            const response = await apiClient.get<PagedResponse<Post>>(`/topics/${topicID}/posts`);
             return response.data;
         } catch (error: any) {
             return rejectWithValue(error.response?.data?.error || 'Failed to fetch posts');
@@ -34,6 +40,30 @@ export const fetchPostsByTopic = createAsyncThunk(
     }
 )
 
+// Fetch the topic's next page of posts, from the last page's nextCursor
+export const fetchMorePostsByTopic = createAsyncThunk(
+    'posts/fetchMorePostsByTopic',
+    async (topicID: number, { getState, rejectWithValue }) => {
+        const { nextCursor } = (getState() as { posts: PostsState }).posts;
+
+        try {
+            const response = await apiClient.get<PagedResponse<Post>>(
+                `/topics/${topicID}/posts`,
+                { params: { offset: nextCursor } }
+            );
+            return response.data;
+        } catch (error: any) {
+            return rejectWithValue(error.response?.data?.error || 'Failed to fetch posts');
+        }
+    },
+    {
+        condition: (_, { getState }) => {
+            const { hasMore, loadingMore } = (getState() as { posts: PostsState }).posts;
+            return hasMore && !loadingMore;
+        }
+    }
+)
+
 // Fetch single post by ID
 export const fetchPostByID = createAsyncThunk(
     'posts/fetchPostByID',
@@ -123,7 +153,9 @@ const postsSlice = createSlice({
             fetchPostsByTopic.fulfilled,
             (state, action) => {
                 state.loading = false;
-                state.posts = action.payload;
+                state.posts = action.payload.items;
+                state.hasMore = action.payload.hasMore;
+                state.nextCursor = action.payload.nextCursor ?? null;
             }
         );
 
@@ -135,6 +167,36 @@ const postsSlice = createSlice({
             }
         )
 
+        // Fetch more posts by topic
+        builder.addCase(
+            fetchMorePostsByTopic.pending,
+            (state) => {
+                state.loadingMore = true;
+                state.error = null;
+            }
+        );
+
+        builder.addCase(
+            fetchMorePostsByTopic.fulfilled,
+            (state, action) => {
+                state.loadingMore = false;
+
+                // Skip posts already listed (e.g. created since the first page shifted the offsets)
+                const known = new Set(state.posts.map(post => post.postID));
+                state.posts.push(...action.payload.items.filter(post => !known.has(post.postID)));
+                state.hasMore = action.payload.hasMore;
+                state.nextCursor = action.payload.nextCursor ?? null;
+            }
+        );
+
+        builder.addCase(
+            fetchMorePostsByTopic.rejected,
+            (state, action) => {
+                state.loadingMore = false;
+                state.error = action.payload as string;
+            }
+        )
+
         // Fetch single post by ID
         builder.addCase(
             fetchPostByID.pending,
@@ -1,10 +1,13 @@
 // Redux slice for topics state management
 import { createAsyncThunk, createSlice, type PayloadAction } from '@reduxjs/toolkit';
-import type { Topic } from '../types';
+import type { PagedResponse, Topic } from '../types';
 import { apiClient } from '../api/client';
 
 interface TopicsState {
     topics: Topic[];
+    hasMore: boolean;
+    nextCursor: string | null; // Offset of the next page, from the last PagedResponse
+    loadingMore: boolean;
     loading: boolean;
     error: string | null;
     submitting: boolean;
@@ -13,18 +16,21 @@ interface TopicsState {
 
 const initialState: TopicsState = {
     topics: [],
+    hasMore: false,
+    nextCursor: null,
+    loadingMore: false,
     loading: false,
     error: null,
     submitting: false,
     submitError: null,
 }
 
-// Fetch topics
+// Fetch the first page of topics
 export const fetchTopics = createAsyncThunk(
     `topics/fetchTopics`,
     async (_, { rejectWithValue }) => {
         try {
-            const response = await apiClient.get<Topic[]>('/topics');
+            const response = await apiClient.get<PagedResponse<Topic>>('/topics');
             return response.data;
         } catch (error: any) {
             return rejectWithValue(error.response?.data?.error || 'Failed to fetch topics');
@@ -32,6 +38,30 @@ export const fetchTopics = createAsyncThunk(
     }
 )
 
+// Fetch the next page of topics, from the last page's nextCursor
+export const fetchMoreTopics = createAsyncThunk(
+    `topics/fetchMoreTopics`,
+    async (_, { getState, rejectWithValue }) => {
+        const { nextCursor } = (getState() as { topics: TopicsState }).topics;
+
+        try {
+            const response = await apiClient.get<PagedResponse<Topic>>(
+                '/topics',
+                { params: { offset: nextCursor } }
+            );
+            return response.data;
+        } catch (error: any) {
+            return rejectWithValue(error.response?.data?.error || 'Failed to fetch topics');
+        }
+    },
+    {
+        condition: (_, { getState }) => {
+            const { hasMore, loadingMore } = (getState() as { topics: TopicsState }).topics;
+            return hasMore && !loadingMore;
+        }
+    }
+)
+
 // Create topic
 export const createTopic = createAsyncThunk(
     'topics/createTopic',
@@ -111,9 +141,11 @@ const topicsSlice = createSlice({
 
         builder.addCase(
             fetchTopics.fulfilled, 
-            (state, action: PayloadAction<Topic[]>) => {
+            (state, action: PayloadAction<PagedResponse<Topic>>) => {
                 state.loading = false;
-                state.topics = action.payload;
+                state.topics = action.payload.items;
+                state.hasMore = action.payload.hasMore;
+                state.nextCursor = action.payload.nextCursor ?? null;
             }
         );
 
@@ -125,6 +157,36 @@ const topicsSlice = createSlice({
             }
         );
 
+        // Fetch more topics
+        builder.addCase(
+            fetchMoreTopics.pending,
+            (state) => {
+                state.loadingMore = true;
+                state.error = null;
+            }
+        );
+
+        builder.addCase(
+            fetchMoreTopics.fulfilled,
+            (state, action: PayloadAction<PagedResponse<Topic>>) => {
+                state.loadingMore = false;
+
+                // Skip topics already listed (e.g. created since the first page shifted the offsets)
+                const known = new Set(state.topics.map(topic => topic.topicID));
+                state.topics.push(...action.payload.items.filter(topic => !known.has(topic.topicID)));
+                state.hasMore = action.payload.hasMore;
+                state.nextCursor = action.payload.nextCursor ?? null;
+            }
+        );
+
+        builder.addCase(
+            fetchMoreTopics.rejected,
+            (state, action) => {
+                state.loadingMore = false;
+                state.error = action.payload as string;
+            }
+        );
+
         // Create topic
         builder.addCase(
             createTopic.pending,
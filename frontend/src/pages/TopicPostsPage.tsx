@@ -1,7 +1,7 @@
 import { useNavigate, useParams } from "react-router-dom";
 import { useAppDispatch, useAppSelector } from "../hooks/redux";
 import { useEffect, useState } from "react";
-import { fetchPostsByTopic } from "../features/postsSlice";
+import { fetchMorePostsByTopic, fetchPostsByTopic } from "../features/postsSlice";
 import { Alert, Box, Button, Card, CardActionArea, CardContent, CircularProgress, Container, Dialog, DialogActions, DialogContent, DialogContentText, DialogTitle, Divider, Grid, IconButton, InputAdornment, Paper, TextField, Typography } from "@mui/material";
 import { Add, ArrowBack, Clear, Delete, Edit, Search } from "@mui/icons-material";
 import ForumBreadcrumbs from "../components/Breadcrumbs";
@@ -13,7 +13,7 @@ export default function TopicPostsPage() {
     const dispatch = useAppDispatch(); 
     const navigate = useNavigate();
 
-    const { posts, loading: postsLoading, error: postsError } = useAppSelector(state => state.posts);
+    const { posts, hasMore, loadingMore, loading: postsLoading, error: postsError } = useAppSelector(state => state.posts);
     const { topics, submitting: topicSubmitting, submitError } = useAppSelector(state => state.topics);
     const { userID } = useAppSelector(state => state.auth);
 
@@ -400,6 +400,25 @@ export default function TopicPostsPage() {
                         )}
                     </Grid>
                 )}
+
+                {/* Load More (searches only filter the posts loaded so far) */}
+                {hasMore && (
+                    <Box
+                        sx={{
+                            display: 'flex',
+                            justifyContent: 'center',
+                            mt: 3,
+                        }}
+                    >
+                        <Button
+                            variant="outlined"
+                            disabled={loadingMore}
+                            onClick={() => dispatch(fetchMorePostsByTopic(topic.topicID))}
+                        >
+                            {loadingMore ? 'Loading...' : 'Load More Posts'}
+                        </Button>
+                    </Box>
+                )}
             </Box>
 
             {/* Delete Confirmation Dialog */}
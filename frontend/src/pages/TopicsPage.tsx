@@ -1,7 +1,7 @@
 import { useNavigate } from "react-router-dom";
 import { useAppDispatch, useAppSelector } from "../hooks/redux";
 import { useEffect, useState } from "react";
-import { fetchTopics } from "../features/topicsSlice";
+import { fetchMoreTopics, fetchTopics } from "../features/topicsSlice";
 import { Alert, Box, Button, Card, CardActionArea, CardContent, CircularProgress, Container, Grid, IconButton, InputAdornment, TextField, Typography } from "@mui/material";
 import { Add, Clear, Search } from "@mui/icons-material";
 import Username from "../components/Username";
@@ -9,7 +9,7 @@ import Username from "../components/Username";
 export default function TopicsPage() {
     const dispatch = useAppDispatch();
     const navigate = useNavigate();
-    const { topics, loading, error } = useAppSelector(state => state.topics);
+    const { topics, hasMore, loadingMore, loading, error } = useAppSelector(state => state.topics);
 
     const [searchQuery, setSearchQuery] = useState('');
 
@@ -280,6 +280,25 @@ export default function TopicsPage() {
                     */
                 )
             }
+
+            {/* Load More (searches only filter the topics loaded so far) */}
+            {hasMore && (
+                <Box
+                    sx={{
+                        display: 'flex',
+                        justifyContent: 'center',
+                        mt: 3,
+                    }}
+                >
+                    <Button
+                        variant="outlined"
+                        disabled={loadingMore}
+                        onClick={() => dispatch(fetchMoreTopics())}
+                    >
+                        {loadingMore ? 'Loading...' : 'Load More Topics'}
+                    </Button>
+                </Box>
+            )}
         </Container>
     )
 }
\ No newline at end of file
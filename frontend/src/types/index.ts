@@ -6,16 +6,21 @@ export interface User {
     username: string;
     createdAt: string;
     updatedAt: string;
+    email?: string; // only on GET /me and registration (if given)
+    lastLoginAt?: string; // only on GET /me
 }
 
 export interface Topic {
     topicID: number;
     title: string;
+    slug: string;
     description: string;
     createdBy: number;
     username: string;
     createdAt: string;
     updatedAt: string;
+    pinned: boolean;
+    latestPost?: Post; // only on GET /topics?includeLatestPost=true (postID, topicID, title and createdAt only)
 }
 
 export interface Post {
@@ -28,6 +33,16 @@ export interface Post {
     username: string;
     createdAt: string;
     updatedAt: string;
+    pinned: boolean;
+    locked: boolean;
+    isOwner?: boolean;
+    topic?: PostTopic; // only on GET /posts/:postID?includeTopic=true
+}
+
+export interface PostTopic {
+    topicID: number;
+    title: string;
+    slug: string;
 }
 
 export interface Comment {
@@ -39,12 +54,54 @@ export interface Comment {
     username: string;
     createdAt: string;
     updatedAt: string;
+    quotedCommentID?: number;
+    deletedAt?: string; // set if deleted while quoted by replies (content is then "[deleted]")
+    isOwner?: boolean;
+}
+
+export interface TrendingTopic { // from GET /topics/trending
+    topicID: number;
+    title: string;
+    slug: string;
+    activityCount: number;
+}
+
+export interface UserKarma {
+    postKarma: number;
+    commentKarma: number;
+    total: number;
+}
+
+export interface UserProfile { // from GET /users/username/:username
+    userID: number;
+    username: string;
+    createdAt: string;
+    karma: UserKarma;
+}
+
+export interface ActiveUser { // from GET /users/active
+    userID: number;
+    username: string;
+    activityCount: number;
+}
+
+export type UsernamesByID = Record<number, string>; // from GET /users/usernames?ids=...; unknown IDs are left out
+
+// Paginated list response (matches PagedResponse in Go)
+export interface PagedResponse<T> {
+    items: T[];
+    total: number;
+    totalEstimated?: boolean; // topics list only: total is the planner's estimate, not an exact count
+    limit: number; // effective page size, after clamping to the endpoint's maximum
+    hasMore: boolean;
+    nextCursor?: string;
 }
 
 // Auth types for login/register 
 export interface RegisterCredentials {
     username: string;
     password: string;
+    email?: string; // optional; can then be used to log in
 } 
 
 export interface RegisterResponse { // returned by RegisterUser in UserHandler
@@ -53,24 +110,57 @@ export interface RegisterResponse { // returned by RegisterUser in UserHandler
 }
 
 export interface LoginCredentials { 
-    username: string;
+    username: string; // username or email
     password: string;
 }
 
 export interface LoginResponse { // returned by LoginUser in LoginHandler
     message: string;
     token: string;
+    expiresAt: string; // RFC 3339
+    expiresIn: number; // seconds
+}
+
+export interface ForgotPasswordRequest { // always answered with a message, whether or not the account exists
+    username: string; // username or email
+}
+
+export interface ResetPasswordRequest {
+    token: string;
+    password: string;
+}
+
+export interface ChangeUsernameRequest { // the updated User is returned; log in again to refresh the token
+    username: string;
+}
+
+export interface ContentLimits { // maximum lengths in characters, from GET /config/limits
+    titleLength: number;
+    topicDescriptionLength: number;
+    postContentLength: number;
+    commentLength: number;
 }
 
 // Generic API error response
 export interface APIError {
     error: string;
+    errors?: FieldError[]; // every failed field, on 400s from topic and post creation
+    code?: AuthErrorCode; // only on 401s from protected routes
+}
+
+// Why a protected route rejected the request's token
+export type AuthErrorCode = 'AUTH_MISSING' | 'AUTH_MALFORMED' | 'AUTH_EXPIRED' | 'AUTH_INVALID' | 'AUTH_REVOKED';
+
+export interface FieldError {
+    field: string;
+    message: string;
 }
 
 // Request types for creating and updating
 export interface CreateTopicRequest {
     title: string;
     description: string;
+    allowDuplicate?: boolean;
 }
 
 export interface UpdateTopicRequest {
@@ -78,6 +168,10 @@ export interface UpdateTopicRequest {
     description: string;
 }
 
+export interface UpdateTopicDescriptionRequest {
+    description: string;
+}
+
 export interface CreatePostRequest {
     title: string;
     content: string;
@@ -90,6 +184,7 @@ export interface UpdatePostRequest {
 
 export interface CreateCommentRequest {
     content: string;
+    quotedCommentID?: number;
 }
 
 export interface UpdateCommentRequest {